@@ -0,0 +1,73 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package confcheck
+
+// Range checks for settings read from a file: numbers out of their range
+// (often from hand editing) are corrected in one place, and every
+// correction is recorded so it can be logged instead of silently papered
+// over.
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Check collects the corrections made through it.
+type Check struct {
+	Issues []string
+}
+
+// Int keeps *v within lo..hi. A value that is out of range and not even
+// positive, where lo is, is replaced by def; anything else is clamped.
+func (k *Check) Int(key string, v *int, lo, hi, def int) {
+	if *v >= lo && *v <= hi {
+		return
+	}
+	fix := min(max(*v, lo), hi)
+	if *v <= 0 && lo > 0 {
+		fix = def
+	}
+	k.Issues = append(k.Issues, fmt.Sprintf("%s: %d is outside %d..%d, using %d", key, *v, lo, hi, fix))
+	*v = fix
+}
+
+// Float is Int for float settings; NaN (".nan" in YAML) is replaced by def.
+func (k *Check) Float(key string, v *float64, lo, hi, def float64) {
+	if *v >= lo && *v <= hi {
+		return // false for NaN
+	}
+	fix := min(max(*v, lo), hi)
+	if math.IsNaN(*v) || *v <= 0 && lo > 0 {
+		fix = def
+	}
+	k.Issues = append(k.Issues, fmt.Sprintf("%s: %g is outside %g..%g, using %g", key, *v, lo, hi, fix))
+	*v = fix
+}
+
+// OneOf replaces *v by def unless it is one of allowed.
+func (k *Check) OneOf(key string, v *int, allowed []int, def int) {
+	if slices.Contains(allowed, *v) {
+		return
+	}
+	k.Issues = append(k.Issues, fmt.Sprintf("%s: %d is not one of %v, using %d", key, *v, allowed, def))
+	*v = def
+}
@@ -0,0 +1,91 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package confcheck
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		v, lo, hi, def int
+		want           int
+		fixed          bool
+	}{
+		{5, 1, 10, 3, 5, false},
+		{1, 1, 10, 3, 1, false},
+		{99, 1, 10, 3, 10, true},
+		{0, 1, 10, 3, 3, true},   // zero where a positive value is needed: default
+		{-4, 1, 10, 3, 3, true},  // likewise
+		{-4, -1, 2, 0, -1, true}, // a range with negatives clamps
+	}
+	for _, tt := range tests {
+		var k Check
+		v := tt.v
+		k.Int("key", &v, tt.lo, tt.hi, tt.def)
+		if v != tt.want || (len(k.Issues) > 0) != tt.fixed {
+			t.Errorf("Int(%d in %d..%d, def %d) = %d with %q; want %d, corrected %v",
+				tt.v, tt.lo, tt.hi, tt.def, v, k.Issues, tt.want, tt.fixed)
+		}
+	}
+}
+
+func TestFloat(t *testing.T) {
+	tests := []struct {
+		v, lo, hi, def float64
+		want           float64
+		fixed          bool
+	}{
+		{0.5, 0.1, 60, 1, 0.5, false},
+		{0, 0, 1e6, 0, 0, false},
+		{120, 0.1, 60, 1, 60, true},
+		{0, 0.1, 60, 1, 1, true},
+		{-3, 0, 100, 0, 0, true},
+		{math.NaN(), 0, 1e6, 0, 0, true},
+		{math.NaN(), 0.1, 60, 1, 1, true},
+		{math.Inf(1), 0, 100, 0, 100, true},
+		{math.Inf(-1), 0.5, 120, 6, 6, true},
+	}
+	for _, tt := range tests {
+		var k Check
+		v := tt.v
+		k.Float("key", &v, tt.lo, tt.hi, tt.def)
+		if v != tt.want || (len(k.Issues) > 0) != tt.fixed {
+			t.Errorf("Float(%g in %g..%g, def %g) = %g with %q; want %g, corrected %v",
+				tt.v, tt.lo, tt.hi, tt.def, v, k.Issues, tt.want, tt.fixed)
+		}
+	}
+}
+
+func TestOneOf(t *testing.T) {
+	var k Check
+	v := 6
+	k.OneOf("family", &v, []int{0, 4, 6}, 0)
+	if v != 6 || len(k.Issues) != 0 {
+		t.Errorf("allowed value changed to %d (%q)", v, k.Issues)
+	}
+	v = 5
+	k.OneOf("family", &v, []int{0, 4, 6}, 0)
+	if v != 0 || len(k.Issues) != 1 {
+		t.Errorf("got %d with %q, want 0 and one correction", v, k.Issues)
+	}
+}
@@ -0,0 +1,100 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package dns
+
+// Host name lookups shared by the UI (resolve preview, dual-stack hosts,
+// reverse names of pinged addresses).
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout applies when ctx carries no deadline of its own.
+const DefaultTimeout = 5 * time.Second
+
+// Lookup resolves host to all of its addresses. IP literals come back as-is.
+func Lookup(ctx context.Context, host string) ([]string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("dns: %s has no addresses", host)
+	}
+	return addrs, nil
+}
+
+// Reverse returns the first name ip's PTR records point to, without the
+// trailing dot.
+func Reverse(ctx context.Context, ip string) (string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+	names, err := net.DefaultResolver.LookupAddr(ctx, ip)
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("dns: %s has no PTR record", ip)
+	}
+	return strings.TrimSuffix(names[0], "."), nil
+}
+
+// Families looks host up and returns its first IPv4 and first IPv6 address;
+// either may be empty if that family has no record.
+func Families(ctx context.Context, host string) (v4, v6 string, err error) {
+	addrs, err := Lookup(ctx, host)
+	if err != nil {
+		return "", "", err
+	}
+	return splitFamilies(addrs)
+}
+
+func splitFamilies(addrs []string) (v4, v6 string, err error) {
+	for _, a := range addrs {
+		ip := net.ParseIP(a)
+		switch {
+		case ip == nil:
+			continue
+		case ip.To4() != nil:
+			if v4 == "" {
+				v4 = a
+			}
+		case v6 == "":
+			v6 = a
+		}
+	}
+	if v4 == "" && v6 == "" {
+		return "", "", fmt.Errorf("dns: no IP addresses in %v", addrs)
+	}
+	return v4, v6, nil
+}
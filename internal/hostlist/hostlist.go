@@ -0,0 +1,185 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package hostlist
+
+// Host addresses as typed or pasted by the user, and host lists imported
+// from plain text inventories, one host per line in either of the common
+// shapes:
+//
+//	192.168.1.1   router gw     # /etc/hosts: address, name, aliases
+//	NAS,192.168.1.20            # CSV: name, address (either order)
+//	8.8.8.8                     # address alone
+//
+// Anything after '#' is a comment.
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/e1z0/speedping/internal/monitor"
+)
+
+// Entry is one host of an imported list.
+type Entry struct {
+	Name string // the address when the line has no name
+	Addr string
+}
+
+// Validate rejects addresses that can never be pinged: malformed
+// IPs (e.g. "1.1.1.") and strings that aren't valid hostnames. Names are not
+// resolved here, so a host stays addable while DNS is down.
+func Validate(s string) error {
+	if s == "" {
+		return errors.New("address is empty")
+	}
+	if cmd, ok := monitor.CommandLine(s); ok {
+		if cmd == "" {
+			return errors.New("custom probe command is empty")
+		}
+		return nil
+	}
+	ip := s
+	if i := strings.IndexByte(ip, '%'); i > 0 {
+		ip = ip[:i] // IPv6 zone, e.g. fe80::1%eth0
+	}
+	if net.ParseIP(ip) != nil {
+		return nil
+	}
+	if strings.Contains(s, ":") {
+		return fmt.Errorf("%q is not a valid IPv6 address", s)
+	}
+
+	name := strings.TrimSuffix(s, ".")
+	if len(name) == 0 || len(name) > 253 {
+		return fmt.Errorf("%q is not a valid host name", s)
+	}
+	allDigits := true
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return fmt.Errorf("%q is not a valid host name", s)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("%q: labels can't start or end with '-'", s)
+		}
+		for _, r := range label {
+			switch {
+			case r >= '0' && r <= '9':
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-', r == '_':
+				allDigits = false
+			default:
+				return fmt.Errorf("%q contains invalid character %q", s, r)
+			}
+		}
+	}
+	if allDigits {
+		// only digits and dots, yet not parsed as an IP above
+		return fmt.Errorf("%q is not a valid IPv4 address", s)
+	}
+	return nil
+}
+
+// SkippedLinesError reports the lines Parse couldn't use. The hosts
+// it returns along with it are still valid.
+type SkippedLinesError struct {
+	Lines []int // 1-based
+}
+
+func (e *SkippedLinesError) Error() string {
+	nums := make([]string, len(e.Lines))
+	for i, n := range e.Lines {
+		nums[i] = strconv.Itoa(n)
+	}
+	return fmt.Sprintf("%d invalid line(s): %s", len(e.Lines), strings.Join(nums, ", "))
+}
+
+// Parse reads hosts from r. Lines whose address fails Validate, and custom
+// probe commands (which a shared file must not be able to run), are
+// skipped and listed in a *SkippedLinesError;
+// addresses seen before in the file are dropped silently. Names default
+// to the address.
+func Parse(r io.Reader) ([]Entry, error) {
+	var hosts []Entry
+	var skipped []int
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, addr, ok := splitHostsLine(line)
+		_, custom := monitor.CommandLine(addr)
+		if !ok || Validate(addr) != nil || custom || strings.HasPrefix(line, monitor.CommandPrefix) {
+			skipped = append(skipped, n)
+			continue
+		}
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		if name == "" {
+			name = addr
+		}
+		hosts = append(hosts, Entry{Name: name, Addr: addr})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		return hosts, &SkippedLinesError{Lines: skipped}
+	}
+	return hosts, nil
+}
+
+// splitHostsLine picks the name and address out of one non-empty line.
+func splitHostsLine(line string) (name, addr string, ok bool) {
+	if strings.Contains(line, ",") {
+		f := strings.Split(line, ",")
+		for i := range f {
+			f[i] = strings.TrimSpace(f[i])
+		}
+		if len(f) != 2 || f[0] == "" && f[1] == "" {
+			return "", "", false
+		}
+		// name,IP is the usual order, but take IP,name too
+		if net.ParseIP(f[0]) != nil && net.ParseIP(f[1]) == nil {
+			return f[1], f[0], true
+		}
+		if f[1] == "" {
+			return "", f[0], true
+		}
+		return f[0], f[1], true
+	}
+	f := strings.Fields(line)
+	if len(f) == 1 {
+		return "", f[0], true
+	}
+	return f[1], f[0], true // the first name; further aliases are ignored
+}
@@ -0,0 +1,80 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package hostlist
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Entry
+		skipped []int
+	}{
+		{"empty", "", nil, nil},
+		{"comments and blank lines", "# inventory\n\n   \n8.8.8.8 # dns\n", []Entry{{"8.8.8.8", "8.8.8.8"}}, nil},
+		{"hosts file", "192.168.1.1   router gw\n::1 localhost\n",
+			[]Entry{{"router", "192.168.1.1"}, {"localhost", "::1"}}, nil},
+		{"csv name first", "NAS,192.168.1.20\n", []Entry{{"NAS", "192.168.1.20"}}, nil},
+		{"csv address first", "192.168.1.20 , NAS\n", []Entry{{"NAS", "192.168.1.20"}}, nil},
+		{"csv address only", "example.com,\n", []Entry{{"example.com", "example.com"}}, nil},
+		{"duplicates dropped", "1.1.1.1 one\n1.1.1.1 again\none.one.one.one\n",
+			[]Entry{{"one", "1.1.1.1"}, {"one.one.one.one", "one.one.one.one"}}, nil},
+		{"invalid lines", "1.1.1.\nok.example\nbad_host!\na,b,c\n,\n",
+			[]Entry{{"ok.example", "ok.example"}}, []int{1, 3, 4, 5}},
+		{"no custom probes", "cmd:rm -rf ~\ncmd:true\nprobe,cmd:true\n8.8.8.8\n", []Entry{{"8.8.8.8", "8.8.8.8"}}, []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.in))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			var skipped *SkippedLinesError
+			switch {
+			case tt.skipped == nil && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case tt.skipped != nil && !errors.As(err, &skipped):
+				t.Errorf("got error %v, want skipped lines %v", err, tt.skipped)
+			case skipped != nil && !slices.Equal(skipped.Lines, tt.skipped):
+				t.Errorf("skipped lines %v, want %v", skipped.Lines, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, s := range []string{"1.1.1.1", "fe80::1%eth0", "example.com", "example.com.", "my_host", "cmd:true"} {
+		if err := Validate(s); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", s, err)
+		}
+	}
+	for _, s := range []string{"", "1.1.1.", "1.2.3.4.5", "::g", "-bad.example", "a..b", "sp ace", "cmd:"} {
+		if Validate(s) == nil {
+			t.Errorf("Validate(%q) = nil, want an error", s)
+		}
+	}
+}
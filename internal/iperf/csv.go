@@ -0,0 +1,53 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package iperf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Sample is one throughput reading as shown on the speed graph.
+type Sample struct {
+	T    time.Time
+	Mbps float64
+}
+
+// ErrNoSamples is WriteCSV's error when there is nothing to write.
+var ErrNoSamples = errors.New("no speed test samples yet")
+
+// WriteCSV writes samples as "time,mbps" rows, with times in RFC 3339 and
+// Mbps to three decimals.
+func WriteCSV(out io.Writer, samples []Sample) error {
+	if len(samples) == 0 {
+		return ErrNoSamples
+	}
+	bw := bufio.NewWriter(out)
+	_, _ = bw.WriteString("time,mbps\n")
+	for _, s := range samples {
+		fmt.Fprintf(bw, "%s,%s\n", s.T.Format(time.RFC3339Nano), strconv.FormatFloat(s.Mbps, 'f', 3, 64))
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,56 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package iperf
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var b bytes.Buffer
+	err := WriteCSV(&b, []Sample{
+		{T: t0, Mbps: 94.12345},
+		{T: t0.Add(1500 * time.Millisecond), Mbps: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "time,mbps\n" +
+		"2024-05-01T12:00:00Z,94.123\n" +
+		"2024-05-01T12:00:01.5Z,0.000\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteCSV =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWriteCSVEmpty(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteCSV(&b, nil); !errors.Is(err, ErrNoSamples) {
+		t.Errorf("WriteCSV(nil) error = %v, want ErrNoSamples", err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("WriteCSV(nil) wrote %q", b.String())
+	}
+}
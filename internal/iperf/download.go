@@ -0,0 +1,144 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package iperf
+
+// Opt-in download of a missing iperf3 binary.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Source is a downloadable iperf3 build for one platform. Both fields are
+// required: nothing is installed unless the file matches SHA256.
+type Source struct {
+	URL    string `yaml:"url"`
+	SHA256 string `yaml:"sha256"` // hex
+}
+
+const (
+	downloadAttempts = 3
+	downloadTimeout  = 60 * time.Second // per attempt
+	downloadBackoff  = 2 * time.Second  // doubled after every failed attempt
+)
+
+// Platform is the key EnsureBinary looks sources up by, e.g. "linux/amd64".
+func Platform() string { return runtime.GOOS + "/" + runtime.GOARCH }
+
+// EnsureBinary returns SelectBinary's result when a binary exists. Otherwise,
+// when sources has an entry for Platform(), it downloads that build into
+// binDir (retrying with backoff), verifies its checksum and only then makes
+// it executable. Any failure is logged and ErrNotFound returned as before.
+func EnsureBinary(binDir string, sources map[string]Source) (string, error) {
+	bin, err := SelectBinary(binDir)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return bin, err
+	}
+	src, ok := sources[Platform()]
+	if !ok || src.URL == "" {
+		return "", err
+	}
+	name, nerr := bundledName()
+	if nerr != nil {
+		return "", err
+	}
+	dest := filepath.Join(binDir, name)
+	if derr := download(src, dest); derr != nil {
+		log.Printf("iperf: download from %s failed: %s\n", src.URL, derr)
+		return "", err
+	}
+	log.Printf("iperf: installed %s\n", dest)
+	return dest, nil
+}
+
+func download(src Source, dest string) error {
+	want := strings.ToLower(strings.TrimSpace(src.SHA256))
+	if len(want) != sha256.Size*2 {
+		return errors.New("no valid sha256 configured")
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	var err error
+	backoff := downloadBackoff
+	for i := 1; i <= downloadAttempts; i++ {
+		if err = fetchVerified(src.URL, want, dest); err == nil {
+			return nil
+		}
+		if i < downloadAttempts {
+			log.Printf("iperf: download attempt %d failed: %s (retrying in %s)\n", i, err, backoff)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
+
+// fetchVerified downloads url next to dest, checks it against the hex sha256
+// want and only then moves it into place as an executable.
+func fetchVerified(url, want, dest string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %s", resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".iperf3-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), resp.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch: got %s, want %s", got, want)
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
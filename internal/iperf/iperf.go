@@ -49,6 +49,8 @@ type Config struct {
 	Bidirectional bool     // --bidir (upload+download simultaneously; iperf3 ≥ 3.7)
 	ExtraArgs     []string // any additional raw args (optional)
 	Format        string   // iperf3 --format (default "m": Mbits/sec)
+	JSON          bool     // --json-stream (iperf3 ≥ 3.17), adds TCP RTT and CWND
+	BindAddr      string   // -B: local address (and so interface) to test from
 }
 
 // Interval is a parsed per-interval row.
@@ -62,46 +64,65 @@ type Interval struct {
 	EndSec   float64
 	Transfer string // e.g. "72.8 MBytes"
 	Bitrate  string // e.g. "607 Mbits/sec"
+	Role     string // "sender"/"receiver" on end-of-test summary rows, "" otherwise
+
+	// Sending side TCP stats, only with Config.JSON and 0 when not reported
+	// (UDP, -R, platforms without TCP_INFO). On end-of-test rows they are
+	// the mean RTT and the largest window.
+	RTTms float64 // smoothed round-trip time
+	CWND  int64   // congestion window, bytes
 }
 
 // Result is emitted after iperf exits.
 type Result struct {
 	ExitErr error // nil on success; iperf non-zero exit -> error
+
+	// End-of-test summary rows (the [SUM] rows when running parallel streams).
+	// Receiver is the authoritative average; either may be nil if iperf
+	// didn't get that far.
+	Sender   *Interval
+	Receiver *Interval
+
+	// Stderr holds the last lines iperf3 wrote to stderr (at most
+	// stderrKeep), where it explains failures such as a refused connection.
+	Stderr []string
 }
 
-// Run starts iperf3 and returns:
-//   - intervals: a live stream of parsed interval rows
-//   - done: fires when the process exits (with any error)
-//
-// It prints nothing by itself; we consume the channels.
-func Run(ctx context.Context, cfg Config) (<-chan Interval, <-chan Result, error) {
-	if cfg.Host == "" {
-		return nil, nil, errors.New("iperf: Host is required")
-	}
-	if cfg.BinDir == "" {
-		cfg.BinDir = "iperf"
-	}
-	if cfg.Port == 0 {
-		cfg.Port = 5201
+// stderrKeep bounds Result.Stderr.
+const stderrKeep = 20
+
+// Err is ExitErr with iperf3's own explanation attached, or nil on success.
+func (r Result) Err() error {
+	if r.ExitErr == nil {
+		return nil
 	}
-	if cfg.DurationSec == 0 {
-		cfg.DurationSec = 10
+	for i := len(r.Stderr) - 1; i >= 0; i-- {
+		if msg := strings.TrimPrefix(r.Stderr[i], "iperf3: "); msg != "" {
+			return fmt.Errorf("%s (%w)", msg, r.ExitErr)
+		}
 	}
-	if cfg.Parallel == 0 {
-		cfg.Parallel = 1
+	return r.ExitErr
+}
+
+// Command returns the binary and arguments Run would execute for cfg, so
+// the same invocation can be shown to the user or run by hand.
+func Command(cfg Config) (bin string, args []string, err error) {
+	if cfg.Host == "" {
+		return "", nil, errors.New("iperf: Host is required")
 	}
-	if cfg.IntervalSec == 0 {
-		cfg.IntervalSec = 1
+	cfg.setDefaults()
+	if msg := cfg.ClampInterval(); msg != "" {
+		log.Printf("iperf: %s\n", msg)
 	}
-	if cfg.Format == "" {
-		cfg.Format = "m" // Mbits/sec
-	}
-
-	bin, err := SelectBinary(cfg.BinDir)
+	bin, err = SelectBinary(cfg.BinDir)
 	if err != nil {
-		return nil, nil, err
+		return "", nil, err
 	}
+	return bin, BuildArgs(cfg), nil
+}
 
+// BuildArgs returns the iperf3 arguments for cfg (defaults already applied).
+func BuildArgs(cfg Config) []string {
 	args := []string{
 		"-c", cfg.Host,
 		"-p", fmt.Sprint(cfg.Port),
@@ -117,9 +138,52 @@ func Run(ctx context.Context, cfg Config) (<-chan Interval, <-chan Result, error
 	if cfg.Bidirectional {
 		args = append(args, "--bidir")
 	}
+	if cfg.JSON {
+		args = append(args, "--json-stream")
+	}
+	if cfg.BindAddr != "" {
+		args = append(args, "-B", cfg.BindAddr)
+	}
 	if len(cfg.ExtraArgs) > 0 {
 		args = append(args, cfg.ExtraArgs...)
 	}
+	return args
+}
+
+func (cfg *Config) setDefaults() {
+	if cfg.BinDir == "" {
+		cfg.BinDir = "iperf"
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 5201
+	}
+	if cfg.DurationSec == 0 {
+		cfg.DurationSec = 10
+	}
+	if cfg.Parallel == 0 {
+		cfg.Parallel = 1
+	}
+	if cfg.IntervalSec == 0 {
+		cfg.IntervalSec = 1
+	}
+	if cfg.Format == "" {
+		cfg.Format = "m" // Mbits/sec
+	}
+}
+
+// Run starts iperf3 and returns:
+//   - intervals: a live stream of parsed interval rows (from stdout)
+//   - done: fires when the process exits, with any error and stderr output
+//
+// Stderr lines are also logged as they arrive; nothing else is printed.
+func Run(ctx context.Context, cfg Config) (<-chan Interval, <-chan Result, error) {
+	bin, args, err := Command(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.BinDir == "" {
+		cfg.BinDir = "iperf"
+	}
 
 	cmd := exec.CommandContext(ctx, bin, args...)
 	log.Printf("Running iperf3: %s %s\n", bin, args)
@@ -134,7 +198,10 @@ func Run(ctx context.Context, cfg Config) (<-chan Interval, <-chan Result, error
 	if err != nil {
 		return nil, nil, err
 	}
-	cmd.Stderr = cmd.Stdout
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// hide external window
 	applyNoWindow(cmd)
@@ -148,13 +215,38 @@ func Run(ctx context.Context, cfg Config) (<-chan Interval, <-chan Result, error
 
 	// Regex for per-interval rows. We ignore header wording (Bitrate/Bandwidth) by matching the row itself.
 	// [ ID]  start-end  sec   <Transfer Bytes>   <Rate> <bits/sec>
-	re := regexp.MustCompile(`^\[\s*(\d+|SUM)\]\s+([0-9.]+)-([0-9.]+)\s+sec\s+([0-9.]+\s+[KMG]?Bytes)\s+([0-9.]+)\s+([KMG]?bits/sec)\b`)
+	// End-of-test summary rows look the same but finish with "sender"/"receiver":
+	// [  5]   0.00-10.04  sec  1.09 GBytes   935 Mbits/sec                  receiver
+	re := regexp.MustCompile(`^\[\s*(\d+|SUM)\]\s+([0-9.]+)-([0-9.]+)\s+sec\s+([0-9.]+\s+[KMG]?Bytes)\s+([0-9.]+)\s+([KMG]?bits/sec)\b(?:.*\b(sender|receiver)\s*$)?`)
+
+	var sender, receiver *Interval
+	var errLines, jsonErrs []string
+	readDone := make(chan struct{})
+	errDone := make(chan struct{})
+
+	// Collect stderr on its own so errors don't drown among interval rows
+	go func(r io.ReadCloser) {
+		defer close(errDone)
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" {
+				continue
+			}
+			log.Printf("iperf3 stderr: %s\n", line)
+			if len(errLines) == stderrKeep {
+				errLines = append(errLines[:0], errLines[1:]...)
+			}
+			errLines = append(errLines, line)
+		}
+	}(stderr)
 
 	// Stream & parse
 	go func(r io.ReadCloser) {
 		defer func() {
 			_ = r.Close()
 			close(intervals)
+			close(readDone)
 		}()
 		sc := bufio.NewScanner(r)
 		// support long lines
@@ -162,6 +254,28 @@ func Run(ctx context.Context, cfg Config) (<-chan Interval, <-chan Result, error
 		sc.Buffer(buf, 1024*1024)
 		for sc.Scan() {
 			line := strings.TrimSpace(sc.Text())
+			if cfg.JSON {
+				jl, err := parseJSONLine(line)
+				if err != nil {
+					log.Printf("iperf3: unreadable JSON line: %s\n", err)
+					continue
+				}
+				for _, iv := range jl.Intervals {
+					intervals <- iv
+				}
+				if jl.Sender != nil {
+					sender = jl.Sender
+				}
+				if jl.Receiver != nil {
+					receiver = jl.Receiver
+				}
+				if jl.Err != "" {
+					// with --json-stream iperf3 reports errors here, not on stderr
+					log.Printf("iperf3 error: %s\n", jl.Err)
+					jsonErrs = append(jsonErrs, jl.Err)
+				}
+				continue
+			}
 			if m := re.FindStringSubmatch(line); m != nil {
 				iv := Interval{
 					Raw:      line,
@@ -171,6 +285,20 @@ func Run(ctx context.Context, cfg Config) (<-chan Interval, <-chan Result, error
 					EndSec:   mustParseFloat(m[3]),
 					Transfer: m[4],              // e.g., "72.8 MBytes"
 					Bitrate:  m[5] + " " + m[6], // e.g., "607 Mbits/sec"
+					Role:     m[7],
+				}
+				switch iv.Role {
+				case "sender":
+					// [SUM] rows follow the per-stream ones and win
+					if iv.IsSum || sender == nil || !sender.IsSum {
+						sender = &iv
+					}
+					continue
+				case "receiver":
+					if iv.IsSum || receiver == nil || !receiver.IsSum {
+						receiver = &iv
+					}
+					continue
 				}
 				intervals <- iv
 			}
@@ -179,14 +307,33 @@ func Run(ctx context.Context, cfg Config) (<-chan Interval, <-chan Result, error
 
 	// Waiter
 	go func() {
+		// drain both pipes before Wait (it closes them) and to have the summary
+		<-readDone
+		<-errDone
 		err := cmd.Wait()
-		done <- Result{ExitErr: err}
+		errLines = append(errLines, jsonErrs...)
+		if len(errLines) > stderrKeep {
+			errLines = errLines[len(errLines)-stderrKeep:]
+		}
+		done <- Result{ExitErr: err, Sender: sender, Receiver: receiver, Stderr: errLines}
 		close(done)
 	}()
 
 	return intervals, done, nil
 }
 
+// ClampInterval makes sure the report interval fits into the test duration
+// (otherwise iperf3 prints no interval rows at all). It returns a
+// human-readable note when it had to change something, "" otherwise.
+func (c *Config) ClampInterval() string {
+	if c.DurationSec > 0 && c.IntervalSec > c.DurationSec {
+		msg := fmt.Sprintf("interval %ds exceeds duration %ds, using %ds", c.IntervalSec, c.DurationSec, c.DurationSec)
+		c.IntervalSec = c.DurationSec
+		return msg
+	}
+	return ""
+}
+
 func SelectBinary(binDir string) (string, error) {
 	// Explicit override
 	if p := os.Getenv("SPEEDPING_IPERF"); p != "" {
@@ -196,40 +343,17 @@ func SelectBinary(binDir string) (string, error) {
 		return "", fmt.Errorf("SPEEDPING_IPERF points to non-executable: %s", p)
 	}
 
-	// Bundled copy (same dir as the executable)
+	// Bundled copy (./iperf next to the executable, or binDir)
+	name, err := bundledName()
+	if err != nil {
+		return "", err
+	}
 	var candidates []string
 	if exe, err := os.Executable(); err == nil {
-		base := filepath.Dir(exe)
-		switch runtime.GOOS {
-		case "darwin":
-			if runtime.GOARCH == "arm64" {
-				candidates = append(candidates,
-					filepath.Join(base, "iperf", "iperf3-arm64-osx-14"),
-				)
-			} else {
-				candidates = append(candidates,
-					filepath.Join(base, "iperf", "iperf3-amd64-osx-13"),
-				)
-			}
-		case "linux":
-			if runtime.GOARCH == "amd64" {
-				candidates = append(candidates,
-					filepath.Join(base, "iperf", "iperf3-amd64"),
-				)
-			} else if runtime.GOARCH == "386" {
-				candidates = append(candidates,
-					filepath.Join(base, "iperf", "iperf3-i386"),
-				)
-			} else {
-				return "", fmt.Errorf("iperf: no linux binary for GOARCH=%s", runtime.GOARCH)
-			}
-		case "windows":
-			candidates = append(candidates,
-				filepath.Join(base, "iperf", "iperf3.exe"),
-			)
-		default:
-			return "", fmt.Errorf("iperf: unsupported OS %s", runtime.GOOS)
-		}
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), "iperf", name))
+	}
+	if binDir != "" {
+		candidates = append(candidates, filepath.Join(binDir, name))
 	}
 
 	for _, c := range candidates {
@@ -239,7 +363,7 @@ func SelectBinary(binDir string) (string, error) {
 	}
 
 	// PATH lookup (Linux/macOS: "iperf3", Windows: "iperf3.exe")
-	name := "iperf3"
+	name = "iperf3"
 	if runtime.GOOS == "windows" {
 		name = "iperf3.exe"
 	}
@@ -247,7 +371,32 @@ func SelectBinary(binDir string) (string, error) {
 		return p, nil
 	}
 
-	return "", errors.New("iperf3 binary not found (set SPEEDPING_IPERF or install iperf3 in PATH)")
+	return "", ErrNotFound
+}
+
+// ErrNotFound is returned when no usable iperf3 binary exists.
+var ErrNotFound = errors.New("iperf3 binary not found (set SPEEDPING_IPERF or install iperf3 in PATH)")
+
+// bundledName is the file name of the bundled iperf3 build for this platform.
+func bundledName() (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		if runtime.GOARCH == "arm64" {
+			return "iperf3-arm64-osx-14", nil
+		}
+		return "iperf3-amd64-osx-13", nil
+	case "linux":
+		switch runtime.GOARCH {
+		case "amd64":
+			return "iperf3-amd64", nil
+		case "386":
+			return "iperf3-i386", nil
+		}
+		return "", fmt.Errorf("iperf: no linux binary for GOARCH=%s", runtime.GOARCH)
+	case "windows":
+		return "iperf3.exe", nil
+	}
+	return "", fmt.Errorf("iperf: unsupported OS %s", runtime.GOOS)
 }
 
 func mustParseFloat(s string) float64 {
@@ -0,0 +1,175 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package iperf
+
+// --json-stream output (iperf3 ≥ 3.17): one JSON object per line,
+// {"event": "start"|"interval"|"end"|"error", "data": ...}. Unlike the text
+// rows it carries the sending side's TCP round-trip time and congestion
+// window. Both are missing for UDP and on the receiving side (-R), and some
+// platforms don't report them at all; such fields are left at zero.
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type jsonEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// jsonStats is the subset of an iperf3 stream or sum object we use.
+type jsonStats struct {
+	Socket     int     `json:"socket"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Bytes      float64 `json:"bytes"`
+	BitsPerSec float64 `json:"bits_per_second"`
+	Sender     bool    `json:"sender"`
+	Omitted    bool    `json:"omitted"`
+
+	// TCP, sender side only
+	SndCwnd    int64   `json:"snd_cwnd"`     // bytes, per interval
+	RTT        float64 `json:"rtt"`          // µs, per interval
+	MaxSndCwnd int64   `json:"max_snd_cwnd"` // bytes, end of test
+	MeanRTT    float64 `json:"mean_rtt"`     // µs, end of test
+}
+
+type jsonInterval struct {
+	Streams []jsonStats `json:"streams"`
+	Sum     *jsonStats  `json:"sum"`
+}
+
+type jsonEnd struct {
+	Streams []struct {
+		Sender *jsonStats `json:"sender"`
+	} `json:"streams"`
+	SumSent     *jsonStats `json:"sum_sent"`
+	SumReceived *jsonStats `json:"sum_received"`
+	Sum         *jsonStats `json:"sum"` // UDP
+}
+
+// jsonLine is what one --json-stream line contributes to a run.
+type jsonLine struct {
+	Intervals []Interval
+	Sender    *Interval // end of test
+	Receiver  *Interval
+	Err       string // "error" event
+}
+
+// parseJSONLine decodes one --json-stream line. Unknown events and omitted
+// (-O) intervals yield nothing.
+func parseJSONLine(line string) (jsonLine, error) {
+	var out jsonLine
+	var ev jsonEvent
+	if err := json.Unmarshal([]byte(line), &ev); err != nil {
+		return out, err
+	}
+	switch ev.Event {
+	case "interval":
+		var d jsonInterval
+		if err := json.Unmarshal(ev.Data, &d); err != nil {
+			return out, err
+		}
+		if d.Sum != nil && d.Sum.Omitted {
+			return out, nil
+		}
+		// like the text output: one row per stream, a SUM row when parallel
+		var rtt float64
+		var cwnd int64
+		rtts := 0
+		for _, s := range d.Streams {
+			iv := jsonToInterval(strconv.Itoa(s.Socket), s, "")
+			iv.RTTms, iv.CWND = s.RTT/1000, s.SndCwnd
+			out.Intervals = append(out.Intervals, iv)
+			if s.RTT > 0 {
+				rtt += s.RTT
+				rtts++
+			}
+			cwnd += s.SndCwnd
+		}
+		if d.Sum != nil && len(d.Streams) > 1 {
+			iv := jsonToInterval("SUM", *d.Sum, "")
+			if rtts > 0 {
+				iv.RTTms = rtt / float64(rtts) / 1000
+			}
+			iv.CWND = cwnd
+			out.Intervals = append(out.Intervals, iv)
+		}
+	case "end":
+		var d jsonEnd
+		if err := json.Unmarshal(ev.Data, &d); err != nil {
+			return out, err
+		}
+		sent, recv := d.SumSent, d.SumReceived
+		if sent == nil && recv == nil && d.Sum != nil {
+			sent = d.Sum // UDP reports a single sum
+		}
+		if sent != nil {
+			iv := jsonToInterval("SUM", *sent, "sender")
+			var rtt float64
+			rtts := 0
+			for _, s := range d.Streams {
+				if s.Sender == nil {
+					continue
+				}
+				if s.Sender.MeanRTT > 0 {
+					rtt += s.Sender.MeanRTT
+					rtts++
+				}
+				iv.CWND += s.Sender.MaxSndCwnd
+			}
+			if rtts > 0 {
+				iv.RTTms = rtt / float64(rtts) / 1000
+			}
+			out.Sender = &iv
+		}
+		if recv != nil {
+			iv := jsonToInterval("SUM", *recv, "receiver")
+			out.Receiver = &iv
+		}
+	case "error":
+		var msg string
+		if err := json.Unmarshal(ev.Data, &msg); err != nil {
+			msg = string(ev.Data)
+		}
+		out.Err = msg
+	}
+	return out, nil
+}
+
+// jsonToInterval fills the fields the text parser would, in the units
+// --format m uses.
+func jsonToInterval(id string, s jsonStats, role string) Interval {
+	iv := Interval{
+		ID:       id,
+		IsSum:    id == "SUM",
+		StartSec: s.Start,
+		EndSec:   s.End,
+		Transfer: fmt.Sprintf("%.2f MBytes", s.Bytes/(1<<20)),
+		Bitrate:  fmt.Sprintf("%.3f Mbits/sec", s.BitsPerSec/1e6),
+		Role:     role,
+	}
+	iv.Raw = strings.TrimSpace(fmt.Sprintf("[%s] %.2f-%.2f sec %s %s %s", id, iv.StartSec, iv.EndSec, iv.Transfer, iv.Bitrate, role))
+	return iv
+}
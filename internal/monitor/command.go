@@ -0,0 +1,141 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+// Custom probes: an external command run at the ping interval whose output
+// is a latency in ms, charted like ping. Hosts whose address starts with
+// CommandPrefix are probed this way instead of with ICMP.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandPrefix marks a host address as a command line printing a latency
+// in ms, e.g. "cmd:curl -so /dev/null -w '%{time_total}' https://example.com
+// | awk '{print $1*1000}'" (curl prints seconds, hence the awk; see
+// CommandBackend for how the output is read).
+const CommandPrefix = "cmd:"
+
+// ErrCommandHost is returned by the ICMP-only probes (ProbeAll, Burst)
+// for custom probe addresses.
+var ErrCommandHost = errors.New("custom probe command, not an address")
+
+// CommandLine returns the command of a custom probe address; ok is false
+// for anything else.
+func CommandLine(addr string) (command string, ok bool) {
+	if !strings.HasPrefix(addr, CommandPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(addr, CommandPrefix)), true
+}
+
+// CommandBackend runs a command through the shell (sh -c, or cmd /C on
+// Windows) every Interval. The first number on its standard output is the
+// latency in ms; a non-zero exit, no number, a negative one or a run longer
+// than Timeout is a loss.
+type CommandBackend struct {
+	Interval time.Duration
+	Timeout  time.Duration // per run; 0 = the interval, at least a second
+}
+
+var reNumber = regexp.MustCompile(`-?\d+(?:\.\d+)?`)
+
+// ParseMs picks the latency out of a probe command's output.
+func ParseMs(out []byte) (float64, bool) {
+	m := reNumber.Find(out)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(string(m), 64)
+	if err != nil || v < 0 {
+		return 0, false
+	}
+	return v, true
+}
+
+// Run probes with command until ctx is done, recording every result into
+// ring; onSample is as for ProbingBackend.Run. Runs don't overlap: a slow
+// command delays the next one instead of piling up.
+func (cb CommandBackend) Run(ctx context.Context, command string, ring *Ring, onSample func(Sample)) error {
+	if ring == nil {
+		return context.Canceled
+	}
+	if cb.Interval <= 0 {
+		cb.Interval = time.Second
+	}
+	if cb.Timeout <= 0 {
+		cb.Timeout = maxDur(cb.Interval, time.Second)
+	}
+
+	tick := time.NewTicker(cb.Interval)
+	defer tick.Stop()
+	for seq := 0; ; seq++ {
+		s := Sample{T: time.Now(), MS: -1, Seq: seq, State: SampleLoss}
+		if ms, ok := cb.probe(ctx, command); ok {
+			s.MS, s.State = ms, SampleOK
+		}
+		if ctx.Err() != nil {
+			return ctx.Err() // cut short by the stop, not a loss
+		}
+		ring.Push(s)
+		if onSample != nil {
+			onSample(s)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+		}
+	}
+}
+
+// commandGrace is how long a probe waits, after the command exits or is
+// killed, for whatever it started in the background to let go of its output.
+const commandGrace = 500 * time.Millisecond
+
+// probe runs command once and parses its output.
+func (cb CommandBackend) probe(ctx context.Context, command string) (float64, bool) {
+	ctx, cancel := context.WithTimeout(ctx, cb.Timeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	applyNoWindow(cmd)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.WaitDelay = commandGrace
+	// ErrWaitDelay: the command itself succeeded, a child kept the pipe open
+	if err := cmd.Run(); err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		return 0, false
+	}
+	return ParseMs(out.Bytes())
+}
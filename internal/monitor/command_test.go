@@ -0,0 +1,100 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct {
+		addr, cmd string
+		ok        bool
+	}{
+		{"cmd:echo 5", "echo 5", true},
+		{"cmd:  echo 5  ", "echo 5", true},
+		{"cmd:", "", true},
+		{"8.8.8.8", "", false},
+		{"CMD:echo 5", "", false},
+		{"example.com", "", false},
+	}
+	for _, tt := range tests {
+		cmd, ok := CommandLine(tt.addr)
+		if cmd != tt.cmd || ok != tt.ok {
+			t.Errorf("CommandLine(%q) = %q, %v; want %q, %v", tt.addr, cmd, ok, tt.cmd, tt.ok)
+		}
+	}
+}
+
+func TestParseMs(t *testing.T) {
+	tests := []struct {
+		out string
+		ms  float64
+		ok  bool
+	}{
+		{"12.5\n", 12.5, true},
+		{"42", 42, true},
+		{"time=3.25 ms, ttl=64", 3.25, true},
+		{"0", 0, true},
+		{"", 0, false},
+		{"timeout\n", 0, false},
+		{"-1", 0, false},
+		{"rtt 7 then 9", 7, true},
+	}
+	for _, tt := range tests {
+		ms, ok := ParseMs([]byte(tt.out))
+		if ms != tt.ms || ok != tt.ok {
+			t.Errorf("ParseMs(%q) = %v, %v; want %v, %v", tt.out, ms, ok, tt.ms, tt.ok)
+		}
+	}
+}
+
+func TestCommandProbeBackground(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	cb := CommandBackend{Timeout: time.Second}
+	tests := []struct {
+		name, command string
+		ms            float64
+		ok            bool
+	}{
+		// the shell is done at once, the sleep still holds its stdout
+		{"exits", "sleep 5 & echo 7", 7, true},
+		// the shell is killed at the timeout, the sleep outlives it
+		{"times out", "sleep 5 & sleep 5; echo 7", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			ms, ok := cb.probe(context.Background(), tt.command)
+			if took := time.Since(start); took > cb.Timeout+commandGrace+time.Second/2 {
+				t.Errorf("probe took %v, want at most the timeout plus %v", took, commandGrace)
+			}
+			if ms != tt.ms || ok != tt.ok {
+				t.Errorf("probe = %v, %v; want %v, %v", ms, ok, tt.ms, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+// Path MTU probe: binary search for the largest echo request that gets
+// through with the don't-fragment bit set. A router on the path with a
+// smaller MTU drops such packets (and should say so with "fragmentation
+// needed", which is often filtered), so large pings vanish while small
+// ones keep working — the classic MTU black hole.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	probing "github.com/prometheus-community/pro-bing"
+)
+
+// MTU bounds: MTUDefault is plain Ethernet, MTUMax the largest jumbo frame
+// PathMTU searches up to.
+const (
+	MTUDefault = 1500
+	MTUMax     = 9000
+)
+
+const (
+	mtuMinPayload = 24 // pro-bing's timestamp and tracker
+	mtuTries      = 2  // echo requests per size; one reply is enough
+	mtuGap        = 200 * time.Millisecond
+)
+
+// ErrMTUUnreachable is returned by PathMTU when not even the smallest
+// packet got a reply, so nothing can be said about the MTU.
+var ErrMTUUnreachable = errors.New("no reply even to the smallest packet, host unreachable")
+
+// MTUResult is the outcome of a PathMTU search.
+type MTUResult struct {
+	Addr    string // the address probed, resolved
+	IPv6    bool
+	MTU     int  // largest packet, IP header included, that got through
+	AtLimit bool // the ceiling itself got through; the real MTU may be larger
+	Probes  int  // echo requests sent
+	Took    time.Duration
+}
+
+// Overhead is the IP and ICMP header bytes around an echo payload.
+func (r MTUResult) Overhead() int {
+	if r.IPv6 {
+		return 40 + 8
+	}
+	return 20 + 8
+}
+
+// PathMTU binary-searches the largest packet of at most ceiling bytes
+// (MTUDefault when 0, at most MTUMax) that reaches addr and comes back
+// with the don't-fragment bit set. Every size gets mtuTries requests and
+// MaxRTT (default 1 s) to answer, so the search is bounded by about
+// 14 × mtuTries × MaxRTT. Setting the bit is not supported everywhere;
+// pro-bing's error says so.
+func (pb ProbingBackend) PathMTU(ctx context.Context, addr string, ceiling int) (r MTUResult, err error) {
+	r.Addr = addr
+	if pb.MaxRTT <= 0 {
+		pb.MaxRTT = time.Second
+	}
+	if ceiling <= 0 {
+		ceiling = MTUDefault
+	}
+	ceiling = min(ceiling, MTUMax)
+	if _, ok := CommandLine(addr); ok {
+		return r, ErrCommandHost
+	}
+	// resolve once, so every size goes to the same address
+	pinger, err := probing.NewPinger(addr)
+	if err != nil {
+		return r, err
+	}
+	ip := pinger.IPAddr()
+	r.Addr, r.IPv6 = ip.String(), ip.IP.To4() == nil
+	lo, hi := mtuMinPayload, ceiling-r.Overhead()
+	if hi < lo {
+		return r, fmt.Errorf("MTU %d is too small to probe", ceiling)
+	}
+
+	start := time.Now()
+	defer func() { r.Took = time.Since(start) }()
+	// lo always gets through, hi+1 never does
+	ok, err := pb.mtuStep(ctx, r.Addr, lo, &r.Probes)
+	if err != nil {
+		return r, err
+	}
+	if !ok {
+		return r, ErrMTUUnreachable
+	}
+	if ok, err = pb.mtuStep(ctx, r.Addr, hi, &r.Probes); err != nil {
+		return r, err
+	}
+	if ok {
+		r.MTU, r.AtLimit = hi+r.Overhead(), true
+		return r, nil
+	}
+	hi--
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		ok, err := pb.mtuStep(ctx, r.Addr, mid, &r.Probes)
+		if err != nil {
+			return r, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	r.MTU = lo + r.Overhead()
+	return r, nil
+}
+
+// mtuStep reports whether an echo request with a payload of size bytes
+// and the don't-fragment bit set got a reply. A send refused locally as
+// too large (the interface's or an already learnt path MTU) counts as no.
+func (pb ProbingBackend) mtuStep(ctx context.Context, addr string, size int, sent *int) (bool, error) {
+	pinger, err := probing.NewPinger(addr)
+	if err != nil {
+		return false, err
+	}
+	pb.setPrivileged(pinger)
+	pb.setSource(pinger)
+	pinger.SetDoNotFragment(true)
+	pinger.Count = mtuTries
+	pinger.Interval = mtuGap
+	pinger.Timeout = mtuTries*mtuGap + pb.MaxRTT
+	pinger.Size = size
+	err = pinger.RunWithContext(ctx)
+	*sent += pinger.Statistics().PacketsSent
+	if errors.Is(err, syscall.EMSGSIZE) {
+		return false, nil
+	}
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if err != nil {
+		return false, err
+	}
+	return pinger.Statistics().PacketsRecv > 0, nil
+}
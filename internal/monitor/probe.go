@@ -0,0 +1,613 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+// ICMP probing of one address into a Ring (pro-bing underneath).
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	probing "github.com/prometheus-community/pro-bing"
+)
+
+type ProbingBackend struct {
+	Privileged bool
+	Interval   time.Duration
+	MaxRTT     time.Duration
+	GraceLate  time.Duration // how long after MaxRTT we still call it "late" (not loss)
+	Size       int           // ICMP payload bytes; 0 = 56
+	// Source is the local address to send from, "" = the OS's choice. It
+	// only applies to addresses of its own family (IPv4/IPv6).
+	Source string
+
+	// Adaptive replaces MaxRTT per host with max(6×median RTT, adaptiveFloor)
+	// once enough replies were seen, so fast links flag loss sooner and slow
+	// but healthy links aren't flagged at all. MaxRTT applies until then.
+	Adaptive bool
+
+	// Reresolve looks a host name up again this often (0 = never). When the
+	// address being pinged is no longer among the answers, as happens with
+	// CDN and load-balanced names, Run moves to the new one and pushes a
+	// SampleGap so the two don't read as one line.
+	Reresolve time.Duration
+	// OnResolve (optional) is told the address Run pings: once at the
+	// start with old == "", then on every move. It may block briefly.
+	OnResolve func(old, cur string)
+
+	// SeqGaps (experimental) records a loss for every sequence number
+	// skipped between two replies that never went through OnSend, so
+	// probes the OS dropped without a send callback still show on the
+	// graph. Probes that were sent keep their own loss timers.
+	SeqGaps bool
+}
+
+// resolveTimeout bounds one re-resolution lookup.
+const resolveTimeout = 5 * time.Second
+
+const (
+	adaptiveWindow  = 30 // replies the baseline median is taken over
+	adaptiveMinSeen = 5  // replies needed before the baseline is trusted
+	adaptiveFloor   = 100 * time.Millisecond
+	adaptiveCeil    = 5 * time.Second
+)
+
+// seqSpace is pro-bing's sequence number range (it wraps to 0 after 65535).
+// A jump of more than seqGapMax between replies is taken for reordering or
+// a restart rather than backfilled.
+const (
+	seqSpace  = 1 << 16
+	seqGapMax = 64
+)
+
+// seqMissing returns the sequence numbers strictly between last and seq,
+// wrap-around included; nil when seq isn't after last or the gap is larger
+// than seqGapMax.
+func seqMissing(last, seq int) []int {
+	d := (seq - last + seqSpace) % seqSpace
+	if last < 0 || d <= 1 || d > seqGapMax {
+		return nil
+	}
+	out := make([]int, 0, d-1)
+	for q := last + 1; q != last+d; q++ {
+		out = append(out, q%seqSpace)
+	}
+	return out
+}
+
+// seqAfter reports whether seq comes after last in wrap-around order.
+func seqAfter(last, seq int) bool {
+	d := (seq - last + seqSpace) % seqSpace
+	return last < 0 || (d > 0 && d < seqSpace/2)
+}
+
+// suspendMin is the smallest clock jump Run treats as a suspend/resume
+// (or a stalled process) rather than ordinary scheduling jitter.
+const suspendMin = 5 * time.Second
+
+// rttBaseline tracks a host's recent reply times for the adaptive timeout.
+type rttBaseline struct {
+	mu   sync.Mutex
+	rtts []time.Duration // last adaptiveWindow replies, oldest first
+	tmp  []time.Duration
+}
+
+func (b *rttBaseline) Add(rtt time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.rtts) == adaptiveWindow {
+		b.rtts = append(b.rtts[:0], b.rtts[1:]...)
+	}
+	b.rtts = append(b.rtts, rtt)
+}
+
+// Timeout returns max(6×median, adaptiveFloor) capped at adaptiveCeil,
+// or def while fewer than adaptiveMinSeen replies were recorded.
+func (b *rttBaseline) Timeout(def time.Duration) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.rtts) < adaptiveMinSeen {
+		return def
+	}
+	b.tmp = append(b.tmp[:0], b.rtts...)
+	sort.Slice(b.tmp, func(i, j int) bool { return b.tmp[i] < b.tmp[j] })
+	med := b.tmp[len(b.tmp)/2]
+	return min(maxDur(6*med, adaptiveFloor), adaptiveCeil)
+}
+
+// Run pings addr until ctx is done, recording every reply/loss into ring.
+// onSample (optional) sees every sample pushed into the ring, and again when
+// a loss is reconciled into a late reply. It must not block.
+//
+// When the machine sleeps, the monotonic clock stops while the wall clock
+// keeps going. Run watches for that jump and, instead of reporting every
+// probe that was in flight as lost, drops them and pushes one SampleGap.
+func (pb ProbingBackend) Run(ctx context.Context, addr string, ring *Ring, onSample func(Sample)) error {
+	if ring == nil {
+		return context.Canceled
+	}
+
+	// ---- sane defaults ----
+	if pb.Interval <= 0 {
+		pb.Interval = time.Second
+	}
+	if pb.MaxRTT <= 0 {
+		pb.MaxRTT = maxDur(3*pb.Interval, 150*time.Millisecond)
+	}
+	if pb.GraceLate <= 0 {
+		pb.GraceLate = 100 * time.Millisecond
+	}
+
+	push := func(s Sample) int {
+		idx := ring.Push(s)
+		if onSample != nil {
+			onSample(s)
+		}
+		return idx
+	}
+
+	type pending struct {
+		timer  *time.Timer   // fires at maxRTT → insert LOSS
+		maxRTT time.Duration // timeout this seq was sent with
+		idx    int           // index in ring where LOSS went
+		pushed bool          // true once LOSS inserted
+	}
+	var (
+		mu      sync.Mutex
+		pends   = make(map[int]*pending) // seq -> pending
+		stale   = make(map[int]bool)     // seqs dropped at the last gap
+		base    rttBaseline              // only fed when pb.Adaptive
+		lastSeq = -1                     // highest seq replied to, -1 after a gap (pb.SeqGaps)
+	)
+
+	// Start a per-seq timer; on fire, insert LOSS sample and remember its index
+	onSend := func(pkt *probing.Packet) {
+		seq := pkt.Seq
+		maxRTT := pb.MaxRTT
+		if pb.Adaptive {
+			maxRTT = base.Timeout(pb.MaxRTT)
+		}
+		t := time.AfterFunc(maxRTT, func() {
+			mu.Lock()
+			p, ok := pends[seq]
+			if !ok {
+				mu.Unlock()
+				return // reply already handled
+			}
+			p.idx = push(Sample{
+				T:     time.Now(),
+				MS:    -1,
+				Seq:   seq,
+				State: SampleLoss,
+			})
+			p.pushed = true
+			mu.Unlock()
+		})
+		mu.Lock()
+		pends[seq] = &pending{timer: t, maxRTT: maxRTT, idx: -1, pushed: false}
+		delete(stale, seq) // reused after wrap-around
+		mu.Unlock()
+	}
+
+	// On receive: OK if within MaxRTT, else "late" (and reconcile loss if already pushed)
+	onRecv := func(pkt *probing.Packet) {
+		seq := pkt.Seq
+		rtt := pkt.Rtt
+		now := time.Now()
+
+		mu.Lock()
+		if stale[seq] {
+			// sent before a suspend; its RTT includes the time asleep
+			delete(stale, seq)
+			mu.Unlock()
+			return
+		}
+		p, had := pends[seq]
+		if had && p.timer != nil {
+			p.timer.Stop()
+		}
+		delete(pends, seq)
+		if pb.SeqGaps && seqAfter(lastSeq, seq) {
+			// seqs in between with no pending entry were never sent as far
+			// as we know; the entry makes a reply to them, should one come,
+			// reconcile like one to a timed-out probe
+			for _, q := range seqMissing(lastSeq, seq) {
+				if _, ok := pends[q]; ok || stale[q] {
+					continue
+				}
+				idx := push(Sample{T: now, MS: -1, Seq: q, State: SampleLoss})
+				pends[q] = &pending{maxRTT: pb.MaxRTT, idx: idx, pushed: true}
+			}
+			lastSeq = seq
+		}
+		mu.Unlock()
+
+		maxRTT := pb.MaxRTT
+		if had {
+			maxRTT = p.maxRTT
+		}
+		if pb.Adaptive {
+			// late replies count too, so a link that got slower re-baselines
+			base.Add(rtt)
+		}
+
+		if rtt <= maxRTT {
+			// on-time → normal point
+			push(Sample{
+				T:     now,
+				MS:    float64(rtt.Microseconds()) / 1000.0,
+				Seq:   seq,
+				State: SampleOK,
+			})
+			return
+		}
+
+		// Late: within grace → if LOSS already inserted, convert it to LATE
+		if had && p.pushed && rtt <= maxRTT+pb.GraceLate {
+			var late Sample
+			ring.UpdateAt(p.idx, func(s *Sample) {
+				s.State = SampleLate
+				s.MS = float64(rtt.Microseconds()) / 1000.0
+				s.T = now
+				late = *s
+			})
+			if onSample != nil {
+				onSample(late)
+			}
+			return
+		}
+
+		// Otherwise, record as a standalone LATE marker (gap in line)
+		push(Sample{
+			T:     now,
+			MS:    float64(rtt.Microseconds()) / 1000.0,
+			Seq:   seq,
+			State: SampleLate,
+		})
+	}
+
+	// Stop all per-seq timers; their probes are neither replied nor lost.
+	dropPending := func() {
+		for seq, p := range pends {
+			if p.timer != nil {
+				p.timer.Stop()
+			}
+			if !p.pushed {
+				stale[seq] = true
+			}
+		}
+		pends = map[int]*pending{}
+		lastSeq = -1
+	}
+
+	// newPinger sets up a pinger for target (addr, or what it resolved to
+	// later) without sending anything yet
+	newPinger := func(target string) (*probing.Pinger, error) {
+		pinger, err := probing.NewPinger(target)
+		if err != nil {
+			return nil, err
+		}
+		pb.setPrivileged(pinger)
+		pb.setSource(pinger)
+
+		pinger.Interval = pb.Interval
+		if pinger.Interval <= 0 {
+			pinger.Interval = time.Second
+		}
+		// Avoid 0-duration paths inside pro-bing
+		pinger.Timeout = 24 * time.Hour
+		pinger.RecordRtts = false
+		pinger.Count = 0
+		pinger.Size = 56
+		if pb.Size > 0 {
+			pinger.Size = pb.Size
+		}
+		pinger.OnSend = onSend
+		pinger.OnRecv = onRecv
+		return pinger, nil
+	}
+
+	// run pings in the background; the result arrives on the returned channel
+	run := func(pinger *probing.Pinger) chan error {
+		errCh := make(chan error, 1)
+		go func() { errCh <- pinger.Run() }()
+		return errCh
+	}
+
+	// Run until cancel/error
+	pinger, err := newPinger(addr)
+	if err != nil {
+		return err
+	}
+	errCh := run(pinger)
+	cur := pinger.IPAddr().IP.String()
+	if pb.OnResolve != nil {
+		pb.OnResolve("", cur)
+	}
+	var moved <-chan string
+	if pb.Reresolve > 0 && net.ParseIP(addr) == nil {
+		moved = watchResolve(ctx, addr, pb.Reresolve, cur)
+	}
+
+	jump := maxDur(suspendMin, 3*pb.Interval)
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			pinger.Stop()
+			mu.Lock()
+			dropPending()
+			mu.Unlock()
+			return ctx.Err()
+		case err := <-errCh:
+			return err
+		case ip, ok := <-moved:
+			if !ok {
+				moved = nil // ctx is done, handled above
+				continue
+			}
+			next, err := newPinger(ip)
+			if err != nil {
+				continue // stay on the old address
+			}
+			pinger.Stop()
+			// drop the old probes before the new pinger reuses their seqs
+			mu.Lock()
+			clear(stale)
+			dropPending()
+			push(Sample{T: time.Now(), MS: -1, Seq: -1, State: SampleGap})
+			mu.Unlock()
+			pinger, errCh = next, run(next)
+			if pb.OnResolve != nil {
+				pb.OnResolve(cur, ip)
+			}
+			cur = ip
+		case now := <-tick.C:
+			if !clockJumped(last, now, jump) {
+				last = now
+				continue
+			}
+			last = now
+			mu.Lock()
+			clear(stale)
+			dropPending()
+			push(Sample{T: now, MS: -1, Seq: -1, State: SampleGap})
+			mu.Unlock()
+		}
+	}
+}
+
+// watchResolve looks name up every interval and sends the new address
+// whenever cur is no longer among the answers, preferring cur's family.
+// Failed lookups are skipped; the channel is closed with ctx.
+func watchResolve(ctx context.Context, name string, every time.Duration, cur string) <-chan string {
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		tick := time.NewTicker(every)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			}
+			lctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+			ips, err := net.DefaultResolver.LookupIPAddr(lctx, name)
+			cancel()
+			next := pickMoved(ips, cur)
+			if err != nil || next == "" {
+				continue
+			}
+			select {
+			case out <- next:
+				cur = next
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// pickMoved returns "" while cur is still one of ips, otherwise the first
+// of ips in cur's address family (or of any family when there is none).
+func pickMoved(ips []net.IPAddr, cur string) string {
+	was := net.ParseIP(cur)
+	first := ""
+	for _, ip := range ips {
+		if ip.IP.Equal(was) {
+			return ""
+		}
+		if first == "" && (was == nil || (ip.IP.To4() != nil) == (was.To4() != nil)) {
+			first = ip.IP.String()
+		}
+	}
+	if first == "" && len(ips) > 0 {
+		first = ips[0].IP.String()
+	}
+	return first
+}
+
+// clockJumped reports whether more than jump passed between two readings
+// that should be about a second apart: either on the wall clock alone (the
+// monotonic clock is paused while suspended) or on both (the process was
+// stopped or starved).
+func clockJumped(prev, now time.Time, jump time.Duration) bool {
+	mono := now.Sub(prev)
+	wall := now.Round(0).Sub(prev.Round(0))
+	return wall-mono > jump || mono > jump
+}
+
+// OnceResult is the outcome of one ProbeAll probe. Err is set when the
+// address could not be probed at all (unresolvable, no permission, ...).
+type OnceResult struct {
+	Addr string
+	Up   bool
+	RTT  time.Duration // valid when Up
+	Err  error
+}
+
+// ProbeAll sends a single echo request to every address in parallel and
+// waits up to MaxRTT (default 1 s) for the replies. Each probe uses its own
+// pinger, so a continuous Run on the same addresses is not disturbed.
+// sizes, if not nil, holds each address's payload size, as Run sends it
+// (0 for pb.Size). Results are in the order of addrs.
+func (pb ProbingBackend) ProbeAll(ctx context.Context, addrs []string, sizes []int) []OnceResult {
+	if pb.MaxRTT <= 0 {
+		pb.MaxRTT = time.Second
+	}
+	res := make([]OnceResult, len(addrs))
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		pb := pb
+		if i < len(sizes) && sizes[i] > 0 {
+			pb.Size = sizes[i]
+		}
+		go func() {
+			defer wg.Done()
+			res[i] = pb.probeOnce(ctx, addr)
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+func (pb ProbingBackend) probeOnce(ctx context.Context, addr string) OnceResult {
+	r := OnceResult{Addr: addr}
+	if _, ok := CommandLine(addr); ok {
+		r.Err = ErrCommandHost
+		return r
+	}
+	pinger, err := probing.NewPinger(addr)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	pb.setPrivileged(pinger)
+	pb.setSource(pinger)
+	pinger.Count = 1
+	pinger.Timeout = pb.MaxRTT
+	pinger.Size = 56
+	if pb.Size > 0 {
+		pinger.Size = pb.Size
+	}
+	pinger.OnRecv = func(pkt *probing.Packet) {
+		r.Up, r.RTT = true, pkt.Rtt
+	}
+	if err := pinger.RunWithContext(ctx); err != nil && ctx.Err() == nil {
+		r.Err = err
+	}
+	return r
+}
+
+// BurstMax caps Burst's count: enough for a momentary quality check, too
+// few to flood anyone.
+const BurstMax = 1000
+
+// burstGap spaces a burst's echo requests: back to back in practice, but
+// never a zero interval inside pro-bing.
+const burstGap = 2 * time.Millisecond
+
+// BurstResult summarizes a Burst. The RTT fields are zero without replies.
+type BurstResult struct {
+	Addr          string
+	Sent, Recv    int
+	LossPct       float64
+	Min, Avg, Max time.Duration
+	StdDev        time.Duration
+	Took          time.Duration // first request to last reply or timeout
+}
+
+// Burst sends count echo requests (at most BurstMax) to addr as fast as
+// possible and waits up to MaxRTT (default 1 s) after the last one for
+// replies. Like ProbeAll it uses its own pinger, so a continuous Run on
+// addr is not disturbed.
+func (pb ProbingBackend) Burst(ctx context.Context, addr string, count int) (BurstResult, error) {
+	r := BurstResult{Addr: addr}
+	count = min(max(count, 1), BurstMax)
+	if pb.MaxRTT <= 0 {
+		pb.MaxRTT = time.Second
+	}
+	if _, ok := CommandLine(addr); ok {
+		return r, ErrCommandHost
+	}
+	pinger, err := probing.NewPinger(addr)
+	if err != nil {
+		return r, err
+	}
+	pb.setPrivileged(pinger)
+	pb.setSource(pinger)
+	pinger.Count = count
+	pinger.Interval = burstGap
+	pinger.Timeout = time.Duration(count)*burstGap + pb.MaxRTT
+	pinger.Size = 56
+	if pb.Size > 0 {
+		pinger.Size = pb.Size
+	}
+	start := time.Now()
+	if err := pinger.RunWithContext(ctx); err != nil && ctx.Err() == nil {
+		return r, err
+	}
+	r.Took = time.Since(start)
+	st := pinger.Statistics()
+	r.Sent, r.Recv, r.LossPct = st.PacketsSent, st.PacketsRecv, st.PacketLoss
+	if st.PacketsRecv > 0 {
+		r.Min, r.Avg, r.Max, r.StdDev = st.MinRtt, st.AvgRtt, st.MaxRtt, st.StdDevRtt
+	}
+	return r, ctx.Err()
+}
+
+func (pb ProbingBackend) setPrivileged(pinger *probing.Pinger) {
+	if runtime.GOOS == "windows" {
+		// on windows it works as privileged, without need to be privileged at all :)
+		pinger.SetPrivileged(true)
+	} else {
+		pinger.SetPrivileged(pb.Privileged)
+	}
+}
+
+// setSource binds pinger to pb.Source when the target is of the same
+// address family; a dual-stack host list keeps pinging its other half.
+func (pb ProbingBackend) setSource(pinger *probing.Pinger) {
+	src := net.ParseIP(pb.Source)
+	dst := pinger.IPAddr()
+	if src == nil || dst == nil {
+		return
+	}
+	if (src.To4() != nil) == (dst.IP.To4() != nil) {
+		pinger.Source = pb.Source
+	}
+}
+
+func maxDur(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
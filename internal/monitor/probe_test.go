@@ -0,0 +1,125 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+import (
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSeqMissing(t *testing.T) {
+	tests := []struct {
+		name      string
+		last, seq int
+		want      []int
+	}{
+		{"first reply", -1, 7, nil},
+		{"next in line", 4, 5, nil},
+		{"one missing", 4, 6, []int{5}},
+		{"several missing", 10, 14, []int{11, 12, 13}},
+		{"wraparound in line", 65535, 0, nil},
+		{"wraparound missing", 65534, 1, []int{65535, 0}},
+		{"duplicate", 9, 9, nil},
+		{"out of order", 9, 8, nil},
+		{"out of order across wrap", 0, 65535, nil},
+		{"gap at the limit", 0, seqGapMax, seqRange(1, seqGapMax)},
+		{"gap over the limit", 0, seqGapMax + 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seqMissing(tt.last, tt.seq); !slices.Equal(got, tt.want) {
+				t.Errorf("seqMissing(%d, %d) = %v, want %v", tt.last, tt.seq, got, tt.want)
+			}
+		})
+	}
+}
+
+// seqRange returns from..to-1.
+func seqRange(from, to int) []int {
+	var out []int
+	for i := from; i < to; i++ {
+		out = append(out, i)
+	}
+	return out
+}
+
+func TestClockJumped(t *testing.T) {
+	const jump = 5 * time.Second
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    bool
+	}{
+		{"a tick", time.Second, false},
+		{"below", jump - time.Millisecond, false},
+		{"at", jump, false},
+		{"above", jump + time.Millisecond, true},
+		{"backwards", -time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// with a monotonic reading, and with the wall clock only
+			prev := time.Now()
+			if got := clockJumped(prev, prev.Add(tt.elapsed), jump); got != tt.want {
+				t.Errorf("monotonic: clockJumped after %v = %v, want %v", tt.elapsed, got, tt.want)
+			}
+			prev = prev.Round(0)
+			if got := clockJumped(prev, prev.Add(tt.elapsed), jump); got != tt.want {
+				t.Errorf("wall: clockJumped after %v = %v, want %v", tt.elapsed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickMoved(t *testing.T) {
+	addrs := func(ips ...string) []net.IPAddr {
+		out := make([]net.IPAddr, len(ips))
+		for i, ip := range ips {
+			out[i] = net.IPAddr{IP: net.ParseIP(ip)}
+		}
+		return out
+	}
+	tests := []struct {
+		name string
+		ips  []net.IPAddr
+		cur  string
+		want string
+	}{
+		{"no answers", nil, "192.0.2.1", ""},
+		{"unchanged", addrs("192.0.2.1"), "192.0.2.1", ""},
+		{"still among the answers", addrs("192.0.2.9", "192.0.2.1"), "192.0.2.1", ""},
+		{"moved", addrs("192.0.2.9", "192.0.2.8"), "192.0.2.1", "192.0.2.9"},
+		{"keeps IPv4", addrs("2001:db8::9", "192.0.2.9"), "192.0.2.1", "192.0.2.9"},
+		{"keeps IPv6", addrs("192.0.2.9", "2001:db8::9"), "2001:db8::1", "2001:db8::9"},
+		{"same address spelled differently", addrs("2001:db8:0::1"), "2001:db8::1", ""},
+		{"family gone", addrs("2001:db8::9"), "192.0.2.1", "2001:db8::9"},
+		{"no current address", addrs("2001:db8::9", "192.0.2.9"), "", "2001:db8::9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickMoved(tt.ips, tt.cur); got != tt.want {
+				t.Errorf("pickMoved(%v, %q) = %q, want %q", tt.ips, tt.cur, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+// Samples and the fixed-size per-host ring they are kept in.
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+type SampleState int
+
+const (
+	SampleOK   SampleState = iota
+	SampleLoss             // timeout -> loss
+	SampleLate             // arrived in grace window after timeout
+	SampleGap              // the machine was suspended: no data, not a loss
+)
+
+func (s SampleState) String() string {
+	switch s {
+	case SampleOK:
+		return "ok"
+	case SampleLoss:
+		return "loss"
+	case SampleLate:
+		return "late"
+	case SampleGap:
+		return "gap"
+	}
+	return "unknown"
+}
+
+const (
+	// Default number of samples retained per host (roughly ~10 minutes at 1s).
+	DefaultRingCap = 600
+)
+
+type Sample struct {
+	T time.Time `json:"t"`
+	// ms latency; negative for loss/timeouts and gaps
+	MS    float64     `json:"ms"`
+	Seq   int         `json:"seq"`   // ICMP sequence number
+	State SampleState `json:"state"` // OK, Loss, Late
+}
+
+type Ring struct {
+	mu    sync.RWMutex
+	data  []Sample
+	head  int
+	count int
+}
+
+func NewRing(capacity int) *Ring {
+	return &Ring{data: make([]Sample, capacity)}
+}
+
+func (r *Ring) Push(s Sample) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.data) == 0 {
+		return -1
+	}
+	idx := r.head
+	r.data[idx] = s
+	r.head = (r.head + 1) % len(r.data)
+	if r.count < len(r.data) {
+		r.count++
+	}
+	return idx
+}
+
+func (r *Ring) UpdateAt(idx int, update func(*Sample)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.count == 0 || idx < 0 || idx >= len(r.data) {
+		return
+	}
+	update(&r.data[idx])
+}
+
+func (r *Ring) Snapshot(dst []Sample) []Sample {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.count == 0 {
+		return dst[:0]
+	}
+	n := r.count
+	if cap(dst) < n {
+		dst = make([]Sample, n)
+	} else {
+		dst = dst[:n]
+	}
+	start := (r.head - r.count + len(r.data)) % len(r.data)
+	for i := 0; i < n; i++ {
+		dst[i] = r.data[(start+i)%len(r.data)]
+	}
+	return dst
+}
+
+// FrameBuffers snapshots a set of rings into buffers kept from one call to
+// the next, so repeated snapshots (one per painted frame) stop allocating
+// once the buffers have grown to the rings' size. The zero value is ready.
+type FrameBuffers struct {
+	bufs  map[*Ring][]Sample
+	frame [][]Sample
+}
+
+// Snapshot copies every ring, oldest sample first. The result is
+// index-aligned with rings and only valid until the next call. Buffers of
+// rings no longer passed are dropped.
+func (f *FrameBuffers) Snapshot(rings []*Ring) [][]Sample {
+	if f.bufs == nil {
+		f.bufs = make(map[*Ring][]Sample)
+	}
+	f.frame = f.frame[:0]
+	for _, r := range rings {
+		buf := r.Snapshot(f.bufs[r])
+		f.bufs[r] = buf
+		f.frame = append(f.frame, buf)
+	}
+	if len(f.bufs) > len(rings) {
+		for r := range f.bufs {
+			if !slices.Contains(rings, r) {
+				delete(f.bufs, r)
+			}
+		}
+	}
+	return f.frame
+}
+
+// Last returns the newest sample that isn't a SampleGap.
+func (r *Ring) Last() (Sample, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := 0; i < r.count; i++ {
+		s := r.data[(r.head-1-i+len(r.data))%len(r.data)]
+		if s.State != SampleGap {
+			return s, true
+		}
+	}
+	return Sample{}, false
+}
+
+// LossSince counts samples newer than t and how many of them were lost.
+func (r *Ring) LossSince(t time.Time) (total, lost int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := 0; i < r.count; i++ {
+		s := r.data[(r.head-1-i+len(r.data))%len(r.data)]
+		if !s.T.After(t) {
+			break // ring is time ordered, newest first here
+		}
+		if s.State == SampleGap {
+			continue
+		}
+		total++
+		if s.State == SampleLoss {
+			lost++
+		}
+	}
+	return total, lost
+}
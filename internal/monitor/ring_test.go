@@ -0,0 +1,78 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func filledRings(n, capacity int) []*Ring {
+	t0 := time.Now()
+	rings := make([]*Ring, n)
+	for i := range rings {
+		rings[i] = NewRing(capacity)
+		for j := 0; j < capacity+capacity/2; j++ {
+			rings[i].Push(Sample{T: t0.Add(time.Duration(j) * time.Second), MS: float64(j), Seq: j})
+		}
+	}
+	return rings
+}
+
+func TestFrameBuffers(t *testing.T) {
+	rings := filledRings(3, 10)
+	var f FrameBuffers
+	frame := f.Snapshot(rings)
+	if len(frame) != 3 {
+		t.Fatalf("got %d snapshots, want 3", len(frame))
+	}
+	for i, s := range frame {
+		if len(s) != 10 || s[0].Seq != 5 || s[9].Seq != 14 {
+			t.Errorf("ring %d: got %d samples, seq %d..%d; want 10, 5..14", i, len(s), s[0].Seq, s[len(s)-1].Seq)
+		}
+	}
+	if allocs := testing.AllocsPerRun(10, func() { f.Snapshot(rings) }); allocs != 0 {
+		t.Errorf("steady-state Snapshot allocates %v times, want 0", allocs)
+	}
+	f.Snapshot(rings[:1])
+	if len(f.bufs) != 1 {
+		t.Errorf("kept %d buffers after rings were dropped, want 1", len(f.bufs))
+	}
+}
+
+func BenchmarkFrameBuffers(b *testing.B) {
+	rings := filledRings(10, DefaultRingCap)
+	var f FrameBuffers
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.Snapshot(rings)
+	}
+}
+
+func BenchmarkRingSnapshotFresh(b *testing.B) {
+	rings := filledRings(10, DefaultRingCap)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, r := range rings {
+			r.Snapshot(nil)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+// Summaries over []Sample: per-host reports, loss windows, flapping, the
+// worst point of a window and the overall health score.
+
+import (
+	"math"
+	"time"
+)
+
+// HostReport is a per-host summary of the samples currently in its ring.
+// Latencies are in ms and only cover replies (ok + late); loss counts timeouts.
+type HostReport struct {
+	Name    string  `json:"name"`
+	Addr    string  `json:"addr"`
+	Note    string  `json:"note,omitempty"`
+	Min     float64 `json:"min_ms"`
+	Avg     float64 `json:"avg_ms"`
+	Max     float64 `json:"max_ms"`
+	Jitter  float64 `json:"jitter_ms"` // mean |Δ| between consecutive replies
+	LossPct float64 `json:"loss_pct"`
+	Samples int     `json:"samples"`
+}
+
+func ComputeReport(name, addr string, samples []Sample) HostReport {
+	r := HostReport{Name: name, Addr: addr}
+	lost, replies := 0, 0
+	sum, jsum := 0.0, 0.0
+	prev := math.NaN()
+	r.Min = math.MaxFloat64
+	for _, s := range samples {
+		if s.State == SampleGap {
+			prev = math.NaN() // no jitter across a suspend
+			continue
+		}
+		r.Samples++
+		if s.State == SampleLoss || s.MS < 0 {
+			lost++
+			continue
+		}
+		replies++
+		sum += s.MS
+		r.Min = math.Min(r.Min, s.MS)
+		r.Max = math.Max(r.Max, s.MS)
+		if !math.IsNaN(prev) {
+			jsum += math.Abs(s.MS - prev)
+		}
+		prev = s.MS
+	}
+
+	if r.Samples == 0 {
+		r.Min = 0
+		return r
+	}
+	r.LossPct = 100 * float64(lost) / float64(r.Samples)
+	if replies == 0 {
+		r.Min = 0 // all lost: no latency figures
+		return r
+	}
+	r.Avg = sum / float64(replies)
+	if replies > 1 {
+		r.Jitter = jsum / float64(replies-1)
+	}
+	return r
+}
+
+// FlapCount counts up/down transitions (reply ↔ loss) among samples newer
+// than since. Late replies count as up; a gap (suspend) starts over. A host
+// can have low loss yet flap constantly, which is what this is meant to surface.
+func FlapCount(samples []Sample, since time.Time) int {
+	n := 0
+	first := true
+	var prevUp bool
+	for _, s := range samples {
+		if s.T.Before(since) {
+			continue
+		}
+		if s.State == SampleGap {
+			first = true
+			continue
+		}
+		up := s.State != SampleLoss
+		if !first && up != prevUp {
+			n++
+		}
+		prevUp, first = up, false
+	}
+	return n
+}
+
+// HealthScore folds all hosts' reports into one 0–100 number (-1: no data).
+// Each host starts at 100 and loses:
+//   - 5 points per % of loss (20% loss alone scores 0),
+//   - 1 point per 10 ms average RTT above 50 ms, at most 30,
+//   - 1 point per 5 ms jitter above 10 ms, at most 10.
+//
+// The result is the mean over hosts with samples, pulled halfway towards the
+// worst host so a single dead target still shows clearly.
+func HealthScore(reports []HostReport) int {
+	sum, worst, n := 0.0, 100.0, 0
+	for _, r := range reports {
+		if r.Samples == 0 {
+			continue
+		}
+		score := 100 - 5*r.LossPct
+		score -= math.Min(math.Max(r.Avg-50, 0)/10, 30)
+		score -= math.Min(math.Max(r.Jitter-10, 0)/5, 10)
+		score = math.Max(score, 0)
+		sum += score
+		worst = math.Min(worst, score)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return int(math.Round((sum/float64(n) + worst) / 2))
+}
+
+// WindowLoss returns the loss percentage of samples newer than since (ok=false if none).
+func WindowLoss(samples []Sample, since time.Time) (pct float64, ok bool) {
+	total, lost := 0, 0
+	for _, s := range samples {
+		if s.T.Before(since) || s.State == SampleGap {
+			continue
+		}
+		total++
+		if s.State == SampleLoss {
+			lost++
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return 100 * float64(lost) / float64(total), true
+}
+
+// Worst is the low point of a window: the slowest on-time reply and the
+// longest run of consecutive losses (RunLen 0 when nothing was lost).
+type Worst struct {
+	Slowest    Sample
+	HasSlowest bool
+	RunStart   time.Time // first and last loss of the run
+	RunEnd     time.Time
+	RunLen     int
+}
+
+// WorstIn finds the Worst among samples within [since, until]. Ties go to
+// the most recent sample or run. Late replies and gaps end a loss run.
+func WorstIn(samples []Sample, since, until time.Time) Worst {
+	var w Worst
+	run := 0
+	var runStart time.Time
+	for _, s := range samples {
+		if s.T.Before(since) || s.T.After(until) {
+			continue
+		}
+		if s.State != SampleLoss {
+			run = 0
+		}
+		switch s.State {
+		case SampleOK:
+			if !w.HasSlowest || s.MS >= w.Slowest.MS {
+				w.Slowest, w.HasSlowest = s, true
+			}
+		case SampleLoss:
+			if run == 0 {
+				runStart = s.T
+			}
+			run++
+			if run >= w.RunLen {
+				w.RunLen, w.RunStart, w.RunEnd = run, runStart, s.T
+			}
+		}
+	}
+	return w
+}
@@ -0,0 +1,199 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeReport(t *testing.T) {
+	ok := func(ms float64) Sample { return Sample{MS: ms, State: SampleOK} }
+	loss := Sample{MS: -1, State: SampleLoss}
+	gap := Sample{State: SampleGap}
+
+	tests := []struct {
+		name    string
+		samples []Sample
+		want    HostReport
+	}{
+		{"empty", nil, HostReport{}},
+		{"only gaps", []Sample{gap, gap}, HostReport{}},
+		{"all lost", []Sample{loss, loss, loss}, HostReport{LossPct: 100, Samples: 3}},
+		{"single reply", []Sample{ok(12)}, HostReport{Min: 12, Avg: 12, Max: 12, Samples: 1}},
+		{"single loss", []Sample{loss}, HostReport{LossPct: 100, Samples: 1}},
+		{"mixed", []Sample{ok(10), loss, ok(20), ok(15)},
+			HostReport{Min: 10, Avg: 15, Max: 20, Jitter: 7.5, LossPct: 25, Samples: 4}},
+		{"late reply counts", []Sample{ok(10), {MS: 30, State: SampleLate}},
+			HostReport{Min: 10, Avg: 20, Max: 30, Jitter: 20, Samples: 2}},
+		{"no jitter across a gap", []Sample{ok(10), gap, ok(50)},
+			HostReport{Min: 10, Avg: 30, Max: 50, Samples: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeReport("", "", tt.samples)
+			for _, f := range []float64{got.Min, got.Avg, got.Max, got.Jitter, got.LossPct} {
+				if math.IsNaN(f) || math.IsInf(f, 0) {
+					t.Fatalf("non-finite field in %+v", got)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthScore(t *testing.T) {
+	host := func(loss, avg, jitter float64) HostReport {
+		return HostReport{LossPct: loss, Avg: avg, Jitter: jitter, Samples: 100}
+	}
+	good := host(0, 20, 2)
+	tests := []struct {
+		name    string
+		reports []HostReport
+		want    int
+	}{
+		{"no hosts", nil, -1},
+		{"no samples yet", []HostReport{{}, {}}, -1},
+		{"healthy", []HostReport{good}, 100},
+		{"hosts without samples are left out", []HostReport{{}, good}, 100},
+		{"all lost", []HostReport{{LossPct: 100, Samples: 5}}, 0},
+		{"loss", []HostReport{host(4, 20, 2)}, 80},
+		{"RTT above 50 ms", []HostReport{host(0, 150, 2)}, 90},
+		{"RTT penalty capped", []HostReport{host(0, 5000, 2)}, 70},
+		{"jitter above 10 ms", []HostReport{host(0, 20, 30)}, 96},
+		{"jitter penalty capped", []HostReport{host(0, 20, 500)}, 90},
+		{"one dead host pulls the score down", []HostReport{good, good, host(100, 0, 0)}, 33},
+		{"clamped at 0", []HostReport{host(50, 5000, 500), good}, 25},
+		{"fast hosts earn no bonus", []HostReport{host(0, 1, 0)}, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HealthScore(tt.reports); got != tt.want {
+				t.Errorf("HealthScore = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// series returns one sample per second from t0 in the given states.
+func series(t0 time.Time, states ...SampleState) []Sample {
+	out := make([]Sample, len(states))
+	for i, st := range states {
+		out[i] = Sample{T: t0.Add(time.Duration(i) * time.Second), MS: 10, Seq: i, State: st}
+		if st == SampleLoss || st == SampleGap {
+			out[i].MS = -1
+		}
+	}
+	return out
+}
+
+func TestFlapCount(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	const (
+		up   = SampleOK
+		down = SampleLoss
+		late = SampleLate
+		gap  = SampleGap
+	)
+	tests := []struct {
+		name   string
+		states []SampleState
+		since  time.Duration // window starts at t0+since
+		want   int
+	}{
+		{"empty", nil, 0, 0},
+		{"steady", []SampleState{up, up, up}, 0, 0},
+		{"all lost", []SampleState{down, down}, 0, 0},
+		{"alternating", []SampleState{up, down, up, down, up}, 0, 4},
+		{"late counts as up", []SampleState{up, late, up}, 0, 0},
+		{"loss then late", []SampleState{down, late}, 0, 1},
+		{"gap starts over", []SampleState{up, gap, down}, 0, 0},
+		{"counts on both sides of a gap", []SampleState{up, down, gap, up, down}, 0, 2},
+		{"older samples left out", []SampleState{up, down, up, down}, 2 * time.Second, 1},
+		{"no transition across the window start", []SampleState{down, up, up}, time.Second, 0},
+		{"sample at the window start included", []SampleState{down, up, down}, time.Second, 1},
+		{"window after the last sample", []SampleState{up, down}, time.Minute, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FlapCount(series(t0, tt.states...), t0.Add(tt.since)); got != tt.want {
+				t.Errorf("FlapCount = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorstIn(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return t0.Add(time.Duration(i) * time.Second) }
+	// one sample per second from t0; ms < 0 is a loss, "late"/"gap" by state
+	mk := func(states []SampleState, ms []float64) []Sample {
+		out := make([]Sample, len(states))
+		for i := range states {
+			out[i] = Sample{T: at(i), MS: ms[i], Seq: i, State: states[i]}
+		}
+		return out
+	}
+	const (
+		up   = SampleOK
+		down = SampleLoss
+		late = SampleLate
+		gap  = SampleGap
+	)
+	tests := []struct {
+		name         string
+		states       []SampleState
+		ms           []float64
+		from, to     int // window [at(from), at(to)]
+		slowest      int // index of the slowest reply, -1 for none
+		runLen       int
+		runFrom, end int
+	}{
+		{"empty", nil, nil, 0, 10, -1, 0, 0, 0},
+		{"nothing in the window", []SampleState{up, down}, []float64{10, -1}, 5, 10, -1, 0, 0, 0},
+		{"slowest reply", []SampleState{up, up, up}, []float64{10, 40, 20}, 0, 10, 1, 0, 0, 0},
+		{"slowest tie goes to the most recent", []SampleState{up, up, up}, []float64{40, 10, 40}, 0, 10, 2, 0, 0, 0},
+		{"longest loss run", []SampleState{down, down, down, up, down}, []float64{-1, -1, -1, 5, -1}, 0, 10, 3, 3, 0, 2},
+		{"run tie goes to the most recent", []SampleState{down, down, up, down, down}, []float64{-1, -1, 5, -1, -1}, 0, 10, 2, 2, 3, 4},
+		{"late reply ends a run and isn't the slowest", []SampleState{down, late, down}, []float64{-1, 900, -1}, 0, 10, -1, 1, 2, 2},
+		{"gap ends a run", []SampleState{down, gap, down}, []float64{-1, -1, -1}, 0, 10, -1, 1, 2, 2},
+		{"window bounds inclusive", []SampleState{up, down, down, up, up}, []float64{90, -1, -1, 20, 80}, 1, 3, 3, 2, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := mk(tt.states, tt.ms)
+			got := WorstIn(samples, at(tt.from), at(tt.to))
+			var want Worst
+			if tt.slowest >= 0 {
+				want.Slowest, want.HasSlowest = samples[tt.slowest], true
+			}
+			if tt.runLen > 0 {
+				want.RunLen, want.RunStart, want.RunEnd = tt.runLen, at(tt.runFrom), at(tt.end)
+			}
+			if got != want {
+				t.Errorf("WorstIn = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+// Automatic end of a ping session: after a duration, a number of samples or
+// a run of consecutive losses.
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StopCondition says when a session should end by itself. Zero fields are off.
+type StopCondition struct {
+	Duration   time.Duration // wall time since Arm
+	Samples    int           // samples per host (counted on the busiest host)
+	LossStreak int           // consecutive losses on any one host
+}
+
+func (c StopCondition) Enabled() bool {
+	return c.Duration > 0 || c.Samples > 0 || c.LossStreak > 0
+}
+
+// StopWatch evaluates a StopCondition. Observe is fed from the sample stream
+// (any goroutine); Due is polled from a ticker. Samples are counted on the
+// busiest host so hosts that never answer (or never start, e.g. unresolvable
+// names) can't keep a session open; a host only ends it through LossStreak.
+type StopWatch struct {
+	cond  StopCondition
+	start time.Time
+
+	mu      sync.Mutex
+	counts  map[string]int          // samples per host key
+	streaks map[string]int          // current consecutive losses per host key
+	lost    map[string]map[int]bool // lost seqs that may still turn late
+	reason  string                  // first condition met, "" while running
+}
+
+// Arm starts a watch at now.
+func Arm(c StopCondition, now time.Time) *StopWatch {
+	return &StopWatch{
+		cond:    c,
+		start:   now,
+		counts:  make(map[string]int),
+		streaks: make(map[string]int),
+		lost:    make(map[string]map[int]bool),
+	}
+}
+
+// Observe records one sample for the host identified by key. A loss later
+// reconciled into a late reply (same probe) ends the loss streak but isn't
+// counted twice.
+func (w *StopWatch) Observe(key string, s Sample) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.reason != "" {
+		return
+	}
+	if s.State == SampleGap {
+		w.streaks[key] = 0 // losses before and after a suspend aren't a streak
+		return
+	}
+	lost := w.lost[key]
+	if lost == nil {
+		lost = make(map[int]bool)
+		w.lost[key] = lost
+	}
+	reconciled := s.State == SampleLate && lost[s.Seq]
+	delete(lost, s.Seq)
+	if !reconciled {
+		w.counts[key]++
+		if n := w.counts[key]; w.cond.Samples > 0 && n >= w.cond.Samples {
+			w.reason = fmt.Sprintf("%d samples collected", n)
+		}
+	}
+	if s.State == SampleLoss {
+		if len(lost) > 1024 {
+			clear(lost)
+		}
+		lost[s.Seq] = true
+		w.streaks[key]++
+		if n := w.streaks[key]; w.cond.LossStreak > 0 && n >= w.cond.LossStreak {
+			w.reason = fmt.Sprintf("%s lost %d in a row", key, n)
+		}
+	} else {
+		w.streaks[key] = 0
+	}
+}
+
+// Due reports whether a condition has been met by now, and which one.
+func (w *StopWatch) Due(now time.Time) (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.reason == "" && w.cond.Duration > 0 && now.Sub(w.start) >= w.cond.Duration {
+		w.reason = "ran for " + w.cond.Duration.String()
+	}
+	return w.reason, w.reason != ""
+}
@@ -0,0 +1,96 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStopWatch(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ok := func(seq int) Sample { return Sample{T: t0, MS: 10, Seq: seq, State: SampleOK} }
+	loss := func(seq int) Sample { return Sample{T: t0, MS: -1, Seq: seq, State: SampleLoss} }
+	late := func(seq int) Sample { return Sample{T: t0, MS: 1500, Seq: seq, State: SampleLate} }
+	gap := Sample{T: t0, MS: -1, Seq: -1, State: SampleGap}
+
+	type obs struct {
+		key string
+		s   Sample
+	}
+	tests := []struct {
+		name   string
+		cond   StopCondition
+		obs    []obs
+		at     time.Duration // Due is asked at t0+at
+		reason string        // "" = not due
+	}{
+		{"off", StopCondition{}, []obs{{"a", loss(0)}, {"a", loss(1)}}, time.Hour, ""},
+		{"before the duration", StopCondition{Duration: time.Minute}, nil, 59 * time.Second, ""},
+		{"at the duration", StopCondition{Duration: time.Minute}, nil, time.Minute, "ran for 1m0s"},
+		{"samples counted per host", StopCondition{Samples: 3},
+			[]obs{{"a", ok(0)}, {"b", ok(0)}, {"a", ok(1)}, {"b", ok(1)}}, 0, ""},
+		{"sample limit", StopCondition{Samples: 3},
+			[]obs{{"a", ok(0)}, {"a", loss(1)}, {"a", ok(2)}}, 0, "3 samples collected"},
+		{"silent host can't hold the session open", StopCondition{Samples: 2},
+			[]obs{{"a", ok(0)}, {"a", ok(1)}}, 0, "2 samples collected"},
+		{"loss streak", StopCondition{LossStreak: 3},
+			[]obs{{"a", loss(0)}, {"a", loss(1)}, {"a", loss(2)}}, 0, "a lost 3 in a row"},
+		{"reply ends the streak", StopCondition{LossStreak: 3},
+			[]obs{{"a", loss(0)}, {"a", loss(1)}, {"a", ok(2)}, {"a", loss(3)}, {"a", loss(4)}}, 0, ""},
+		{"streaks are per host", StopCondition{LossStreak: 3},
+			[]obs{{"a", loss(0)}, {"b", loss(0)}, {"a", loss(1)}, {"b", loss(1)}}, 0, ""},
+		{"gap ends the streak", StopCondition{LossStreak: 3},
+			[]obs{{"a", loss(0)}, {"a", loss(1)}, {"a", gap}, {"a", loss(2)}}, 0, ""},
+		{"late reply ends the streak", StopCondition{LossStreak: 3},
+			[]obs{{"a", loss(0)}, {"a", loss(1)}, {"a", late(1)}, {"a", loss(2)}}, 0, ""},
+		{"late reply isn't counted twice", StopCondition{Samples: 3},
+			[]obs{{"a", ok(0)}, {"a", loss(1)}, {"a", late(1)}}, 0, ""},
+		{"first condition met wins", StopCondition{Samples: 2, LossStreak: 3},
+			[]obs{{"a", ok(0)}, {"a", ok(1)}, {"a", loss(2)}, {"a", loss(3)}, {"a", loss(4)}}, time.Hour, "2 samples collected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := Arm(tt.cond, t0)
+			for _, o := range tt.obs {
+				w.Observe(o.key, o.s)
+			}
+			reason, due := w.Due(t0.Add(tt.at))
+			if reason != tt.reason || due != (tt.reason != "") {
+				t.Errorf("Due = %q, %v; want %q", reason, due, tt.reason)
+			}
+		})
+	}
+}
+
+func TestStopWatchAfterStop(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	w := Arm(StopCondition{Samples: 1, LossStreak: 1}, t0)
+	w.Observe("a", Sample{T: t0, MS: 10, Seq: 0, State: SampleOK})
+	// samples still arriving while the session winds down
+	w.Observe("a", Sample{T: t0, MS: -1, Seq: 1, State: SampleLoss})
+	w.Observe("a", Sample{T: t0, MS: 900, Seq: 1, State: SampleLate})
+	for _, at := range []time.Duration{0, time.Hour} {
+		if reason, due := w.Due(t0.Add(at)); !due || reason != "1 samples collected" {
+			t.Errorf("Due(+%s) = %q, %v; want the first reason kept", at, reason, due)
+		}
+	}
+}
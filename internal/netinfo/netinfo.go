@@ -0,0 +1,174 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package netinfo
+
+// Local network facts (default gateway, ...) gathered from the OS tools.
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var ErrNoGateway = errors.New("netinfo: default gateway not found")
+
+// DefaultGateway returns the IPv4 address of the default route's next hop.
+func DefaultGateway() (string, error) {
+	switch runtime.GOOS {
+	case "linux":
+		// /proc is cheapest and always there; fall back to iproute2 for odd setups
+		if f, err := os.Open("/proc/net/route"); err == nil {
+			defer f.Close()
+			if gw, err := parseProcRoute(f); err == nil {
+				return gw, nil
+			}
+		}
+		out, err := runTool("ip", "-4", "route", "show", "default")
+		if err != nil {
+			return "", err
+		}
+		return parseIPRoute(out)
+	case "darwin", "freebsd", "openbsd", "netbsd":
+		out, err := runTool("route", "-n", "get", "default")
+		if err != nil {
+			return "", err
+		}
+		return parseRouteGet(out)
+	case "windows":
+		out, err := runTool("route", "print", "-4", "0.0.0.0")
+		if err != nil {
+			return "", err
+		}
+		return parseRoutePrint(out)
+	default:
+		return "", fmt.Errorf("netinfo: unsupported OS %s", runtime.GOOS)
+	}
+}
+
+func runTool(bin string, args ...string) (string, error) {
+	cmd := exec.Command(bin, args...)
+	// hide external window
+	applyNoWindow(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("netinfo: %s: %w", bin, err)
+	}
+	return string(out), nil
+}
+
+// parseProcRoute reads Linux /proc/net/route, where addresses are
+// little-endian hex, e.g.:
+//
+//	Iface Destination Gateway  Flags RefCnt Use Metric Mask ...
+//	eth0  00000000    0101A8C0 0003  0      0   100    00000000 ...
+func parseProcRoute(f *os.File) (string, error) {
+	sc := bufio.NewScanner(f)
+	best, bestMetric := "", -1
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue // header or not a default route
+		}
+		raw, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil || raw == 0 {
+			continue
+		}
+		metric, _ := strconv.Atoi(fields[6])
+		if bestMetric >= 0 && metric >= bestMetric {
+			continue
+		}
+		ip := make(net.IP, 4)
+		binary.LittleEndian.PutUint32(ip, uint32(raw))
+		best, bestMetric = ip.String(), metric
+	}
+	if best == "" {
+		return "", ErrNoGateway
+	}
+	return best, nil
+}
+
+// parseIPRoute handles `ip route show default`:
+//
+//	default via 192.168.1.1 dev eth0 proto dhcp metric 100
+func parseIPRoute(out string) (string, error) {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == "via" && net.ParseIP(fields[i+1]) != nil {
+				return fields[i+1], nil
+			}
+		}
+	}
+	return "", ErrNoGateway
+}
+
+// parseRouteGet handles BSD/macOS `route -n get default`:
+//
+//	route to: default
+//	 gateway: 192.168.1.1
+func parseRouteGet(out string) (string, error) {
+	for _, line := range strings.Split(out, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || k != "gateway" {
+			continue
+		}
+		if v = strings.TrimSpace(v); net.ParseIP(v) != nil {
+			return v, nil
+		}
+	}
+	return "", ErrNoGateway
+}
+
+// parseRoutePrint handles Windows `route print -4 0.0.0.0`, picking the
+// active default route with the lowest metric:
+//
+//	Network Destination        Netmask          Gateway       Interface  Metric
+//	          0.0.0.0          0.0.0.0      192.168.1.1    192.168.1.20     25
+func parseRoutePrint(out string) (string, error) {
+	best, bestMetric := "", -1
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[0] != "0.0.0.0" || fields[1] != "0.0.0.0" {
+			continue
+		}
+		if net.ParseIP(fields[2]) == nil {
+			continue // "On-link" etc.
+		}
+		metric, err := strconv.Atoi(fields[4])
+		if err != nil {
+			continue
+		}
+		if bestMetric < 0 || metric < bestMetric {
+			best, bestMetric = fields[2], metric
+		}
+	}
+	if best == "" {
+		return "", ErrNoGateway
+	}
+	return best, nil
+}
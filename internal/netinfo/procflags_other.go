@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package netinfo
+
+import "os/exec"
+
+func applyNoWindow(cmd *exec.Cmd) {
+	// no-op on non-Windows
+}
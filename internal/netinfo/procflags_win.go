@@ -0,0 +1,16 @@
+//go:build windows
+// +build windows
+
+package netinfo
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func applyNoWindow(cmd *exec.Cmd) {
+	// Hide the console window for console subsystem children (route.exe, ...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow: true,
+	}
+}
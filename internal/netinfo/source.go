@@ -0,0 +1,82 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package netinfo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// SourceAddr is a local address packets can be sent from.
+type SourceAddr struct {
+	Iface string // interface name, e.g. "eth0" or "utun3"
+	IP    string
+}
+
+// SourceAddrs lists the unicast addresses of the interfaces that are up.
+// Loopback and IPv6 link-local addresses (which need a zone) are left out.
+func SourceAddrs() ([]SourceAddr, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var out []SourceAddr
+	for _, ifc := range ifaces {
+		if ifc.Flags&net.FlagUp == 0 || ifc.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := ifc.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			ipn, ok := a.(*net.IPNet)
+			if !ok || ipn.IP.IsLinkLocalUnicast() || ipn.IP.IsLoopback() {
+				continue
+			}
+			out = append(out, SourceAddr{Iface: ifc.Name, IP: ipn.IP.String()})
+		}
+	}
+	return out, nil
+}
+
+// ErrSourceGone means the source address isn't assigned to any interface
+// that is up (VPN disconnected, cable unplugged, DHCP lease changed...).
+var ErrSourceGone = errors.New("netinfo: source address is not on any active interface")
+
+// CheckSource returns nil when ip is an address pings can be sent from
+// right now.
+func CheckSource(ip string) error {
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("netinfo: invalid source address %q", ip)
+	}
+	addrs, err := SourceAddrs()
+	if err != nil {
+		return err
+	}
+	for _, a := range addrs {
+		if net.ParseIP(a.IP).Equal(net.ParseIP(ip)) {
+			return nil
+		}
+	}
+	return ErrSourceGone
+}
@@ -0,0 +1,90 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package pubip
+
+// Public ("what's my IP") address lookup via an ipify-style HTTP endpoint.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint answers with the caller's address as plain text.
+const DefaultEndpoint = "https://api.ipify.org"
+
+// DefaultTimeout applies when ctx carries no deadline of its own.
+const DefaultTimeout = 5 * time.Second
+
+// Lookup asks endpoint for our public address. The endpoint may reply with
+// plain text ("203.0.113.7") or JSON carrying an "ip" field
+// (ipify ?format=json, ipinfo.io, ...).
+func Lookup(ctx context.Context, endpoint string) (ip string, err error) {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("pubip: %s returned %s", endpoint, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+	return parse(body)
+}
+
+func parse(body []byte) (string, error) {
+	s := strings.TrimSpace(string(body))
+	if strings.HasPrefix(s, "{") {
+		var v struct {
+			IP string `json:"ip"`
+		}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return "", err
+		}
+		s = strings.TrimSpace(v.IP)
+	}
+	if net.ParseIP(s) == nil {
+		return "", errors.New("pubip: response is not an IP address")
+	}
+	return s, nil
+}
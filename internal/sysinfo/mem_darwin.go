@@ -0,0 +1,18 @@
+//go:build darwin
+// +build darwin
+
+package sysinfo
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+func totalMemory() (uint64, error) {
+	out, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+}
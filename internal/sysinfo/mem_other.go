@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package sysinfo
+
+func totalMemory() (uint64, error) {
+	return 0, ErrUnsupported
+}
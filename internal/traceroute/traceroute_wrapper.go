@@ -26,6 +26,7 @@ import (
 	"errors"
 	"log"
 	"math"
+	"net"
 	"os/exec"
 	"regexp"
 	"runtime"
@@ -42,6 +43,7 @@ type Options struct {
 	Timeout     time.Duration // per-hop timeout
 	Probes      int           // per-hop probes (1 or 3)
 	DontResolve bool          // use -n / -d to avoid DNS
+	Family      int           // 4 or 6 forces IPv4/IPv6; 0 leaves it to the platform
 }
 
 type Hop struct {
@@ -58,51 +60,97 @@ type Event struct {
 	Err  error
 }
 
-func Run(ctx context.Context, opt Options) (<-chan Event, error) {
-	if opt.Target == "" {
-		return nil, errors.New("target required")
-	}
-	if opt.MaxHops <= 0 {
-		opt.MaxHops = 30
-	}
-	if opt.Timeout <= 0 {
-		opt.Timeout = time.Second
+// EffectiveTimeout is the per-hop timeout the platform traceroute will
+// actually use for d, after rounding to what its -w flag accepts.
+func EffectiveTimeout(d time.Duration) time.Duration {
+	return effectiveTimeout(runtime.GOOS, d)
+}
+
+func effectiveTimeout(goos string, d time.Duration) time.Duration {
+	if d <= 0 {
+		d = time.Second
 	}
-	if opt.Probes <= 0 {
-		opt.Probes = 1
+	switch goos {
+	case "windows":
+		// tracert -w takes whole milliseconds
+		return max(d.Round(time.Millisecond), time.Millisecond)
+	case "darwin":
+		// macOS -w takes whole seconds: round to nearest, at least 1
+		return max(d.Round(time.Second), time.Second)
+	default:
+		// Linux traceroute accepts fractional seconds; keep ms precision
+		return max(d.Round(time.Millisecond), time.Millisecond)
 	}
+}
 
-	var args []string
-	var bin string
-	switch runtime.GOOS {
+// buildArgs returns the traceroute binary and arguments for goos.
+// opt must already have its defaults applied.
+func buildArgs(goos string, opt Options) (bin string, args []string) {
+	timeout := effectiveTimeout(goos, opt.Timeout)
+	switch goos {
 	case "windows":
 		bin = "tracert"
+		if opt.Family == 4 || opt.Family == 6 {
+			args = append(args, "-"+strconv.Itoa(opt.Family))
+		}
 		args = append(args, "-h", strconv.Itoa(opt.MaxHops))
-		args = append(args, "-w", strconv.Itoa(int(opt.Timeout.Milliseconds())))
+		args = append(args, "-w", strconv.FormatInt(timeout.Milliseconds(), 10))
 		if opt.DontResolve {
 			args = append(args, "-d")
 		}
 		args = append(args, opt.Target)
 	case "darwin":
-		bin = "traceroute"
+		bin = "traceroute" // IPv4 only; IPv6 has its own tool
+		if opt.Family == 6 {
+			bin = "traceroute6"
+		}
 		if opt.DontResolve {
 			args = append(args, "-n")
 		}
 		// macOS: -w expects integer seconds
-		args = append(args, "-w", strconv.Itoa(int(math.Ceil(opt.Timeout.Seconds()))))
+		args = append(args, "-w", strconv.Itoa(int(timeout/time.Second)))
 		args = append(args, "-q", strconv.Itoa(opt.Probes))
 		args = append(args, "-m", strconv.Itoa(opt.MaxHops))
 		args = append(args, opt.Target)
 	default:
 		bin = "traceroute"
+		if opt.Family == 4 || opt.Family == 6 {
+			args = append(args, "-"+strconv.Itoa(opt.Family))
+		}
 		if opt.DontResolve {
 			args = append(args, "-n")
 		}
 		args = append(args, "-q", strconv.Itoa(opt.Probes))
-		args = append(args, "-w", strconv.FormatFloat(opt.Timeout.Seconds(), 'f', 1, 64))
+		args = append(args, "-w", strconv.FormatFloat(timeout.Seconds(), 'f', -1, 64))
 		args = append(args, "-m", strconv.Itoa(opt.MaxHops))
 		args = append(args, opt.Target)
 	}
+	return bin, args
+}
+
+// Command returns the program and arguments Run would execute for opt.
+func Command(opt Options) (bin string, args []string, err error) {
+	if opt.Target == "" {
+		return "", nil, errors.New("target required")
+	}
+	if opt.MaxHops <= 0 {
+		opt.MaxHops = 30
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = time.Second
+	}
+	if opt.Probes <= 0 {
+		opt.Probes = 1
+	}
+	bin, args = buildArgs(runtime.GOOS, opt)
+	return bin, args, nil
+}
+
+func Run(ctx context.Context, opt Options) (<-chan Event, error) {
+	bin, args, err := Command(opt)
+	if err != nil {
+		return nil, err
+	}
 
 	cmd := exec.CommandContext(ctx, bin, args...)
 	log.Printf("Executing traceroute: %s\n", cmd)
@@ -287,6 +335,29 @@ func Run(ctx context.Context, opt Options) (<-chan Event, error) {
 	return events, nil
 }
 
+// Reached reports whether hopAddr is the trace target: one of targetIPs (its
+// resolved addresses) or the target name itself, as Windows tracert prints
+// names unless told not to resolve.
+func Reached(hopAddr, target string, targetIPs []string) bool {
+	a := strings.Trim(hopAddr, "[]")
+	if a == "" || a == "*" {
+		return false
+	}
+	if strings.EqualFold(a, target) {
+		return true
+	}
+	ip := net.ParseIP(a)
+	if ip == nil {
+		return false
+	}
+	for _, t := range targetIPs {
+		if ip.Equal(net.ParseIP(t)) {
+			return true
+		}
+	}
+	return false
+}
+
 func firstNonEmpty(a, b string) string {
 	if strings.TrimSpace(a) != "" {
 		return a
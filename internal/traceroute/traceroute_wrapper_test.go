@@ -0,0 +1,89 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package traceroute_wrapper
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBuildArgs(t *testing.T) {
+	base := Options{Target: "example.com", MaxHops: 20, Timeout: 1500 * time.Millisecond, Probes: 1}
+	with := func(f func(o *Options)) Options {
+		o := base
+		f(&o)
+		return o
+	}
+	tests := []struct {
+		name string
+		goos string
+		opt  Options
+		bin  string
+		args []string
+	}{
+		{"linux", "linux", base, "traceroute",
+			[]string{"-q", "1", "-w", "1.5", "-m", "20", "example.com"}},
+		{"linux numeric IPv6", "linux", with(func(o *Options) { o.DontResolve, o.Family = true, 6 }), "traceroute",
+			[]string{"-6", "-n", "-q", "1", "-w", "1.5", "-m", "20", "example.com"}},
+		{"linux IPv4", "linux", with(func(o *Options) { o.Family = 4 }), "traceroute",
+			[]string{"-4", "-q", "1", "-w", "1.5", "-m", "20", "example.com"}},
+		{"windows", "windows", base, "tracert",
+			[]string{"-h", "20", "-w", "1500", "example.com"}},
+		{"windows numeric IPv4", "windows", with(func(o *Options) { o.DontResolve, o.Family = true, 4 }), "tracert",
+			[]string{"-4", "-h", "20", "-w", "1500", "-d", "example.com"}},
+		{"darwin rounds to seconds", "darwin", base, "traceroute",
+			[]string{"-w", "2", "-q", "1", "-m", "20", "example.com"}},
+		{"darwin IPv6", "darwin", with(func(o *Options) { o.DontResolve, o.Family = true, 6 }), "traceroute6",
+			[]string{"-n", "-w", "2", "-q", "1", "-m", "20", "example.com"}},
+		{"darwin IPv4 is the default tool", "darwin", with(func(o *Options) { o.Family = 4 }), "traceroute",
+			[]string{"-w", "2", "-q", "1", "-m", "20", "example.com"}},
+		{"freebsd like linux", "freebsd", with(func(o *Options) { o.Probes = 3 }), "traceroute",
+			[]string{"-q", "3", "-w", "1.5", "-m", "20", "example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin, args := buildArgs(tt.goos, tt.opt)
+			if bin != tt.bin || !slices.Equal(args, tt.args) {
+				t.Errorf("got %s %q, want %s %q", bin, args, tt.bin, tt.args)
+			}
+		})
+	}
+}
+
+func TestEffectiveTimeout(t *testing.T) {
+	tests := []struct {
+		goos string
+		in   time.Duration
+		want time.Duration
+	}{
+		{"linux", 0, time.Second},
+		{"linux", 1234567 * time.Microsecond, 1235 * time.Millisecond},
+		{"windows", 300 * time.Microsecond, time.Millisecond},
+		{"darwin", 400 * time.Millisecond, time.Second},
+		{"darwin", 2600 * time.Millisecond, 3 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := effectiveTimeout(tt.goos, tt.in); got != tt.want {
+			t.Errorf("effectiveTimeout(%s, %s) = %s, want %s", tt.goos, tt.in, got, tt.want)
+		}
+	}
+}
@@ -22,10 +22,12 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"runtime"
 	"strings"
 	"time"
 
+	"github.com/e1z0/speedping/internal/sysinfo"
 	"github.com/mappu/miqt/qt"
 )
 
@@ -46,10 +48,10 @@ func NewAboutPage(model *AppModel) *qt.QWidget {
 
 	// Title
 	title := qt.NewQLabel6(fmt.Sprintf("%s", AppName), nil, 0)
-	titleFont := title.Font()
-	titleFont.SetPointSize(titleFont.PointSize() + 10)
-	titleFont.SetBold(true)
-	title.SetFont(titleFont)
+	scaledFont(title.QWidget, func(f *qt.QFont) {
+		f.SetPointSize(f.PointSize() + 10)
+		f.SetBold(true)
+	})
 	title.SetAlignment(qt.AlignHCenter)
 
 	// Version/build line
@@ -82,13 +84,13 @@ func NewAboutPage(model *AppModel) *qt.QWidget {
 	// License short blurb
 	lic := qt.NewQTextEdit(nil)
 	lic.SetReadOnly(true)
-	lic.SetMinimumHeight(90)
+	whenScaled(func() { lic.SetMinimumHeight(scaledPx(90)) })
 	lic.SetPlainText(LicenseStr)
 
 	// System info
 	sysInfo := qt.NewQTextEdit(nil)
 	sysInfo.SetReadOnly(true)
-	sysInfo.SetMinimumHeight(110)
+	whenScaled(func() { sysInfo.SetMinimumHeight(scaledPx(110)) })
 	sysInfo.SetPlainText(makeSystemInfo())
 
 	// Buttons row
@@ -99,10 +101,16 @@ func NewAboutPage(model *AppModel) *qt.QWidget {
 	btnOpenLog.SetText("Open logs folder")
 	btnCopySys := qt.NewQPushButton(nil)
 	btnCopySys.SetText("Copy system info")
+	btnCopyStats := qt.NewQPushButton(nil)
+	btnCopyStats.SetText("Copy stats as JSON")
+	btnCopyStats.SetToolTip("Copy every host's current min/avg/max, jitter and loss as JSON, with the time and app version")
 	btnRow.AddStretch()
 	btnRow.AddWidget(btnOpenCfg.QWidget)
 	btnRow.AddWidget(btnOpenLog.QWidget)
 	btnRow.AddWidget(btnCopySys.QWidget)
+	btnRow.AddWidget(btnCopyStats.QWidget)
+	console, btnConsole := newLogConsole()
+	btnRow.AddWidget(btnConsole.QWidget)
 	btnRow.AddStretch()
 
 	// Add widgets
@@ -117,30 +125,79 @@ func NewAboutPage(model *AppModel) *qt.QWidget {
 	col.AddWidget(qt.NewQLabel6("System info", nil, 0).QWidget)
 	col.AddWidget(sysInfo.QWidget)
 	col.AddLayout(btnRow.QLayout)
+	col.AddWidget2(console, 1)
 	col.AddStretch()
 
 	// Actions
 	btnOpenCfg.OnClicked(func() {
-		openFileOrDir(configDir())
+		openFolder(page, configDir())
 	})
 	btnOpenLog.OnClicked(func() {
-		openFileOrDir(logsDir())
+		openFolder(page, logsDir())
 	})
 	btnCopySys.OnClicked(func() {
 		cb := qt.QGuiApplication_Clipboard()
 		cb.SetText2(makeSystemInfo(), qt.QClipboard__Clipboard)
 	})
+	btnCopyStats.OnClicked(func() {
+		b, err := model.ReportJSON(time.Now())
+		if err != nil {
+			log.Printf("Unable to encode stats: %s\n", err)
+			return
+		}
+		copyToClipboard(string(b))
+	})
 
 	return page
 }
 
+// openFolder opens dir, creating it first, and tells the user when that fails.
+func openFolder(parent *qt.QWidget, dir string) {
+	if err := openDir(dir); err != nil {
+		log.Printf("Unable to open %s: %s\n", dir, err)
+		qt.QMessageBox_Warning(parent, "Open folder", err.Error())
+	}
+}
+
 func makeSystemInfo() string {
 	now := time.Now().Format(time.RFC3339)
 	return fmt.Sprintf(
-		"App:      %s v%s (build: %s) %s\nGo:        %s\nOS/Arch:   %s/%s\nCPU:       %d\nTime:      %s\nBinary:       %s\nConfig:    %s\nLogs:      %s\n",
+		"App:       %s v%s (build: %s) %s\nGo:        %s\nQt:        %s\nOS/Arch:   %s/%s\nCPU:       %d\nMemory:    %s\nGraphics:  %s\nScreen:    %s\nTime:      %s\nBinary:    %s\nConfig:    %s\nLogs:      %s\n",
 		AppName, AppVersion, build, BuildDate,
-		runtime.Version(), runtime.GOOS, runtime.GOARCH,
-		runtime.NumCPU(), now,
+		runtime.Version(), qt.QLibraryInfo_Version().ToString(),
+		runtime.GOOS, runtime.GOARCH,
+		runtime.NumCPU(), memoryInfo(), graphicsInfo(), screenInfo(), now,
 		exePath(), configDir(), logsDir(),
 	)
 }
+
+func memoryInfo() string {
+	b, err := sysinfo.TotalMemory()
+	if err != nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%.1f GiB", float64(b)/(1<<30))
+}
+
+// graphicsInfo names the Qt platform plugin and the requested OpenGL flavour.
+func graphicsInfo() string {
+	gl := "default"
+	switch {
+	case qt.QCoreApplication_TestAttribute(qt.AA_UseSoftwareOpenGL):
+		gl = "software"
+	case qt.QCoreApplication_TestAttribute(qt.AA_UseOpenGLES):
+		gl = "gles"
+	case qt.QCoreApplication_TestAttribute(qt.AA_UseDesktopOpenGL):
+		gl = "desktop"
+	}
+	return fmt.Sprintf("platform=%s opengl=%s", qt.QGuiApplication_PlatformName(), gl)
+}
+
+// screenInfo describes the primary screen scaling (helps with HiDPI reports).
+func screenInfo() string {
+	scr := qt.QGuiApplication_PrimaryScreen()
+	if scr == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s dpr=%.2f dpi=%.0f", scr.Name(), scr.DevicePixelRatio(), scr.LogicalDotsPerInch())
+}
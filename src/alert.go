@@ -0,0 +1,225 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/e1z0/speedping/internal/monitor"
+)
+
+// Alerts: when a host's loss or average RTT over the last alert.window_sec
+// crosses its threshold, POST to alert.webhook_url and/or run alert.command.
+// A recovery notification follows once the host is back under both limits.
+// Actions run in their own goroutines so neither the UI nor pinging waits.
+
+const alertMinSamples = 3 // don't judge a host on fewer samples than this
+
+type AlertEvent struct {
+	State   string    `json:"state"` // "firing" or "resolved"
+	Host    string    `json:"host"`
+	Addr    string    `json:"addr"`
+	LossPct float64   `json:"loss_pct"`
+	RTTms   float64   `json:"rtt_ms"`
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+type alertState struct {
+	firing   bool
+	lastFire time.Time
+}
+
+type Alerter struct {
+	states  map[*Host]*alertState
+	tmpl    *template.Template // nil: default JSON payload
+	tmplSrc string             // alert.webhook_template tmpl was parsed from
+	buf     []Sample
+}
+
+func NewAlerter() *Alerter {
+	return &Alerter{states: make(map[*Host]*alertState)}
+}
+
+// alertsEnabled reports whether c has a threshold and somewhere to send to.
+func alertsEnabled(c AlertConfig) bool {
+	return (c.LossPct > 0 || c.RTTms > 0) && (c.WebhookURL != "" || c.Command != "")
+}
+
+// Evaluate checks every host against c and dispatches what changed.
+// Call it periodically from the UI thread.
+func (a *Alerter) Evaluate(m *AppModel, c AlertConfig, now time.Time) {
+	if !alertsEnabled(c) {
+		return
+	}
+	if c.WebhookTemplate != a.tmplSrc {
+		a.tmplSrc, a.tmpl = c.WebhookTemplate, nil
+		if c.WebhookTemplate != "" {
+			t, err := template.New("alert").Parse(c.WebhookTemplate)
+			if err != nil {
+				log.Printf("Alert webhook template invalid, using JSON: %s\n", err)
+			} else {
+				a.tmpl = t
+			}
+		}
+	}
+	window := time.Duration(c.WindowSec) * time.Second
+	if window <= 0 {
+		window = time.Minute
+	}
+	cooldown := time.Duration(c.CooldownSec) * time.Second
+
+	hosts := m.Hosts()
+	for _, h := range hosts {
+		st := a.states[h]
+		if st == nil {
+			st = &alertState{}
+			a.states[h] = st
+		}
+		a.buf = h.buf.Snapshot(a.buf)
+		r := monitor.ComputeReport(h.Name, h.Addr, samplesSince(a.buf, now.Add(-window)))
+		if r.Samples < alertMinSamples {
+			continue
+		}
+		bad := (c.LossPct > 0 && r.LossPct >= c.LossPct) || (c.RTTms > 0 && r.Avg >= c.RTTms)
+		switch {
+		case bad && !st.firing && now.Sub(st.lastFire) >= cooldown:
+			st.firing, st.lastFire = true, now
+			a.dispatch(c, alertEvent("firing", r, now))
+		case !bad && st.firing:
+			st.firing = false
+			a.dispatch(c, alertEvent("resolved", r, now))
+		}
+	}
+	// forget removed hosts (by identity: two hosts may share an address)
+	for h := range a.states {
+		if !slices.Contains(hosts, h) {
+			delete(a.states, h)
+		}
+	}
+}
+
+// samplesSince returns the tail of a time-ordered slice newer than t.
+func samplesSince(samples []Sample, t time.Time) []Sample {
+	for i, s := range samples {
+		if s.T.After(t) {
+			return samples[i:]
+		}
+	}
+	return nil
+}
+
+func alertEvent(state string, r HostReport, now time.Time) AlertEvent {
+	msg := fmt.Sprintf("%s (%s) %s: %.1f%% loss, %.1f ms avg", r.Name, r.Addr, state, r.LossPct, r.Avg)
+	return AlertEvent{State: state, Host: r.Name, Addr: r.Addr, LossPct: r.LossPct, RTTms: r.Avg, Time: now, Message: msg}
+}
+
+func (a *Alerter) dispatch(c AlertConfig, ev AlertEvent) {
+	log.Printf("Alert: %s\n", ev.Message)
+	if c.WebhookURL != "" {
+		body, ctype, err := a.payload(ev)
+		if err != nil {
+			log.Printf("Alert webhook payload: %s\n", err)
+		} else {
+			go postWebhook(c.WebhookURL, ctype, body)
+		}
+	}
+	if strings.TrimSpace(c.Command) != "" {
+		go runAlertCommand(c.Command, ev)
+	}
+}
+
+func (a *Alerter) payload(ev AlertEvent) ([]byte, string, error) {
+	if a.tmpl == nil {
+		b, err := json.Marshal(ev)
+		return b, "application/json", err
+	}
+	var buf bytes.Buffer
+	if err := a.tmpl.Execute(&buf, ev); err != nil {
+		return nil, "", err
+	}
+	ctype := "text/plain; charset=utf-8"
+	if json.Valid(buf.Bytes()) {
+		ctype = "application/json"
+	}
+	return buf.Bytes(), ctype, nil
+}
+
+func postWebhook(url, ctype string, body []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Alert webhook: %s\n", err)
+		return
+	}
+	req.Header.Set("Content-Type", ctype)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Printf("Alert webhook: %s\n", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("Alert webhook: %s returned %s\n", url, resp.Status)
+	}
+}
+
+// runAlertCommand runs command (split on spaces) with state, host and addr
+// appended as arguments and the full event in SPEEDPING_* variables.
+// A blank command runs nothing.
+func runAlertCommand(command string, ev AlertEvent) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return
+	}
+	args = append(args, ev.State, ev.Host, ev.Addr)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"SPEEDPING_STATE="+ev.State,
+		"SPEEDPING_HOST="+ev.Host,
+		"SPEEDPING_ADDR="+ev.Addr,
+		fmt.Sprintf("SPEEDPING_LOSS_PCT=%.1f", ev.LossPct),
+		fmt.Sprintf("SPEEDPING_RTT_MS=%.1f", ev.RTTms),
+		"SPEEDPING_MESSAGE="+ev.Message,
+	)
+	// don't hang on anything it leaves running with our output pipe
+	cmd.WaitDelay = 2 * time.Second
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Printf("Alert command %q failed: %s: %s\n", command, err, strings.TrimSpace(string(out)))
+		return
+	}
+	log.Printf("Alert command %q exited 0\n", command)
+}
@@ -0,0 +1,84 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Opt-in local JSON API (api.enabled) for scripts and dashboards:
+//
+//	GET /api/hosts                 current []HostReport
+//	GET /api/host/{addr}/samples   the host's ring, oldest first
+//
+// Handlers only read through AppModel's locked accessors and ring snapshots,
+// so they are safe to run next to the ping goroutines.
+
+type APIServer struct {
+	model *AppModel
+	srv   *http.Server
+}
+
+// StartAPIServer listens on addr until Close.
+func StartAPIServer(model *AppModel, addr string) (*APIServer, error) {
+	a := &APIServer{model: model}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/hosts", a.hosts)
+	mux.HandleFunc("GET /api/host/{addr}/samples", a.samples)
+	srv, err := serveHTTP(addr, mux, "JSON API")
+	if err != nil {
+		return nil, err
+	}
+	a.srv = srv
+	return a, nil
+}
+
+func (a *APIServer) Close() {
+	if a == nil {
+		return
+	}
+	shutdownHTTP(a.srv)
+}
+
+func (a *APIServer) hosts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, a.model.Report())
+}
+
+func (a *APIServer) samples(w http.ResponseWriter, r *http.Request) {
+	h := a.model.FindHost(r.PathValue("addr"))
+	if h == nil {
+		http.Error(w, "unknown host", http.StatusNotFound)
+		return
+	}
+	samples := h.buf.Snapshot(nil)
+	if samples == nil {
+		samples = []Sample{} // "[]" rather than "null"
+	}
+	writeJSON(w, samples)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
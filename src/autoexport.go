@@ -0,0 +1,158 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// Scheduled export (auto_export): every interval_min minutes the ping graph
+// is saved as PNG and every host's samples as CSV, both named after the
+// time of the export. The first failure (disk full, no permission...) is
+// logged and stops the schedule until the next start, instead of repeating
+// the same error every interval.
+
+// exportW×exportH is used when the graph has no usable size of its own
+// (e.g. it was never shown because the app started in another tab).
+const exportW, exportH = 1200, 500
+
+// startAutoExport arms the export timer from c; a no-op unless enabled.
+func (ui *UI) startAutoExport(c AutoExportConfig) {
+	if !c.Enabled {
+		return
+	}
+	dir := c.Dir
+	if dir == "" {
+		dir = exportsDir()
+	}
+	every := time.Duration(max(c.IntervalMin, 1)) * time.Minute
+	ui.exportTimer = qt.NewQTimer()
+	ui.exportTimer.OnTimeout(func() {
+		if err := ui.exportNow(dir, time.Now()); err != nil {
+			ui.exportTimer.Stop()
+			log.Printf("Auto export to %s failed, disabled until restart: %s\n", dir, err)
+			ui.main.StatusBar().ShowMessage2("Auto export disabled: "+err.Error(), 30000)
+		}
+	})
+	ui.exportTimer.Start(int(every.Milliseconds()))
+	log.Printf("Exporting graph and samples to %s every %s\n", dir, every)
+}
+
+// stopAutoExport disarms the export timer, if any.
+func (ui *UI) stopAutoExport() {
+	if ui.exportTimer != nil {
+		ui.exportTimer.Stop()
+		ui.exportTimer = nil
+	}
+}
+
+// exportNow writes speedping-<time>.png and .csv into dir.
+func (ui *UI) exportNow(dir string, now time.Time) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	base := filepath.Join(dir, "speedping-"+now.Format("20060102-150405"))
+
+	w, h := ui.graph.Width(), ui.graph.Height()
+	if w < 200 || h < 100 {
+		w, h = exportW, exportH
+	}
+	img := ui.graph.RenderImage(w, h, 1)
+	if img == nil || !img.Save(base+".png") {
+		return errors.New("unable to write " + base + ".png")
+	}
+	return writeSamplesCSV(base+".csv", ui.model.Hosts())
+}
+
+// exportSelectedCSV asks for a file and saves the samples of the hosts
+// selected in the list to it.
+func (ui *UI) exportSelectedCSV() {
+	var hosts []*Host
+	for _, it := range ui.hostList.SelectedItems() {
+		if h := ui.model.HostAt(ui.hostList.Row(it)); h != nil {
+			hosts = append(hosts, h)
+		}
+	}
+	if len(hosts) == 0 {
+		return
+	}
+	path := ui.saveCSVAs("Export selected hosts", "speedping", func(path string) error {
+		return writeSamplesCSV(path, hosts)
+	})
+	if path != "" {
+		ui.main.StatusBar().ShowMessage2(fmt.Sprintf("Exported %d hosts to %s", len(hosts), path), 10000)
+	}
+}
+
+// saveCSVAs asks where to save a CSV, offering prefix-<time>.csv in
+// exportsDir(), and has write create it. It returns the path written, or
+// "" when cancelled or failed; failures are logged and shown under title.
+func (ui *UI) saveCSVAs(title, prefix string, write func(path string) error) string {
+	name := filepath.Join(exportsDir(), prefix+"-"+time.Now().Format("20060102-150405")+".csv")
+	path := qt.QFileDialog_GetSaveFileName4(ui.main.QWidget, title, name, "CSV (*.csv)")
+	if path == "" {
+		return ""
+	}
+	if filepath.Ext(path) == "" {
+		path += ".csv"
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err == nil {
+		err = write(path)
+	}
+	if err != nil {
+		log.Printf("Unable to export %s: %s\n", path, err)
+		qt.QMessageBox_Warning(ui.main.QWidget, title, err.Error())
+		return ""
+	}
+	return path
+}
+
+// writeSamplesCSV saves every sample in the hosts' rings, host by host,
+// in the sample log's format.
+func writeSamplesCSV(path string, hosts []*Host) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	_, _ = w.WriteString(sampleCSVHeader)
+	var buf []Sample
+	for _, h := range hosts {
+		buf = h.buf.Snapshot(buf)
+		for _, s := range buf {
+			_, _ = w.WriteString(sampleCSVLine(h.ident(), s))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,114 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/e1z0/speedping/internal/monitor"
+	"github.com/mappu/miqt/qt"
+	"github.com/mappu/miqt/qt/mainthread"
+)
+
+// Burst test: a bounded `ping -f` — N echo requests to one host back to
+// back, reported as a single min/avg/max/loss line. For a momentary look at
+// link quality, not for monitoring; the count is capped at monitor.BurstMax
+// and the dialog spells out what is about to be sent.
+
+const burstDefault = 100
+
+// burstTest asks which host and how many packets, then runs the burst in
+// the background and shows the result.
+func (ui *UI) burstTest() {
+	hosts := ui.model.Hosts()
+	if len(hosts) == 0 {
+		return
+	}
+	dlg := qt.NewQDialog(ui.main.QWidget)
+	dlg.SetWindowTitle("Burst test")
+	form := qt.NewQFormLayout(nil)
+	dlg.SetLayout(form.QLayout)
+
+	hostCombo := qt.NewQComboBox(nil)
+	for _, h := range hosts {
+		hostCombo.AddItem(h.Name + " (" + h.Addr + ")")
+	}
+	if row := ui.hostList.CurrentRow(); row >= 0 && row < len(hosts) {
+		hostCombo.SetCurrentIndex(row)
+	}
+	count := qt.NewQSpinBox(nil)
+	count.SetRange(1, monitor.BurstMax)
+	count.SetValue(burstDefault)
+	count.SetSuffix(" packets")
+	warn := qt.NewQLabel3(fmt.Sprintf("Sends the packets back to back, as fast as possible (at most %d).\n"+
+		"Only burst hosts you own or are allowed to load.", monitor.BurstMax))
+	warn.SetWordWrap(true)
+
+	form.AddRow3("Host:", hostCombo.QWidget)
+	form.AddRow3("Count:", count.QWidget)
+	form.AddRow(nil, warn.QWidget)
+
+	buttons := qt.NewQDialogButtonBox4(qt.QDialogButtonBox__Cancel)
+	buttons.AddButton2("Send burst", qt.QDialogButtonBox__AcceptRole)
+	buttons.OnAccepted(func() { dlg.Accept() })
+	buttons.OnRejected(func() { dlg.Reject() })
+	form.AddRow(nil, buttons.QWidget)
+
+	if dlg.Exec() != int(qt.QDialog__Accepted) {
+		return
+	}
+	h := hosts[max(hostCombo.CurrentIndex(), 0)]
+	n := count.Value()
+
+	ui.btnBurst.SetEnabled(false)
+	ui.main.StatusBar().ShowMessage(fmt.Sprintf("Sending %d pings to %s…", n, h.Name))
+	pb := ProbingBackend{MaxRTT: time.Second, Size: h.PacketSize, Source: ui.pingSource()}
+	go func() {
+		r, err := pb.Burst(context.Background(), h.Addr, n)
+		mainthread.Wait(func() {
+			ui.btnBurst.SetEnabled(true)
+			if err != nil {
+				log.Printf("Burst to %s failed: %s\n", h.Addr, err)
+				ui.main.StatusBar().ShowMessage2("Burst failed: "+err.Error(), 30000)
+				qt.QMessageBox_Warning(ui.main.QWidget, "Burst test", err.Error())
+				return
+			}
+			msg := burstText(h.Name, r)
+			log.Printf("Burst: %s\n", msg)
+			ui.main.StatusBar().ShowMessage2("Burst: "+msg, 30000)
+			qt.QMessageBox_Information(ui.main.QWidget, "Burst test", msg)
+		})
+	}()
+}
+
+// burstText is a one-line summary, e.g. "router: 100 sent, 99 received,
+// 1.0% loss, min/avg/max/stddev 0.4/0.6/2.1/0.2 ms in 0.4 s".
+func burstText(name string, r monitor.BurstResult) string {
+	ms := func(d time.Duration) string { return formatFloat(float64(d.Microseconds())/1000, 1) }
+	s := fmt.Sprintf("%s: %d sent, %d received, %s%% loss", name, r.Sent, r.Recv, formatFloat(r.LossPct, 1))
+	if r.Recv > 0 {
+		s += ", min/avg/max/stddev " + ms(r.Min) + "/" + ms(r.Avg) + "/" + ms(r.Max) + "/" + ms(r.StdDev) + " ms"
+	}
+	return s + " in " + formatFloat(r.Took.Seconds(), 1) + " s"
+}
@@ -0,0 +1,167 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/e1z0/speedping/internal/hostlist"
+	"github.com/e1z0/speedping/internal/monitor"
+	"github.com/mappu/miqt/qt"
+	"github.com/mappu/miqt/qt/mainthread"
+)
+
+// A guided check (the connection wizard, the endpoint check) pings a few
+// hosts of its own for a fixed time in a dialog, then sums the result up in
+// a plain-language verdict. Its hosts are temporary: they are not added to
+// the host list or saved, and the user's own pinging carries on untouched.
+
+// guidedCheck describes one check.
+type guidedCheck struct {
+	Title    string
+	Running  string // shown while the check runs
+	Hosts    []hostlist.Entry
+	Duration time.Duration
+	// Side, when set, runs alongside the pings (a lookup, a trace) until
+	// it returns or the dialog closes; the verdict waits for it. The func
+	// it returns, if any, is called on the UI thread with its results.
+	Side func(ctx context.Context) func()
+	// Verdict sums up the reports, one per host in Hosts order.
+	Verdict func(reports []monitor.HostReport) (string, diagLevel)
+	// Details lists the numbers behind the verdict.
+	Details func(reports []monitor.HostReport) string
+}
+
+// runGuidedCheck opens c's dialog and runs it; closing the dialog stops it.
+func (ui *UI) runGuidedCheck(c guidedCheck) {
+	dlg := qt.NewQDialog(ui.main.QWidget)
+	dlg.SetWindowTitle(c.Title)
+	dlg.SetAttribute(qt.WA_DeleteOnClose)
+	col := qt.NewQVBoxLayout(nil)
+	dlg.SetLayout(col.QLayout)
+	step := qt.NewQLabel6(c.Running, nil, 0)
+	step.SetWordWrap(true)
+	bar := qt.NewQProgressBar(nil)
+	bar.SetRange(0, int(c.Duration.Seconds()))
+	verdict := qt.NewQLabel6("", nil, 0)
+	verdict.SetWordWrap(true)
+	verdict.Hide()
+	details := qt.NewQLabel6("", nil, 0)
+	details.SetTextInteractionFlags(qt.TextSelectableByMouse)
+	buttons := qt.NewQDialogButtonBox4(qt.QDialogButtonBox__Close)
+	buttons.OnRejected(func() { dlg.Close() })
+	col.AddWidget(step.QWidget)
+	col.AddWidget(bar.QWidget)
+	col.AddWidget(verdict.QWidget)
+	col.AddWidget(details.QWidget)
+	col.AddWidget(buttons.QWidget)
+	dlg.SetMinimumWidth(int(480 * dpiScale(ui.graph.QPaintDevice)))
+	dlg.Show()
+
+	model := NewAppModel()
+	for _, e := range c.Hosts {
+		model.AddHost(e.Name, e.Addr, DefaultRingCap)
+	}
+	interval := time.Duration(ui.intSlider.Value()) * time.Millisecond
+	pb := ProbingBackend{
+		Interval:  interval,
+		MaxRTT:    pingMaxRTT(interval),
+		GraceLate: 100 * time.Millisecond,
+		Source:    ui.pingSource(),
+	}
+	start := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := ui.runs.Start(cancel)
+	var wg sync.WaitGroup
+	for _, h := range model.Hosts() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pb.Run(ctx, h.Addr, h.buf, nil); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("%s: pinging %s (%s) failed: %s\n", c.Title, h.Name, h.Addr, err)
+			}
+		}()
+	}
+	sideDone := c.Side == nil
+	if c.Side != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			apply := c.Side(ctx)
+			mainthread.Wait(func() {
+				if apply != nil {
+					apply()
+				}
+				sideDone = true
+			})
+		}()
+	}
+	go func() {
+		wg.Wait()
+		exited()
+	}()
+
+	timer := qt.NewQTimer()
+	timer.OnTimeout(func() {
+		took := time.Since(start)
+		bar.SetValue(min(int(took.Seconds()), bar.Maximum()))
+		if took < c.Duration || !sideDone {
+			return
+		}
+		timer.Stop()
+		cancel()
+		var reports []monitor.HostReport
+		for _, h := range model.Hosts() {
+			reports = append(reports, monitor.ComputeReport(h.Name, h.Addr, samplesSince(h.buf.Snapshot(nil), start)))
+		}
+		step.SetText("Done.")
+		bar.Hide()
+		text, lvl := c.Verdict(reports)
+		verdict.SetStyleSheet(diagCSS(lvl))
+		verdict.SetText(text)
+		verdict.Show()
+		details.SetText(c.Details(reports))
+	})
+	timer.Start(500)
+	dlg.OnFinished(func(int) {
+		timer.Stop()
+		cancel()
+	})
+}
+
+// reportLines words each report as "name (addr): N ms average, N% loss".
+func reportLines(reports []monitor.HostReport) []string {
+	var lines []string
+	for _, r := range reports {
+		if r.Samples == 0 || r.LossPct >= 100 {
+			lines = append(lines, fmt.Sprintf("%s (%s): no replies", r.Name, r.Addr))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s (%s): %s ms average, %s%% loss", r.Name, r.Addr,
+			formatFloat(r.Avg, 1), formatFloat(r.LossPct, 0)))
+	}
+	return lines
+}
@@ -30,6 +30,9 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/e1z0/speedping/internal/iperf"
+	"github.com/e1z0/speedping/internal/netinfo"
+	"github.com/e1z0/speedping/internal/pubip"
 	"gopkg.in/yaml.v3"
 )
 
@@ -50,11 +53,50 @@ type HostConfig struct {
 	Name    string `yaml:"name"`
 	Addr    string `yaml:"addr"`
 	Enabled bool   `yaml:"enabled"`
+	Note    string `yaml:"note,omitempty"` // free-form reminder, not used for pinging
+
+	// latency thresholds overriding ping.warn_ms/bad_ms (0 = use global)
+	WarnMs float64 `yaml:"warn_ms,omitempty"`
+	BadMs  float64 `yaml:"bad_ms,omitempty"`
+
+	// probing overrides for this host (0 = ping.interval_ms / 56 bytes)
+	IntervalMs int `yaml:"interval_ms,omitempty"`
+	PacketSize int `yaml:"packet_size,omitempty"` // ICMP payload bytes
 }
 
 type PingConfig struct {
-	IntervalMs int          `yaml:"interval_ms"`
-	Hosts      []HostConfig `yaml:"hosts"`
+	IntervalMs      int          `yaml:"interval_ms"`
+	Hosts           []HostConfig `yaml:"hosts"`
+	LogSamples      bool         `yaml:"log_samples"`      // append every sample to logs/samples.csv
+	WarnMs          float64      `yaml:"warn_ms"`          // line turns amber above this RTT (0 = off)
+	BadMs           float64      `yaml:"bad_ms"`           // line turns red above this RTT (0 = off)
+	AdaptiveTimeout bool         `yaml:"adaptive_timeout"` // per-host loss timeout from observed RTT
+	Source          string       `yaml:"source"`           // local address to ping from ("" = automatic)
+	StopMinutes     int          `yaml:"stop_minutes"`     // end a started session after this long (0 = off)
+	StopSamples     int          `yaml:"stop_samples"`     // ... after this many samples per host (0 = off)
+	StopLossStreak  int          `yaml:"stop_loss_streak"` // ... when a host loses this many in a row (0 = off)
+	SummaryOnStop   bool         `yaml:"summary_on_stop"`  // results dialog when a started session ends
+	AutoStart       bool         `yaml:"auto_start"`       // start pinging on launch (when there are hosts)
+	ReresolveMin    int          `yaml:"reresolve_min"`    // look host names up again this often (0 = off)
+	SeqGaps         bool         `yaml:"seq_gaps"`         // experimental: count skipped sequence numbers as lost
+	ReverseDNS      bool         `yaml:"reverse_dns"`      // show the PTR name of pinged addresses
+	CustomProbes    bool         `yaml:"custom_probes"`    // run "cmd:" host addresses as probe commands
+	RingCapacity    int          `yaml:"ring_capacity"`    // samples kept per host (0 = 600)
+}
+
+// GraphConfig holds the ping graph's view toggles. They used to live under
+// ping:, see migrateGraphConfig.
+type GraphConfig struct {
+	ShowLoss      bool `yaml:"show_loss"`      // loss % badges on the graph
+	ShowWorst     bool `yaml:"show_worst"`     // mark the slowest reply and longest loss run
+	FlapThreshold int  `yaml:"flap_threshold"` // up/down transitions in the window that mark a host flapping (0 = off)
+	LossStrip     bool `yaml:"loss_strip"`     // loss strip under the graph instead of top ticks
+	LossStripPx   int  `yaml:"loss_strip_px"`  // strip height per host
+	Heatmap       bool `yaml:"heatmap"`        // latency heatmap strip under the graph
+	// ConnectLoss draws lost probes as points at LossMs (0 = the time axis)
+	// instead of breaking the line
+	ConnectLoss bool    `yaml:"connect_loss"`
+	LossMs      float64 `yaml:"loss_ms"`
 }
 
 type SpeedConfig struct {
@@ -64,6 +106,62 @@ type SpeedConfig struct {
 	IntervalSec int    `yaml:"interval_sec"`
 	Parallel    int    `yaml:"parallel"`
 	Reverse     bool   `yaml:"reverse"`
+	ShowAverage bool   `yaml:"show_average"` // dashed end-of-test average in the graph
+	Smooth      bool   `yaml:"smooth"`       // spline instead of straight segments
+	BigReadout  bool   `yaml:"big_readout"`  // large current/peak numbers above the graph
+	Unit        string `yaml:"unit"`         // graph and readout unit: Kbps, Mbps, Gbps or MB/s
+	TCPInfo     bool   `yaml:"tcp_info"`     // run with --json-stream for TCP RTT and cwnd
+	Bind        string `yaml:"bind"`         // -B local address to test from ("" = automatic)
+
+	// Download offers to fetch iperf3 when none is found, keyed by
+	// "goos/goarch" (e.g. "linux/amd64"). Empty = never download.
+	Download map[string]iperf.Source `yaml:"download,omitempty"`
+}
+
+type AlertConfig struct {
+	LossPct         float64 `yaml:"loss_pct"`         // fire at or above this loss % (0 = off)
+	RTTms           float64 `yaml:"rtt_ms"`           // fire at or above this average RTT (0 = off)
+	WindowSec       int     `yaml:"window_sec"`       // samples judged, newest first
+	CooldownSec     int     `yaml:"cooldown_sec"`     // minimum time between two firings of a host
+	WebhookURL      string  `yaml:"webhook_url"`      // POST target
+	WebhookTemplate string  `yaml:"webhook_template"` // text/template over AlertEvent; empty = JSON
+	Command         string  `yaml:"command"`          // run with: <state> <host> <addr> + SPEEDPING_* env
+}
+
+// AutoExportConfig saves a PNG of the ping graph and a CSV of the samples
+// on a schedule, for long unattended runs.
+type AutoExportConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	IntervalMin int    `yaml:"interval_min"` // minutes between exports
+	Dir         string `yaml:"dir"`          // empty: ~/.config/<app>/exports
+}
+
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"` // serve Prometheus /metrics
+	Listen  string `yaml:"listen"`  // host:port to bind
+}
+
+type APIConfig struct {
+	Enabled bool   `yaml:"enabled"` // serve the JSON API
+	Listen  string `yaml:"listen"`  // host:port to bind (localhost by default)
+}
+
+type DisplayConfig struct {
+	FrameRate int  `yaml:"frame_rate"` // graph repaint rate (fps)
+	CPUSaver  bool `yaml:"cpu_saver"`  // drop to saverFrameRate while the window isn't focused
+	// DecimalComma shows numbers as "12,5" instead of "12.5"
+	DecimalComma bool `yaml:"decimal_comma"`
+	// Palette is "default" or "colorblind" (Okabe-Ito series colors)
+	Palette string `yaml:"palette"`
+	// HighContrast thickens graph lines and strengthens grid lines
+	HighContrast bool `yaml:"high_contrast"`
+	// Decimals fixes the decimals of RTTs and rates in graph labels and
+	// tooltips (0-2); -1 keeps the defaults (whole ms, the rate unit's own)
+	Decimals int `yaml:"decimals"`
+	// TipMillis shows tooltip times with milliseconds
+	TipMillis bool `yaml:"tip_millis"`
+	// UIScale is the application font size in percent of the default
+	UIScale int `yaml:"ui_scale"`
 }
 
 type WindowConfig struct {
@@ -71,29 +169,70 @@ type WindowConfig struct {
 	Y int `yaml:"y"`
 	W int `yaml:"w"`
 	H int `yaml:"h"`
+
+	Mini     bool `yaml:"mini,omitempty"`     // main window only: reopen in mini mode
+	Detached bool `yaml:"detached,omitempty"` // graph window only: reopen with the graph detached
 }
 
 type TracerouteConfig struct {
-	Target       string  `yaml:"target"`
-	MaxHops      int     `yaml:"max_hops"`
-	TimeoutSec   float64 `yaml:"timeout_sec"`   // per-probe timeout (s)
-	Probes       int     `yaml:"probes"`        // probes per hop
-	DontResolve  bool    `yaml:"dont_resolve"`  // -n behavior
-	PulseSeconds float64 `yaml:"pulse_seconds"` // seconds per pulse loop in the map
+	Target       string    `yaml:"target"`
+	MaxHops      int       `yaml:"max_hops"`
+	TimeoutSec   float64   `yaml:"timeout_sec"`   // per-probe timeout (s)
+	Probes       int       `yaml:"probes"`        // probes per hop
+	DontResolve  bool      `yaml:"dont_resolve"`  // -n behavior
+	Family       int       `yaml:"family"`        // 4 or 6 for IPv4/IPv6 only, 0 = the target's first address
+	PulseSeconds float64   `yaml:"pulse_seconds"` // seconds per pulse loop in the map
+	Delta        bool      `yaml:"delta"`         // map shows per-hop added delay
+	Repeat       bool      `yaml:"repeat"`        // trace continuously, with per-hop history
+	Colors       MapColors `yaml:"colors"`
+}
+
+// MapColors themes the traceroute map. Preset is "neon" (default) or
+// "colorblind"; any color given as "#rrggbb" overrides the preset's.
+type MapColors struct {
+	Preset      string `yaml:"preset"`
+	Path        string `yaml:"path,omitempty"`
+	NodeOK      string `yaml:"node_ok,omitempty"`
+	NodeTimeout string `yaml:"node_timeout,omitempty"`
+	Destination string `yaml:"destination,omitempty"`
+	Comet       string `yaml:"comet,omitempty"`
+}
+
+type NetworkConfig struct {
+	PublicIPURL string `yaml:"public_ip_url"` // ipify-style endpoint (plain text or JSON {"ip":...})
 }
 
 type AppConfig struct {
-	Ping   PingConfig       `yaml:"ping"`
-	Speed  SpeedConfig      `yaml:"speed"`
-	Trace  TracerouteConfig `yaml:"traceroute"`
-	Window WindowConfig     `yaml:"window"`
+	Ping    PingConfig       `yaml:"ping"`
+	Graph   GraphConfig      `yaml:"graph"`
+	Speed   SpeedConfig      `yaml:"speed"`
+	Trace   TracerouteConfig `yaml:"traceroute"`
+	Net     NetworkConfig    `yaml:"network"`
+	View    DisplayConfig    `yaml:"display"`
+	Metrics MetricsConfig    `yaml:"metrics"`
+	API     APIConfig        `yaml:"api"`
+	Alert   AlertConfig      `yaml:"alert"`
+	Export  AutoExportConfig `yaml:"auto_export"`
+	Window  WindowConfig     `yaml:"window"`
+	// MiniWindow is where the graph-only mini mode window was last placed
+	MiniWindow WindowConfig `yaml:"mini_window"`
+	// GraphWindow is where the detached ping graph's window was last placed
+	GraphWindow WindowConfig `yaml:"graph_window"`
+
+	firstRun bool // settings file didn't exist yet (never persisted)
 }
 
 func defaultConfig() *AppConfig {
 	return &AppConfig{
 		Ping: PingConfig{
-			IntervalMs: 1000,
-			Hosts:      nil,
+			IntervalMs:    1000,
+			Hosts:         nil,
+			SummaryOnStop: true,
+		},
+		Graph: GraphConfig{
+			ShowLoss:      true,
+			FlapThreshold: 6,
+			LossStripPx:   6,
 		},
 		Speed: SpeedConfig{
 			Server:      "",
@@ -101,6 +240,7 @@ func defaultConfig() *AppConfig {
 			DurationSec: 10,
 			IntervalSec: 1,
 			Parallel:    1,
+			Unit:        "Mbps",
 			Reverse:     false,
 		},
 		Trace: TracerouteConfig{ // <— NEW defaults
@@ -111,6 +251,27 @@ func defaultConfig() *AppConfig {
 			DontResolve:  false,
 			PulseSeconds: 6.0, // slow, pleasant pulse
 		},
+		Net: NetworkConfig{
+			PublicIPURL: pubip.DefaultEndpoint,
+		},
+		View: DisplayConfig{
+			FrameRate: 30,
+			Decimals:  -1,
+			UIScale:   100,
+		},
+		Metrics: MetricsConfig{
+			Listen: "127.0.0.1:9105",
+		},
+		API: APIConfig{
+			Listen: "127.0.0.1:9106",
+		},
+		Alert: AlertConfig{
+			WindowSec:   60,
+			CooldownSec: 300,
+		},
+		Export: AutoExportConfig{
+			IntervalMin: 15,
+		},
 	}
 }
 
@@ -118,7 +279,9 @@ func LoadConfig() (*AppConfig, error) {
 	log.Printf("Loading configuration...\n")
 	b, err := os.ReadFile(env.settingsFile)
 	if errors.Is(err, fs.ErrNotExist) {
-		return defaultConfig(), nil
+		cfg := defaultConfig()
+		cfg.firstRun = true
+		return cfg, nil
 	}
 	if err != nil {
 		return nil, err
@@ -127,9 +290,67 @@ func LoadConfig() (*AppConfig, error) {
 	if err := yaml.Unmarshal(b, cfg); err != nil {
 		return nil, err
 	}
+	migrateGraphConfig(b, cfg)
+	for _, issue := range cfg.Validate() {
+		log.Printf("Corrected setting in %s: %s\n", env.settingsFile, issue)
+	}
 	return cfg, nil
 }
 
+// legacyGraphConfig is where settings files written before the graph:
+// section kept the graph toggles.
+type legacyGraphConfig struct {
+	Graph *yaml.Node `yaml:"graph"`
+	Ping  struct {
+		ShowLoss      *bool `yaml:"show_loss"`
+		ShowWorst     *bool `yaml:"show_worst"`
+		FlapThreshold *int  `yaml:"flap_threshold"`
+		LossStrip     *bool `yaml:"loss_strip"`
+		LossStripPx   *int  `yaml:"loss_strip_px"`
+	} `yaml:"ping"`
+}
+
+// migrateGraphConfig carries the graph toggles over from ping: when the
+// settings file b has no graph: section yet. Keys that were never set keep
+// their defaults; the old keys are dropped on the next save.
+func migrateGraphConfig(b []byte, cfg *AppConfig) {
+	var old legacyGraphConfig
+	if err := yaml.Unmarshal(b, &old); err != nil || old.Graph != nil {
+		return
+	}
+	p, g := old.Ping, &cfg.Graph
+	if p.ShowLoss != nil {
+		g.ShowLoss = *p.ShowLoss
+	}
+	if p.ShowWorst != nil {
+		g.ShowWorst = *p.ShowWorst
+	}
+	if p.FlapThreshold != nil {
+		g.FlapThreshold = *p.FlapThreshold
+	}
+	if p.LossStrip != nil {
+		g.LossStrip = *p.LossStrip
+	}
+	if p.LossStripPx != nil {
+		g.LossStripPx = *p.LossStripPx
+	}
+}
+
+// seedFirstRunHosts gives new users something to look at: their router and
+// a public DNS. Only applies before the config was ever saved, so a list the
+// user cleared on purpose stays empty.
+func seedFirstRunHosts(cfg *AppConfig) {
+	if cfg == nil || !cfg.firstRun || len(cfg.Ping.Hosts) > 0 {
+		return
+	}
+	if gw, err := netinfo.DefaultGateway(); err == nil {
+		cfg.Ping.Hosts = append(cfg.Ping.Hosts, HostConfig{Name: "Gateway", Addr: gw, Enabled: true})
+	} else {
+		log.Printf("First run: unable to detect default gateway: %s\n", err)
+	}
+	cfg.Ping.Hosts = append(cfg.Ping.Hosts, HostConfig{Name: "Cloudflare DNS", Addr: "1.1.1.1", Enabled: true})
+}
+
 func SaveConfig(cfg *AppConfig) error {
 	log.Printf("Saving configuration...\n")
 	if err := os.MkdirAll(filepath.Dir(env.settingsFile), 0o755); err != nil {
@@ -194,12 +415,12 @@ func initlog() {
 		log.Fatal(err)
 		return
 	}
-	// we always write to log file; if DEBUG=true we write to stdout too)
+	// we always write to log file and the log console; if DEBUG=true we write to stdout too)
 	if debugging == "true" {
 		DEBUG = true
-		log.SetOutput(io.MultiWriter(file, os.Stdout))
+		log.SetOutput(io.MultiWriter(file, appLog, os.Stdout))
 	} else {
-		log.SetOutput(io.MultiWriter(file))
+		log.SetOutput(io.MultiWriter(file, appLog))
 	}
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 }
@@ -226,6 +447,14 @@ func configDir() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".config", appName)
 }
+func sessionsDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", appName, "sessions")
+}
+func exportsDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", appName, "exports")
+}
 func logsDir() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".config", appName, "logs")
@@ -0,0 +1,145 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/e1z0/speedping/internal/dns"
+	"github.com/e1z0/speedping/internal/hostlist"
+	"github.com/e1z0/speedping/internal/monitor"
+)
+
+// Endpoint check: ping a few well-known endpoints for a short while and
+// tell from which of them answer whether the problem is routing, DNS or one
+// particular site. It runs as a guided check (see checkrun.go), so the
+// endpoints are pinged without being added to the host list.
+
+const (
+	checkDuration = 15 * time.Second
+	checkResolve  = 5 * time.Second // per name
+)
+
+type checkEndpoint struct {
+	Name   string
+	Addr   string
+	ByName bool // needs DNS; the others are plain addresses
+}
+
+var checkEndpoints = []checkEndpoint{
+	{Name: "Root DNS (a.root-servers.net)", Addr: "198.41.0.4"},
+	{Name: "Cloudflare DNS", Addr: "1.1.1.1"},
+	{Name: "Google DNS", Addr: "8.8.8.8"},
+	{Name: "Cloudflare CDN", Addr: "www.cloudflare.com", ByName: true},
+	{Name: "Google", Addr: "www.google.com", ByName: true},
+}
+
+// runConnCheck pings every endpoint for checkDuration while looking the
+// named ones up.
+func (ui *UI) runConnCheck() {
+	var hosts []hostlist.Entry
+	for _, ep := range checkEndpoints {
+		hosts = append(hosts, hostlist.Entry{Name: ep.Name, Addr: ep.Addr})
+	}
+	var unresolved map[string]bool
+	ui.runGuidedCheck(guidedCheck{
+		Title:    "Endpoint check",
+		Running:  fmt.Sprintf("Pinging %d well-known endpoints and looking their names up…", len(checkEndpoints)),
+		Hosts:    hosts,
+		Duration: checkDuration,
+		Side: func(ctx context.Context) func() {
+			failed := checkLookups(ctx)
+			return func() { unresolved = failed }
+		},
+		Verdict: func(reports []monitor.HostReport) (string, diagLevel) { return checkVerdict(reports, unresolved) },
+		Details: func(reports []monitor.HostReport) string {
+			var shown []monitor.HostReport
+			var lines []string
+			for i, r := range reports {
+				if unresolved[checkEndpoints[i].Addr] {
+					lines = append(lines, r.Addr+" did not resolve.")
+					continue
+				}
+				shown = append(shown, r)
+			}
+			return strings.Join(append(reportLines(shown), lines...), "\n")
+		},
+	})
+}
+
+// checkLookups resolves the named endpoints, returning those that failed.
+func checkLookups(ctx context.Context) map[string]bool {
+	failed := map[string]bool{}
+	for _, ep := range checkEndpoints {
+		if !ep.ByName {
+			continue
+		}
+		lctx, cancel := context.WithTimeout(ctx, checkResolve)
+		if _, err := dns.Lookup(lctx, ep.Addr); err != nil {
+			log.Printf("Endpoint check: unable to resolve %s: %s\n", ep.Addr, err)
+			failed[ep.Addr] = true
+		}
+		cancel()
+	}
+	return failed
+}
+
+// checkVerdict sorts the answers, one report per checkEndpoints entry, into:
+// nothing reachable (routing), only addresses reachable (DNS), some
+// endpoints silent (those sites) or all fine.
+func checkVerdict(reports []monitor.HostReport, unresolved map[string]bool) (string, diagLevel) {
+	up := func(r monitor.HostReport) bool { return r.Samples > 0 && r.LossPct < 100 }
+	addrUp, names := 0, 0
+	var silent, failed []string
+	for i, r := range reports {
+		ep := checkEndpoints[i]
+		if unresolved[ep.Addr] {
+			failed = append(failed, ep.Addr)
+			continue
+		}
+		if ep.ByName {
+			names++
+		}
+		switch {
+		case !up(r):
+			silent = append(silent, r.Name)
+		case !ep.ByName:
+			addrUp++
+		}
+	}
+	switch {
+	case addrUp == 0:
+		return "No connectivity: none of the well-known addresses answered. Check your gateway (Diagnose) and your ISP.", diagBad
+	case len(failed) > 0 && names == 0:
+		return "DNS problem: the internet answers by address, but " + strings.Join(failed, " and ") + " did not resolve.", diagBad
+	case len(silent) > 0 || len(failed) > 0:
+		what := " did not answer"
+		if len(failed) > 0 {
+			what = " did not answer or resolve"
+		}
+		return "Mostly connected: " + strings.Join(append(silent, failed...), ", ") + what + "; the problem is likely with those sites or their routes.", diagWarn
+	}
+	return fmt.Sprintf("Connected: all %d endpoints answered and their names resolved.", len(reports)), diagGood
+}
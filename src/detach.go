@@ -0,0 +1,129 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import "github.com/mappu/miqt/qt"
+
+// Detached graph: the ping graph can move into a window of its own, e.g. on
+// a second monitor, while the controls stay in the main window. The graph
+// widget itself moves (its ticker has no parent and keeps running); closing
+// that window brings it back. Whether the graph was detached and where its
+// window was are saved on exit.
+
+// graphPane holds the ping graph in the ping tab, or a note with an Attach
+// button while it is detached.
+func (ui *UI) graphPane() *qt.QWidget {
+	pane := qt.NewQWidget(nil)
+	ui.graphBox = qt.NewQVBoxLayout(nil)
+	ui.graphBox.SetContentsMargins(0, 0, 0, 0)
+	pane.SetLayout(ui.graphBox.QLayout)
+	ui.graphBox.AddWidget2(&ui.graph.QWidget, 1)
+
+	ui.graphNote = qt.NewQWidget(nil)
+	row := qt.NewQHBoxLayout(nil)
+	ui.graphNote.SetLayout(row.QLayout)
+	btn := qt.NewQPushButton(nil)
+	btn.SetText("Attach")
+	btn.OnClicked(func() { ui.setDetached(false) })
+	row.AddStretch()
+	row.AddWidget(qt.NewQLabel6("The graph is in its own window.", nil, 0).QWidget)
+	row.AddWidget(btn.QWidget)
+	row.AddStretch()
+	ui.graphNote.SetVisible(false)
+	ui.graphBox.AddWidget(ui.graphNote)
+	return pane
+}
+
+// setDetached moves the graph into its own window or back into the tab.
+func (ui *UI) setDetached(on bool) {
+	if on == ui.detached {
+		return
+	}
+	if on && ui.mini {
+		ui.setMiniMode(false)
+	}
+	ui.detached = on
+	sc := dpiScale(ui.graph.QPaintDevice)
+	if on {
+		if ui.graphWin == nil {
+			ui.graphWin = qt.NewQMainWindow(nil)
+			ui.graphWin.SetWindowTitle(AppName + " – ping graph")
+			ui.graphWin.OnCloseEvent(func(super func(*qt.QCloseEvent), e *qt.QCloseEvent) {
+				ui.setDetached(false)
+				super(e)
+			})
+			ui.graphWin.OnChangeEvent(func(super func(*qt.QEvent), e *qt.QEvent) {
+				super(e)
+				if e.Type() == qt.QEvent__ActivationChange {
+					ui.applyFrameRate()
+				}
+			})
+		}
+		ui.graphBox.RemoveWidget(&ui.graph.QWidget)
+		ui.graphWin.SetCentralWidget(&ui.graph.QWidget)
+		ui.graph.SetMinimumSize2(int(320*sc), int(160*sc))
+		g := ui.graphGeom
+		if g.W <= 0 || g.H <= 0 {
+			g = WindowConfig{W: int(800 * sc), H: int(400 * sc)}
+		}
+		applyWindowGeometry(ui.graphWin, g)
+		ui.graph.Show()
+		ui.graphWin.Show()
+	} else {
+		ui.graphGeom = windowGeometry(ui.graphWin)
+		ui.graphWin.TakeCentralWidget()
+		ui.graphBox.InsertWidget2(0, &ui.graph.QWidget, 1)
+		ui.graph.SetMinimumSize2(int(800*sc), int(320*sc))
+		ui.graph.Show()
+		ui.graphWin.Hide()
+	}
+	ui.graphNote.SetVisible(on)
+	ui.btnDetach.SetChecked(on)
+	ui.applyFrameRate()
+}
+
+// GraphWindow returns where the detached graph window is, or was last, and
+// whether the graph is detached right now.
+func (ui *UI) GraphWindow() (WindowConfig, bool) {
+	if ui.detached {
+		return windowGeometry(ui.graphWin), true
+	}
+	return ui.graphGeom, false
+}
+
+// windowActive reports whether the main or the graph window has the focus.
+func (ui *UI) windowActive() bool {
+	return ui.main.IsActiveWindow() || (ui.detached && ui.graphWin.IsActiveWindow())
+}
+
+// graphMenu extends the graph's context menu with the window toggles.
+func (ui *UI) graphMenu(menu *qt.QMenu) {
+	text := "Detach graph"
+	if ui.detached {
+		text = "Attach graph"
+	}
+	menu.AddSeparator()
+	act := menu.AddAction(text)
+	act.OnTriggered(func() { ui.setDetached(!ui.detached) })
+	if !ui.detached {
+		ui.miniMenuAction(menu)
+	}
+}
@@ -0,0 +1,134 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/e1z0/speedping/internal/netinfo"
+	"github.com/mappu/miqt/qt"
+)
+
+// "Is it my router or my ISP?" — ping the gateway next to a public target
+// and summarize which side is losing packets.
+
+const (
+	diagPublicName = "Cloudflare DNS"
+	diagPublicAddr = "1.1.1.1"
+	diagWindow     = 60 * time.Second // loss is judged over the last minute
+	diagMinSamples = 5                // don't judge before we have a few replies
+	diagLossPct    = 5.0              // above this a link counts as degraded
+)
+
+func (ui *UI) runDiagnose() {
+	gwAddr, err := netinfo.DefaultGateway()
+	if err != nil {
+		ui.setDiagBanner(fmt.Sprintf("Diagnose: unable to detect default gateway (%v).", err), diagBad)
+		return
+	}
+
+	// structural changes only while stopped; the diagnosis always (re)starts pinging
+	since := ui.runningSince
+	ui.StopPinging()
+
+	// reuse hosts already in the list, add the missing ones
+	if ui.diagGW = ui.model.FindHost(gwAddr); ui.diagGW == nil {
+		ui.diagGW = ui.addHost("Gateway", gwAddr)
+	}
+	if ui.diagNet = ui.model.FindHost(diagPublicAddr); ui.diagNet == nil {
+		ui.diagNet = ui.addHost(diagPublicName, diagPublicAddr)
+	}
+	ui.StartPinging()
+	ui.keepRunningSince(since)
+
+	if ui.diagTimer == nil {
+		ui.diagTimer = qt.NewQTimer()
+		ui.diagTimer.OnTimeout(func() { ui.updateDiagnose() })
+	}
+	ui.diagTimer.Start(1000)
+	ui.updateDiagnose()
+}
+
+type diagLevel int
+
+const (
+	diagInfo diagLevel = iota
+	diagGood
+	diagWarn
+	diagBad
+)
+
+func (ui *UI) setDiagBanner(text string, lvl diagLevel) {
+	ui.diagBanner.SetStyleSheet(diagCSS(lvl))
+	ui.diagBanner.SetText(text)
+	ui.diagBanner.Show()
+}
+
+// diagCSS is the style sheet of a label showing a verdict of level lvl.
+func diagCSS(lvl diagLevel) string {
+	css := "padding: 6px; border-radius: 4px; "
+	switch lvl {
+	case diagGood:
+		css += "background: rgba(120, 230, 140, 90);"
+	case diagWarn:
+		css += "background: rgba(255, 200, 80, 110);"
+	case diagBad:
+		css += "background: rgba(255, 120, 120, 110);"
+	default:
+		css += "background: rgba(90, 180, 255, 70);"
+	}
+	return css
+}
+
+func (ui *UI) updateDiagnose() {
+	// hosts removed from the list (or pinging stopped) end the diagnosis
+	if ui.model.FindHost(ui.diagGW.Addr) != ui.diagGW || ui.model.FindHost(ui.diagNet.Addr) != ui.diagNet {
+		ui.diagTimer.Stop()
+		ui.diagBanner.Hide()
+		return
+	}
+	if !ui.running {
+		ui.diagTimer.Stop()
+		ui.setDiagBanner("Diagnosis stopped.", diagInfo)
+		return
+	}
+
+	since := time.Now().Add(-diagWindow)
+	gwN, gwLost := ui.diagGW.buf.LossSince(since)
+	netN, netLost := ui.diagNet.buf.LossSince(since)
+	if gwN < diagMinSamples || netN < diagMinSamples {
+		ui.setDiagBanner("Diagnosing… collecting samples.", diagInfo)
+		return
+	}
+	gwPct := 100 * float64(gwLost) / float64(gwN)
+	netPct := 100 * float64(netLost) / float64(netN)
+	detail := fmt.Sprintf("(gateway %s%% loss, %s %s%% loss)", formatFloat(gwPct, 0), ui.diagNet.Name, formatFloat(netPct, 0))
+
+	switch {
+	case gwPct > diagLossPct:
+		ui.setDiagBanner("Local network degraded: your gateway is dropping packets "+detail, diagBad)
+	case netPct > diagLossPct:
+		ui.setDiagBanner("Local network OK, upstream degraded: the problem is past your router "+detail, diagWarn)
+	default:
+		ui.setDiagBanner("Local network OK, internet OK "+detail, diagGood)
+	}
+}
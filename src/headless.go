@@ -0,0 +1,134 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/e1z0/speedping/internal/monitor"
+)
+
+// Headless check (speedping --headless): pings the hosts in settings.yml
+// without opening a window until a stop condition is met or it is
+// interrupted, then prints the session summary, e.g. for "run a 5-minute
+// check and tell me the result" from a script. The conditions default to
+// the "Stop after" settings.
+
+const headlessFlag = "--headless"
+
+// runHeadless runs a check with the command line args following
+// headlessFlag and returns the exit code.
+func runHeadless(args []string) int {
+	cfg, _ := LoadConfig()
+	if cfg == nil {
+		cfg = defaultConfig()
+	}
+	fs := flag.NewFlagSet("speedping "+headlessFlag, flag.ContinueOnError)
+	dur := fs.Duration("duration", time.Duration(cfg.Ping.StopMinutes)*time.Minute, "stop after this long, e.g. 5m")
+	samples := fs.Int("samples", cfg.Ping.StopSamples, "stop after this many samples per host")
+	streak := fs.Int("loss-streak", cfg.Ping.StopLossStreak, "stop when a host loses this many in a row")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	cond := monitor.StopCondition{Duration: *dur, Samples: *samples, LossStreak: *streak}
+
+	model := NewAppModel()
+	model.LoadFromConfig(cfg)
+	if model.Count() == 0 {
+		fmt.Fprintln(os.Stderr, "No hosts to ping; add some in settings.yml or the app first.")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	interval := time.Duration(model.PingIntervalMs()) * time.Millisecond
+	pb := ProbingBackend{
+		Interval:  interval,
+		MaxRTT:    pingMaxRTT(interval),
+		GraceLate: 100 * time.Millisecond,
+		Adaptive:  cfg.Ping.AdaptiveTimeout,
+		Source:    cfg.Ping.Source,
+	}
+	start := time.Now()
+	sw := monitor.Arm(cond, start)
+	model.Subscribe(func(h *Host, s Sample) { sw.Observe(model.Ident(h).Addr, s) })
+
+	var wg sync.WaitGroup
+	for _, h := range model.Hosts() {
+		cmd, custom := monitor.CommandLine(h.Addr)
+		if custom && !cfg.Ping.CustomProbes {
+			fmt.Fprintf(os.Stderr, "Skipping %s: custom probes are off (ping.custom_probes)\n", h.Name)
+			continue
+		}
+		hb := backendFor(pb, h)
+		onSample := func(s Sample) { model.notify(h, s) }
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if custom {
+				err = monitor.CommandBackend{Interval: hb.Interval}.Run(ctx, cmd, h.buf, onSample)
+			} else {
+				err = hb.Run(ctx, h.Addr, h.buf, onSample)
+			}
+			if err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Fprintf(os.Stderr, "Pinging %s (%s) failed: %s\n", h.Name, h.Addr, err)
+			}
+		}()
+	}
+	if cond.Enabled() {
+		fmt.Fprintf(os.Stderr, "Pinging %d host(s) until a stop condition is met or Ctrl-C…\n", model.Count())
+	} else {
+		fmt.Fprintf(os.Stderr, "Pinging %d host(s) until Ctrl-C…\n", model.Count())
+	}
+
+	reason := "interrupted"
+	tick := time.NewTicker(250 * time.Millisecond)
+	defer tick.Stop()
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			break wait
+		case now := <-tick.C:
+			if r, due := sw.Due(now); due {
+				reason = r
+				break wait
+			}
+		}
+	}
+	cancel()
+	wg.Wait()
+	end := time.Now()
+	fmt.Print(summaryText(model.sessionSummary(start, end), start, end, reason))
+	return 0
+}
@@ -0,0 +1,223 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// The heatmap strip (graph.heatmap) sits under the ping graph and packs
+// everything still in the rings into one row per host: each column is a
+// slice of time colored by its latency bucket, so a pattern over the last
+// few hundred samples shows at a glance where the line graph has long
+// scrolled it away.
+
+// heatEdges are the upper RTT bounds (ms) of the latency buckets; replies
+// at or above the last one fall into the top bucket.
+var heatEdges = []float64{20, 50, 100, 200}
+
+const (
+	heatCellPx = 4  // column width at 96 DPI
+	heatRowPx  = 10 // row height at 96 DPI
+)
+
+// heatCell sums up the samples that fell into one column.
+type heatCell struct {
+	n, lost int
+	sum     float64 // ms of replies
+}
+
+// level is the cell's bucket: -1 without samples, 0..len(heatEdges) by
+// average RTT (one up when anything was lost), len(heatEdges)+1 when at
+// least half the probes were lost.
+func (c heatCell) level() int {
+	if c.n == 0 {
+		return -1
+	}
+	if c.lost*2 >= c.n {
+		return len(heatEdges) + 1
+	}
+	avg := c.sum / float64(c.n-c.lost)
+	lv := len(heatEdges)
+	for i, e := range heatEdges {
+		if avg < e {
+			lv = i
+			break
+		}
+	}
+	if c.lost > 0 {
+		lv = min(lv+1, len(heatEdges))
+	}
+	return lv
+}
+
+// heatColumns bins samples into cols columns of step each, the last one
+// ending at end. Gaps (suspend) are left out.
+func heatColumns(samples []Sample, end time.Time, step time.Duration, cols int) []heatCell {
+	out := make([]heatCell, cols)
+	start := end.Add(-time.Duration(cols) * step)
+	for _, s := range samples {
+		if s.State == SampleGap || s.T.Before(start) || !s.T.Before(end) {
+			continue
+		}
+		c := &out[int(s.T.Sub(start)/step)]
+		c.n++
+		if s.State == SampleLoss {
+			c.lost++
+		} else {
+			c.sum += s.MS
+		}
+	}
+	return out
+}
+
+// heatColor maps a level to a color between the palette's ok, warn and bad;
+// the loss level is a darkened bad.
+func heatColor(lv int) *qt.QColor {
+	top := len(heatEdges)
+	if lv > top {
+		c := palette.bad
+		return qcolor(c[0]*55/100, c[1]*55/100, c[2]*55/100, 255)
+	}
+	from, to, f := palette.ok, palette.warn, float64(lv)/float64(top/2)
+	if lv > top/2 {
+		from, to, f = palette.warn, palette.bad, float64(lv-top/2)/float64(top-top/2)
+	}
+	mix := func(i int) int { return int(float64(from[i]) + (float64(to[i])-float64(from[i]))*f) }
+	return qcolor(mix(0), mix(1), mix(2), 255)
+}
+
+type HeatmapWidget struct {
+	qt.QWidget
+
+	model  *AppModel
+	ticker *qt.QTimer
+	snap   []Sample
+
+	// layout of the last frame, for the hover tooltip
+	end  time.Time
+	step time.Duration
+	cols [][]heatCell
+}
+
+func NewHeatmapWidget(model *AppModel) *HeatmapWidget {
+	m := &HeatmapWidget{model: model}
+	m.QWidget = *qt.NewQWidget(nil)
+	m.SetMouseTracking(true)
+	m.OnPaintEvent(func(super func(*qt.QPaintEvent), e *qt.QPaintEvent) { m.paint() })
+	m.OnMouseMoveEvent(func(super func(*qt.QMouseEvent), e *qt.QMouseEvent) {
+		qt.QToolTip_ShowText(e.GlobalPos(), m.cellText(e.X(), e.Y()))
+	})
+	m.fitHeight()
+	return m
+}
+
+// StartTicker repaints once a second; the columns are far coarser than a frame.
+func (m *HeatmapWidget) StartTicker() {
+	m.ticker = qt.NewQTimer()
+	m.ticker.OnTimeout(func() {
+		m.fitHeight()
+		m.Update()
+	})
+	m.ticker.Start(1000)
+}
+
+// fitHeight gives every host a row, with room for at least one.
+func (m *HeatmapWidget) fitHeight() {
+	sc := dpiScale(m.QPaintDevice)
+	rows := max(m.model.Count(), 1)
+	if h := int(float64(rows*heatRowPx) * sc); h != m.Height() {
+		m.SetFixedHeight(h)
+	}
+}
+
+// window is how much time the strip covers: a full ring at the ping interval.
+func (m *HeatmapWidget) window() time.Duration {
+	return time.Duration(m.model.PingIntervalMs()) * time.Millisecond * time.Duration(m.model.RingCap())
+}
+
+func (m *HeatmapWidget) paint() {
+	W, H := float64(m.Width()), float64(m.Height())
+	if W < 4 || H < 4 {
+		return
+	}
+	p := qt.NewQPainter()
+	if !p.Begin(m.QPaintDevice) {
+		return
+	}
+	defer p.End()
+	sc := dpiScale(m.QPaintDevice)
+	bg := m.Palette().ColorWithCr(qt.QPalette__Window)
+	p.FillRect4(qt.NewQRectF4(0, 0, W, H), bg)
+
+	hosts := m.model.Hosts()
+	m.cols = m.cols[:0]
+	ncol := max(int(W/(heatCellPx*sc)), 1)
+	cellW := W / float64(ncol)
+	// columns are aligned to multiples of step so they don't shimmer as
+	// the strip scrolls; each covers at least a millisecond
+	m.step = max(m.window()/time.Duration(ncol), time.Millisecond)
+	m.end = time.Now().Truncate(m.step).Add(m.step)
+	if len(hosts) == 0 {
+		return
+	}
+	rowH := H / float64(len(hosts))
+	for i, h := range hosts {
+		m.snap = h.buf.Snapshot(m.snap)
+		cells := heatColumns(m.snap, m.end, m.step, ncol)
+		m.cols = append(m.cols, cells)
+		y := float64(i) * rowH
+		for j, c := range cells {
+			if lv := c.level(); lv >= 0 {
+				p.FillRect4(qt.NewQRectF4(float64(j)*cellW, y+0.5, cellW+0.5, rowH-1), heatColor(lv))
+			}
+		}
+	}
+}
+
+// cellText describes the column under x in the host row under y.
+func (m *HeatmapWidget) cellText(x, y int) string {
+	hosts := m.model.Hosts()
+	if len(m.cols) == 0 || len(hosts) != len(m.cols) || m.Width() <= 0 || m.Height() <= 0 {
+		return ""
+	}
+	row := min(y*len(m.cols)/m.Height(), len(m.cols)-1)
+	cells := m.cols[row]
+	col := min(max(x*len(cells)/m.Width(), 0), len(cells)-1)
+	c := cells[col]
+	from := m.end.Add(-time.Duration(len(cells)-col) * m.step)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s – %s\n", hosts[row].Name, from.Format("15:04:05"), from.Add(m.step).Format("15:04:05"))
+	switch {
+	case c.n == 0:
+		b.WriteString("no samples")
+	case c.lost == c.n:
+		fmt.Fprintf(&b, "%d probes, all lost", c.n)
+	default:
+		fmt.Fprintf(&b, "%d probes, avg %s ms, loss %s%%", c.n,
+			formatFloat(c.sum/float64(c.n-c.lost), 1), formatFloat(float64(c.lost)*100/float64(c.n), 0))
+	}
+	return b.String()
+}
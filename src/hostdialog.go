@@ -0,0 +1,106 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// editHostDialog lets the user change a host's name, address, note, latency
+// thresholds and probing overrides. ok is false when cancelled or the
+// address was left empty.
+func editHostDialog(parent *qt.QWidget, h *Host) (hc HostConfig, ok bool) {
+	dlg := qt.NewQDialog(parent)
+	dlg.SetWindowTitle("Edit host")
+	form := qt.NewQFormLayout(nil)
+	dlg.SetLayout(form.QLayout)
+
+	nameEd := qt.NewQLineEdit(nil)
+	nameEd.SetText(h.Name)
+	addrEd := qt.NewQLineEdit(nil)
+	addrEd.SetText(h.Addr)
+	noteEd := qt.NewQLineEdit(nil)
+	noteEd.SetText(h.Note)
+	noteEd.SetPlaceholderText("e.g. office uplink, ISP modem…")
+	noteEd.SetMinimumWidth(scaledPx(280))
+	warnEd := optionalEdit(h.WarnMs)
+	badEd := optionalEdit(h.BadMs)
+	intEd := optionalEdit(float64(h.IntervalMs))
+	intEd.SetToolTip("Ping this host at its own interval, e.g. a gateway every 200 ms")
+	sizeEd := optionalEdit(float64(h.PacketSize))
+	sizeEd.SetPlaceholderText("global (56)")
+
+	form.AddRow3("Name:", nameEd.QWidget)
+	form.AddRow3("Host/IP:", addrEd.QWidget)
+	form.AddRow3("Note:", noteEd.QWidget)
+	form.AddRow3("Warn above (ms):", warnEd.QWidget)
+	form.AddRow3("Bad above (ms):", badEd.QWidget)
+	form.AddRow3("Interval (ms):", intEd.QWidget)
+	form.AddRow3("Packet size (bytes):", sizeEd.QWidget)
+
+	buttons := qt.NewQDialogButtonBox4(qt.QDialogButtonBox__Ok | qt.QDialogButtonBox__Cancel)
+	buttons.OnAccepted(func() { dlg.Accept() })
+	buttons.OnRejected(func() { dlg.Reject() })
+	form.AddRow(nil, buttons.QWidget)
+
+	if dlg.Exec() != int(qt.QDialog__Accepted) {
+		return HostConfig{}, false
+	}
+	interval := atoiDefault(intEd.Text(), 0)
+	if interval > 0 {
+		interval = max(interval, minHostIntervalMs)
+	}
+	hc = HostConfig{
+		Name:    strings.TrimSpace(nameEd.Text()),
+		Addr:    strings.TrimSpace(addrEd.Text()),
+		Enabled: true,
+		Note:    strings.TrimSpace(noteEd.Text()),
+		WarnMs:  atofDefault(warnEd.Text(), 0),
+		BadMs:   atofDefault(badEd.Text(), 0),
+
+		IntervalMs: interval,
+		PacketSize: min(atoiDefault(sizeEd.Text(), 0), maxPacketSize),
+	}
+	if hc.Addr == "" {
+		return HostConfig{}, false
+	}
+	return hc, true
+}
+
+// Limits for the per-host overrides: the interval slider's minimum, and
+// the largest ICMP payload (65535 minus IP and ICMP headers).
+const (
+	minHostIntervalMs = 100
+	maxPacketSize     = 65507
+)
+
+// optionalEdit is a line edit for an optional value; empty means "use global".
+func optionalEdit(v float64) *qt.QLineEdit {
+	ed := qt.NewQLineEdit(nil)
+	ed.SetPlaceholderText("global")
+	if v > 0 {
+		ed.SetText(strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return ed
+}
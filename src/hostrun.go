@@ -0,0 +1,150 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/e1z0/speedping/internal/monitor"
+	"github.com/mappu/miqt/qt/mainthread"
+)
+
+// Each host is probed by its own goroutine under a child of the session's
+// context. When its prober fails mid-session (a socket error, the address
+// no longer resolving...) it is restarted with a growing delay; only after
+// hostRetries failures in a row does the host give up and show as failed.
+
+const (
+	hostRetries    = 8
+	hostRetryFirst = 2 * time.Second
+	hostRetryMax   = time.Minute
+	// a run lasting this long counts as recovered: the next failure starts
+	// the retries over
+	hostRetryReset = 5 * time.Minute
+)
+
+// hostRun is one host's prober goroutine.
+type hostRun struct {
+	cancel context.CancelFunc
+	done   chan struct{} // closed once the goroutine has returned
+}
+
+func (r *hostRun) exited() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// startHost starts probing h under the running session; false when h is a
+// custom probe while those are turned off. When h's previous prober (paused
+// or from the last session) is still winding down, the new one starts only
+// once it has returned, so its last samples and HostStopped can't land
+// after the new run's.
+func (ui *UI) startHost(h *Host) bool {
+	cmd, custom := monitor.CommandLine(h.Addr)
+	if custom && !ui.customOn {
+		return false
+	}
+	if prev := ui.hostRuns[h]; prev != nil && !prev.exited() {
+		prev.cancel()
+		runCtx := ui.runCtx
+		go func() {
+			<-prev.done
+			mainthread.Wait(func() {
+				if ui.runCtx == runCtx && ui.hostRuns[h] == prev && !h.Paused && slices.Contains(ui.model.Hosts(), h) {
+					ui.startHost(h)
+				}
+			})
+		}()
+		return true
+	}
+	ctx, cancel := context.WithCancel(ui.runCtx)
+	r := &hostRun{cancel: cancel, done: make(chan struct{})}
+	ui.hostRuns[h] = r
+	h.State = HostRunning
+	if h.Err != "" {
+		h.Err = ""
+		ui.syncHost(h)
+	}
+	onSample := func(s Sample) { ui.model.notify(h, s) }
+	var run func(ctx context.Context) error
+	if custom {
+		cb := monitor.CommandBackend{Interval: backendFor(ui.backend, h).Interval}
+		run = func(ctx context.Context) error { return cb.Run(ctx, cmd, h.buf, onSample) }
+	} else {
+		pb := backendFor(ui.backend, h)
+		pb.OnResolve = func(old, cur string) {
+			mainthread.Wait(func() { ui.hostResolved(h, old, cur) })
+		}
+		run = func(ctx context.Context) error { return pb.Run(ctx, h.Addr, h.buf, onSample) }
+	}
+	go func() {
+		defer close(r.done)
+		ui.keepProbing(ctx, h, run)
+		h.State = HostStopped
+	}()
+	return true
+}
+
+// keepProbing runs run until ctx is done, restarting it with backoff when it
+// ends on its own. After hostRetries failures in a row h is marked failed.
+func (ui *UI) keepProbing(ctx context.Context, h *Host, run func(ctx context.Context) error) {
+	wait, fails := hostRetryFirst, 0
+	for {
+		began := time.Now()
+		err := run(ctx)
+		if ctx.Err() != nil {
+			return // stopped or paused
+		}
+		if err == nil {
+			err = errors.New("prober stopped")
+		}
+		if time.Since(began) >= hostRetryReset {
+			wait, fails = hostRetryFirst, 0
+		}
+		fails++
+		if fails > hostRetries {
+			log.Printf("Pinging %s (%s) failed %d times in a row, giving up: %s\n", h.Name, h.Addr, fails, err)
+			msg := fmt.Sprintf("gave up after %d failures: %s", fails, err)
+			mainthread.Wait(func() {
+				h.Err = msg
+				ui.syncHost(h)
+				ui.main.StatusBar().ShowMessage2(fmt.Sprintf("Pinging %s stopped: %s", h.Name, err), 30000)
+			})
+			return
+		}
+		log.Printf("Pinging %s (%s) failed: %s; restarting in %s (%d of %d)\n", h.Name, h.Addr, err, wait, fails, hostRetries)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, hostRetryMax)
+	}
+}
@@ -0,0 +1,89 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/e1z0/speedping/internal/hostlist"
+	"github.com/mappu/miqt/qt"
+)
+
+// "Import hosts…": the file format is parsed by internal/hostlist.
+
+const hostsFileFilter = "Host lists (*.txt *.csv *.hosts hosts);;All files (*)"
+
+// importHosts asks for a host list file and adds every host in it that
+// isn't monitored yet.
+func (ui *UI) importHosts() {
+	path := qt.QFileDialog_GetOpenFileName4(ui.main.QWidget, "Import hosts", appPath(), hostsFileFilter)
+	if path == "" {
+		return
+	}
+	hosts, err := readHostsFile(path)
+	var skipped *hostlist.SkippedLinesError
+	if err != nil && !errors.As(err, &skipped) {
+		log.Printf("Unable to import hosts from %s: %s\n", path, err)
+		qt.QMessageBox_Warning(ui.main.QWidget, "Import hosts", err.Error())
+		return
+	}
+
+	added, dups := 0, 0
+	ui.model.BeginBatch()
+	for _, e := range hosts {
+		if ui.model.FindHost(e.Addr) != nil {
+			dups++
+			continue
+		}
+		ui.addHost(e.Name, e.Addr)
+		added++
+	}
+	ui.model.EndBatch()
+
+	msg := fmt.Sprintf("Imported %d host(s)", added)
+	if dups > 0 {
+		msg += fmt.Sprintf(", %d already monitored", dups)
+	}
+	if skipped != nil {
+		msg += fmt.Sprintf(", skipped %d invalid line(s)", len(skipped.Lines))
+		log.Printf("Importing hosts from %s: %s\n", path, skipped)
+	}
+	ui.main.StatusBar().ShowMessage2(msg, 10000)
+	if added == 0 {
+		return
+	}
+	ui.updateButtons()
+	if ui.running {
+		ui.restartPinging()
+	}
+}
+
+func readHostsFile(path string) ([]hostlist.Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return hostlist.Parse(f)
+}
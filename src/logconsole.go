@@ -0,0 +1,133 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// The log console on the About tab shows the newest lines of debug.log as
+// they are written: initlog tees the log into logTail, and the console
+// polls it while open.
+
+const logTailLines = 1000
+
+// logTail keeps the last max lines written to it.
+type logTail struct {
+	mu    sync.Mutex
+	lines []string
+	total uint64 // lines ever written; the newest is number total
+	max   int
+}
+
+var appLog = &logTail{max: logTailLines}
+
+// Write takes one or more complete log lines, as the log package writes them.
+func (t *logTail) Write(p []byte) (int, error) {
+	text := strings.TrimRight(string(p), "\n")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, line := range strings.Split(text, "\n") {
+		t.lines = append(t.lines, line)
+		t.total++
+	}
+	if over := len(t.lines) - t.max; over > 0 {
+		t.lines = append(t.lines[:0], t.lines[over:]...)
+	}
+	return len(p), nil
+}
+
+// Since returns the lines after number seq that are still kept, and the
+// number of the newest line to pass next time.
+func (t *logTail) Since(seq uint64) ([]string, uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := min(t.total-seq, uint64(len(t.lines)))
+	out := make([]string, n)
+	copy(out, t.lines[len(t.lines)-int(n):])
+	return out, t.total
+}
+
+// newLogConsole builds the console panel and the button that shows it.
+// It starts hidden and only polls the log while shown.
+func newLogConsole() (panel *qt.QWidget, toggle *qt.QPushButton) {
+	panel = qt.NewQWidget(nil)
+	col := qt.NewQVBoxLayout(nil)
+	col.SetContentsMargins(0, 0, 0, 0)
+	panel.SetLayout(col.QLayout)
+
+	view := qt.NewQPlainTextEdit(nil)
+	view.SetReadOnly(true)
+	view.SetLineWrapMode(qt.QPlainTextEdit__NoWrap)
+	view.SetFont(qt.QFontDatabase_SystemFont(qt.QFontDatabase__FixedFont))
+	view.SetMaximumBlockCount(logTailLines)
+	whenScaled(func() { view.SetMinimumHeight(scaledPx(160)) })
+	col.AddWidget(view.QWidget)
+
+	row := qt.NewQHBoxLayout(nil)
+	btnCopy := qt.NewQPushButton(nil)
+	btnCopy.SetText("Copy")
+	btnClear := qt.NewQPushButton(nil)
+	btnClear.SetText("Clear")
+	row.AddStretch()
+	row.AddWidget(btnCopy.QWidget)
+	row.AddWidget(btnClear.QWidget)
+	col.AddLayout(row.QLayout)
+	panel.Hide()
+
+	var seq uint64
+	poll := func() {
+		lines, next := appLog.Since(seq)
+		seq = next
+		if len(lines) == 0 {
+			return
+		}
+		// stay at the bottom unless scrolled up to read
+		bar := view.VerticalScrollBar()
+		atEnd := bar.Value() == bar.Maximum()
+		view.AppendPlainText(strings.Join(lines, "\n"))
+		if atEnd {
+			bar.SetValue(bar.Maximum())
+		}
+	}
+	timer := qt.NewQTimer()
+	timer.OnTimeout(poll)
+
+	toggle = qt.NewQPushButton(nil)
+	toggle.SetText("Log console")
+	toggle.SetCheckable(true)
+	toggle.SetToolTip("Show the app's recent log lines (errors from ping, iperf3, traceroute…) here instead of opening debug.log")
+	toggle.OnToggled(func(on bool) {
+		panel.SetVisible(on)
+		if on {
+			poll()
+			timer.Start(500)
+		} else {
+			timer.Stop()
+		}
+	})
+	btnCopy.OnClicked(func() { copyToClipboard(view.ToPlainText()) })
+	btnClear.OnClicked(func() { view.Clear() })
+	return panel, toggle
+}
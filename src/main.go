@@ -31,6 +31,9 @@ var DEBUG bool
 var globalIcon *qt.QIcon
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == headlessFlag {
+		os.Exit(runHeadless(os.Args[2:]))
+	}
 	qt.NewQApplication(os.Args)
 	pixmap := qt.NewQPixmap()
 	pixmap.Load(":/icon.png")
@@ -39,32 +42,40 @@ func main() {
 	qt.QGuiApplication_SetWindowIcon(globalIcon)
 
 	cfg, _ := LoadConfig()
+	seedFirstRunHosts(cfg)
+	if cfg != nil {
+		applyUIScale(cfg.View.UIScale)
+	}
 	model := NewAppModel()
 	model.LoadFromConfig(cfg)
 
 	ui := NewUI(model)
 
 	// restore window geometry if we have saved it already
-	if cfg.Window.W > 0 && cfg.Window.H > 0 {
-		ui.main.Resize(cfg.Window.W, cfg.Window.H)
-	}
-	if cfg.Window.X != 0 || cfg.Window.Y != 0 {
-		ui.main.Move(cfg.Window.X, cfg.Window.Y)
+	applyWindowGeometry(ui.main, cfg.Window)
+	if cfg.Window.Mini {
+		ui.setMiniMode(true)
 	}
 
 	ui.main.OnCloseEvent(func(super func(*qt.QCloseEvent), e *qt.QCloseEvent) {
-		// snapshot geometry
-		geo := WindowConfig{
-			X: ui.main.X(),
-			Y: ui.main.Y(),
-			W: ui.main.Width(),
-			H: ui.main.Height(),
-		}
-		_ = SaveConfig(model.SnapshotConfig(geo))
+		// snapshot geometry, full and mini window separately
+		full, mini := ui.Geometry()
+		full.Mini = ui.mini
+		c := model.SnapshotConfig(full)
+		c.MiniWindow = mini
+		graph, detached := ui.GraphWindow()
+		graph.Detached = detached
+		c.GraphWindow = graph
+		_ = SaveConfig(c)
+		ui.Close()
 		super(e)
 	})
 
 	ui.Show()
+	if cfg.GraphWindow.Detached {
+		ui.setDetached(true)
+	}
+	ui.AutoStart()
 	IgnoreSignum()
 	qt.QApplication_Exec()
 }
@@ -0,0 +1,282 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// The ping matrix tab lists every host in one table row: status dot, last
+// RTT, average, jitter and loss over what the ring holds. With twenty or
+// more hosts the overlaid graph lines are unreadable while the table still
+// shows which host is in trouble. Cells are refreshed in place once a second
+// while the tab is showing; a header click sorts by that column (numbers
+// worst first), a row click shows the host on the ping tab.
+
+const (
+	matStatus = iota
+	matHost
+	matLast
+	matAvg
+	matJitter
+	matLoss
+	matCols
+)
+
+var matrixHeads = [matCols]string{"", "Host", "Last (ms)", "Avg (ms)", "Jitter (ms)", "Loss %"}
+
+// matrix row status, in sort order
+const (
+	matNoData = iota
+	matGood
+	matWarn
+	matDown // last probe lost or over the bad threshold
+)
+
+// matrixRow is one host's figures for a refresh.
+type matrixRow struct {
+	host   *Host
+	rep    HostReport
+	last   float64 // ms, -1 when lost or none yet
+	status int
+}
+
+// sortKey is r's value in column col; larger is worse.
+func (r matrixRow) sortKey(col int) float64 {
+	switch col {
+	case matStatus:
+		return float64(r.status)
+	case matLast:
+		if r.last < 0 && r.status == matDown {
+			return math.Inf(1) // lost: worse than any RTT
+		}
+		return r.last
+	case matAvg:
+		return r.rep.Avg
+	case matJitter:
+		return r.rep.Jitter
+	case matLoss:
+		return r.rep.LossPct
+	}
+	return 0
+}
+
+// cells is r's text per column.
+func (r matrixRow) cells() [matCols]string {
+	out := [matCols]string{"●", r.host.Name, "–", "–", "–", "–"}
+	if r.host.Name != r.host.Addr {
+		out[matHost] += " (" + r.host.Addr + ")"
+	}
+	ms := func(v float64) string { return formatFloat(v, valuePrec(1)) }
+	if r.last >= 0 {
+		out[matLast] = ms(r.last)
+	} else if r.status == matDown {
+		out[matLast] = "lost"
+	}
+	if r.rep.Samples > 0 && r.rep.LossPct < 100 {
+		out[matAvg] = ms(r.rep.Avg)
+		out[matJitter] = ms(r.rep.Jitter)
+	}
+	if r.rep.Samples > 0 {
+		out[matLoss] = formatFloat(r.rep.LossPct, 1)
+	}
+	return out
+}
+
+var matStatusTips = [...]string{"No replies yet", "OK", "Slow or losing probes", "Last probe lost or above the bad threshold"}
+
+type PingMatrix struct {
+	*qt.QTableWidget
+
+	model  *AppModel
+	ticker *qt.QTimer
+	onPick func(h *Host) // row clicked
+
+	sortCol int  // -1 = host list order
+	desc    bool // sortCol descending
+	order   []*Host
+	shown   [][matCols]string // text in each table row, to skip unchanged cells
+	status  []int
+}
+
+func NewPingMatrix(model *AppModel, onPick func(h *Host)) *PingMatrix {
+	m := &PingMatrix{model: model, onPick: onPick, sortCol: -1}
+	m.QTableWidget = qt.NewQTableWidget(nil)
+	m.SetColumnCount(matCols)
+	m.SetHorizontalHeaderLabels(matrixHeads[:])
+	m.SetEditTriggers(qt.QAbstractItemView__NoEditTriggers)
+	m.SetSelectionBehavior(qt.QAbstractItemView__SelectRows)
+	m.SetSelectionMode(qt.QAbstractItemView__SingleSelection)
+	m.VerticalHeader().SetVisible(false)
+	hdr := m.HorizontalHeader()
+	hdr.SetSectionResizeMode2(matHost, qt.QHeaderView__Stretch)
+	hdr.SetSectionResizeMode2(matStatus, qt.QHeaderView__ResizeToContents)
+	hdr.SetSectionsClickable(true)
+	hdr.OnSectionClicked(func(col int) {
+		if col == m.sortCol {
+			m.desc = !m.desc
+		} else {
+			m.sortCol, m.desc = col, col != matHost
+		}
+		order := qt.AscendingOrder
+		if m.desc {
+			order = qt.DescendingOrder
+		}
+		hdr.SetSortIndicatorShown(true)
+		hdr.SetSortIndicator(col, order)
+		m.Refresh()
+	})
+	m.OnCellClicked(func(row, col int) {
+		if row < len(m.order) && m.onPick != nil {
+			m.onPick(m.order[row])
+		}
+	})
+	m.OnShowEvent(func(super func(*qt.QShowEvent), e *qt.QShowEvent) {
+		super(e)
+		m.Refresh()
+	})
+	return m
+}
+
+// StartTicker refreshes the table once a second while it is showing.
+func (m *PingMatrix) StartTicker() {
+	m.ticker = qt.NewQTimer()
+	m.ticker.OnTimeout(func() {
+		if m.IsVisible() {
+			m.Refresh()
+		}
+	})
+	m.ticker.Start(1000)
+}
+
+// rows gathers the current figures, in the table's sort order.
+func (m *PingMatrix) rows() []matrixRow {
+	hosts := m.model.Hosts()
+	reports := m.model.Report()
+	if len(reports) != len(hosts) {
+		return nil
+	}
+	rows := make([]matrixRow, len(hosts))
+	for i, h := range hosts {
+		r := matrixRow{host: h, rep: reports[i], last: -1}
+		if s, ok := h.buf.Last(); ok {
+			warn, bad := m.model.Thresholds(h)
+			switch {
+			case s.State == SampleLoss:
+				r.status = matDown
+			case rttLevelOf(s.MS, warn, bad) == levelBad:
+				r.status, r.last = matDown, s.MS
+			case rttLevelOf(s.MS, warn, bad) == levelWarn || r.rep.LossPct > 0:
+				r.status, r.last = matWarn, s.MS
+			default:
+				r.status, r.last = matGood, s.MS
+			}
+		}
+		rows[i] = r
+	}
+	if m.sortCol < 0 {
+		return rows
+	}
+	slices.SortStableFunc(rows, func(a, b matrixRow) int {
+		var c int
+		if m.sortCol == matHost {
+			c = cmp.Compare(a.host.Name, b.host.Name)
+		} else {
+			c = cmp.Compare(a.sortKey(m.sortCol), b.sortKey(m.sortCol))
+		}
+		if m.desc {
+			return -c
+		}
+		return c
+	})
+	return rows
+}
+
+// Refresh updates the table from the rings, touching only cells whose text
+// or status changed.
+func (m *PingMatrix) Refresh() {
+	rows := m.rows()
+	if n := len(rows); n != m.RowCount() {
+		old := m.RowCount()
+		m.SetRowCount(n)
+		for r := old; r < n; r++ {
+			for c := 0; c < matCols; c++ {
+				it := qt.NewQTableWidgetItem2("")
+				if c != matHost {
+					it.SetTextAlignment(int(qt.AlignRight | qt.AlignVCenter))
+				}
+				m.SetItem(r, c, it)
+			}
+		}
+		m.shown = make([][matCols]string, n)
+		m.status = make([]int, n)
+		for r := range m.status {
+			m.status[r] = -1
+		}
+	}
+
+	m.order = m.order[:0]
+	for r, row := range rows {
+		m.order = append(m.order, row.host)
+		cells := row.cells()
+		for c, text := range cells {
+			if m.shown[r][c] != text {
+				m.Item(r, c).SetText(text)
+			}
+		}
+		if m.shown[r][matHost] != cells[matHost] && row.host.Note != "" {
+			m.Item(r, matHost).SetToolTip(row.host.Note)
+		}
+		m.shown[r] = cells
+		if m.status[r] != row.status {
+			m.status[r] = row.status
+			dot := m.Item(r, matStatus)
+			dot.SetForeground(qt.NewQBrush3(matStatusColor(row.status)))
+			dot.SetToolTip(matStatusTips[row.status])
+		}
+	}
+}
+
+// ApplyPalette recolors the status dots after a palette change.
+func (m *PingMatrix) ApplyPalette() {
+	for r := range m.status {
+		m.status[r] = -1
+	}
+	m.Refresh()
+}
+
+// matStatusColor is the status dot's color, from the graph palette.
+func matStatusColor(status int) *qt.QColor {
+	c := []int{150, 150, 150}
+	switch status {
+	case matGood:
+		c = palette.ok
+	case matWarn:
+		c = palette.warn
+	case matDown:
+		c = palette.bad
+	}
+	return qcolor(c[0], c[1], c[2], 255)
+}
@@ -0,0 +1,187 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Opt-in Prometheus exporter (metrics.enabled). Gauges come from
+// AppModel.Report(); probe/loss counters are accumulated from the sample
+// stream so they keep growing past the ring's window. The text exposition
+// format is simple enough that we write it by hand.
+
+type probeCounts struct {
+	probes, losses uint64
+	late           uint64       // losses that turned into late replies after all
+	lostSeqs       map[int]bool // losses that may still turn into late replies
+}
+
+type MetricsServer struct {
+	model *AppModel
+	srv   *http.Server
+	unsub func()
+
+	mu     sync.Mutex
+	counts map[string]*probeCounts // by host address
+}
+
+// StartMetricsServer listens on addr and serves /metrics until Close.
+func StartMetricsServer(model *AppModel, addr string) (*MetricsServer, error) {
+	ms := &MetricsServer{model: model, counts: make(map[string]*probeCounts)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", ms.serve)
+	srv, err := serveHTTP(addr, mux, "Prometheus metrics")
+	if err != nil {
+		return nil, err
+	}
+	ms.srv = srv
+	ms.unsub = model.Subscribe(ms.count)
+	return ms, nil
+}
+
+// Close stops accepting scrapes and waits briefly for running ones.
+func (ms *MetricsServer) Close() {
+	if ms == nil {
+		return
+	}
+	ms.unsub()
+	shutdownHTTP(ms.srv)
+}
+
+// serveHTTP binds addr and serves h in the background; what names the
+// service in the log.
+func serveHTTP(addr string, h http.Handler, what string) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("%s server stopped: %s\n", what, err)
+		}
+	}()
+	log.Printf("Serving %s on http://%s\n", what, ln.Addr())
+	return srv, nil
+}
+
+// shutdownHTTP stops srv, giving in-flight requests a moment to finish.
+func shutdownHTTP(srv *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(ctx)
+}
+
+// count is the sample subscriber behind the *_total counters.
+func (ms *MetricsServer) count(h *Host, s Sample) {
+	addr := ms.model.Ident(h).Addr
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	c := ms.counts[addr]
+	if c == nil {
+		c = &probeCounts{lostSeqs: make(map[int]bool)}
+		ms.counts[addr] = c
+	}
+	switch {
+	case s.State == SampleGap:
+		// suspended: no probe was sent
+	case s.State == SampleLate && c.lostSeqs[s.Seq]:
+		// a loss reconciled into a late reply: counted apart, as counters
+		// must never go down
+		delete(c.lostSeqs, s.Seq)
+		c.late++
+	case s.State == SampleLoss:
+		c.probes++
+		c.losses++
+		if len(c.lostSeqs) > 1024 {
+			clear(c.lostSeqs)
+		}
+		c.lostSeqs[s.Seq] = true
+	default:
+		c.probes++
+	}
+}
+
+func (ms *MetricsServer) serve(w http.ResponseWriter, r *http.Request) {
+	reports := ms.model.Report()
+	totals := make(map[string][3]uint64, len(reports))
+	ms.mu.Lock()
+	for addr, c := range ms.counts {
+		totals[addr] = [3]uint64{c.probes, c.losses, c.late}
+	}
+	ms.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w, reports, totals)
+}
+
+// writeMetrics renders reports (and per-address probe, loss and late reply
+// totals) in the Prometheus text exposition format.
+func writeMetrics(w io.Writer, reports []HostReport, totals map[string][3]uint64) {
+	gauge := func(name, help string, val func(HostReport) (float64, bool)) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, r := range reports {
+			if v, ok := val(r); ok {
+				fmt.Fprintf(w, "%s{%s} %g\n", name, metricLabels(r), v)
+			}
+		}
+	}
+	replied := func(r HostReport) bool { return r.Samples > 0 && r.LossPct < 100 }
+
+	gauge("speedping_rtt_ms", "Average round-trip time over the sample window.",
+		func(r HostReport) (float64, bool) { return r.Avg, replied(r) })
+	gauge("speedping_rtt_min_ms", "Minimum round-trip time over the sample window.",
+		func(r HostReport) (float64, bool) { return r.Min, replied(r) })
+	gauge("speedping_rtt_max_ms", "Maximum round-trip time over the sample window.",
+		func(r HostReport) (float64, bool) { return r.Max, replied(r) })
+	gauge("speedping_jitter_ms", "Mean difference between consecutive round-trip times.",
+		func(r HostReport) (float64, bool) { return r.Jitter, replied(r) })
+	gauge("speedping_loss_ratio", "Share of probes lost over the sample window (0-1).",
+		func(r HostReport) (float64, bool) { return r.LossPct / 100, r.Samples > 0 })
+
+	counter := func(name, help string, i int) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, r := range reports {
+			fmt.Fprintf(w, "%s{%s} %d\n", name, metricLabels(r), totals[r.Addr][i])
+		}
+	}
+	counter("speedping_probes_total", "Echo requests sent since start.", 0)
+	counter("speedping_losses_total", "Echo requests that timed out since start.", 1)
+	counter("speedping_late_replies_total", "Timed out echo requests whose reply came in the grace window after all.", 2)
+}
+
+func metricLabels(r HostReport) string {
+	return `host="` + escapeLabel(r.Name) + `",addr="` + escapeLabel(r.Addr) + `"`
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
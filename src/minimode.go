@@ -0,0 +1,114 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import "github.com/mappu/miqt/qt"
+
+// Mini mode shrinks the window to the ping graph alone, for keeping an eye
+// on it in a corner of the screen. The full and the mini window each keep
+// their own geometry; both end up in settings.yml on exit.
+
+const miniShortcut = "Ctrl+M"
+
+// default mini window size (scaled by DPI) when none was saved yet
+const (
+	miniW = 360
+	miniH = 200
+)
+
+func windowGeometry(w *qt.QMainWindow) WindowConfig {
+	return WindowConfig{X: w.X(), Y: w.Y(), W: w.Width(), H: w.Height()}
+}
+
+func applyWindowGeometry(w *qt.QMainWindow, g WindowConfig) {
+	if g.W > 0 && g.H > 0 {
+		w.Resize(g.W, g.H)
+	}
+	if g.X != 0 || g.Y != 0 {
+		w.Move(g.X, g.Y)
+	}
+}
+
+// setMiniMode switches between the full layout and the graph-only one.
+func (ui *UI) setMiniMode(on bool) {
+	if on == ui.mini {
+		return
+	}
+	if on && ui.detached {
+		ui.setDetached(false)
+	}
+	sc := dpiScale(ui.graph.QPaintDevice)
+	if on {
+		ui.fullGeom = windowGeometry(ui.main)
+		ui.tabs.SetCurrentIndex(0) // the ping tab
+	} else {
+		ui.miniGeom = windowGeometry(ui.main)
+	}
+	ui.mini = on
+
+	ui.tabs.TabBar().SetVisible(!on)
+	ui.pingTop.SetVisible(!on)
+	ui.health.SetVisible(!on)
+	ui.heat.SetVisible(!on && ui.chkHeat.IsChecked())
+	ui.main.StatusBar().SetVisible(!on)
+	g := ui.fullGeom
+	if on {
+		ui.graph.SetMinimumSize2(int(160*sc), int(90*sc))
+		g = ui.miniGeom
+		if g.W <= 0 || g.H <= 0 {
+			g = WindowConfig{W: int(miniW * sc), H: int(miniH * sc)}
+		}
+	} else {
+		ui.graph.SetMinimumSize2(int(800*sc), int(320*sc))
+	}
+	ui.btnMini.SetChecked(on)
+
+	// resize once the layouts have caught up; until then the window's
+	// minimum size is still that of the layout being left
+	if ui.miniTimer == nil {
+		ui.miniTimer = qt.NewQTimer()
+		ui.miniTimer.SetSingleShot(true)
+		ui.miniTimer.OnTimeout(func() { applyWindowGeometry(ui.main, ui.miniNext) })
+	}
+	ui.miniNext = g
+	ui.miniTimer.Start(0)
+}
+
+// Geometry returns the full window's geometry and, separately, the mini
+// window's, whichever of the two is showing right now.
+func (ui *UI) Geometry() (full, mini WindowConfig) {
+	if ui.mini {
+		return ui.fullGeom, windowGeometry(ui.main)
+	}
+	return windowGeometry(ui.main), ui.miniGeom
+}
+
+// miniMenuAction adds the mini mode toggle to the graph's context menu,
+// the way back when everything else is hidden.
+func (ui *UI) miniMenuAction(menu *qt.QMenu) {
+	text := "Mini mode"
+	if ui.mini {
+		text = "Full window"
+	}
+	menu.AddSeparator()
+	act := menu.AddAction(text + "\t" + miniShortcut)
+	act.OnTriggered(func() { ui.setMiniMode(!ui.mini) })
+}
@@ -21,85 +21,34 @@
 package main
 
 import (
+	"slices"
 	"sync"
 	"time"
-)
 
-type SampleState int
+	"github.com/e1z0/speedping/internal/monitor"
+)
 
-const (
-	SampleOK   SampleState = iota
-	SampleLoss             // timeout -> loss
-	SampleLate             // arrived in grace window after timeout
+// Core types live in internal/monitor (Qt-free), aliased here for brevity.
+type (
+	Sample         = monitor.Sample
+	SampleState    = monitor.SampleState
+	Ring           = monitor.Ring
+	HostReport     = monitor.HostReport
+	ProbingBackend = monitor.ProbingBackend
 )
 
 const (
-	// Default number of samples retained per host (roughly ~10 minutes at 1s).
-	DefaultRingCap = 600
+	SampleOK       = monitor.SampleOK
+	SampleLoss     = monitor.SampleLoss
+	SampleLate     = monitor.SampleLate
+	SampleGap      = monitor.SampleGap
+	DefaultRingCap = monitor.DefaultRingCap
+
+	// bounds of ping.ring_capacity
+	minRingCap = 60
+	maxRingCap = 100000
 )
 
-type Sample struct {
-	T time.Time
-	// ms latency; negative for loss/timeouts
-	MS    float64
-	Seq   int         // ICMP sequence number
-	State SampleState // OK, Loss, Late
-}
-
-type Ring struct {
-	mu    sync.RWMutex
-	data  []Sample
-	head  int
-	count int
-}
-
-func NewRing(capacity int) *Ring {
-	return &Ring{data: make([]Sample, capacity)}
-}
-
-func (r *Ring) Push(s Sample) int {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if len(r.data) == 0 {
-		return -1
-	}
-	idx := r.head
-	r.data[idx] = s
-	r.head = (r.head + 1) % len(r.data)
-	if r.count < len(r.data) {
-		r.count++
-	}
-	return idx
-}
-
-func (r *Ring) UpdateAt(idx int, update func(*Sample)) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if r.count == 0 || idx < 0 || idx >= len(r.data) {
-		return
-	}
-	update(&r.data[idx])
-}
-
-func (r *Ring) Snapshot(dst []Sample) []Sample {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	if r.count == 0 {
-		return dst[:0]
-	}
-	n := r.count
-	if cap(dst) < n {
-		dst = make([]Sample, n)
-	} else {
-		dst = dst[:n]
-	}
-	start := (r.head - r.count + len(r.data)) % len(r.data)
-	for i := 0; i < n; i++ {
-		dst[i] = r.data[(start+i)%len(r.data)]
-	}
-	return dst
-}
-
 type HostState int
 
 const (
@@ -108,20 +57,49 @@ const (
 )
 
 type Host struct {
-	Name   string // display name
+	Name   string // display name; Name, Addr and Note change under AppModel.mu (see EditHost)
 	Addr   string // ip or hostname
 	ColorI int    // color index (we’ll let Qt pick default pen colors per index)
 	State  HostState
+	Note   string  // user comment (metadata only)
+	WarnMs float64 // per-host latency thresholds, 0 = use the global ones
+	BadMs  float64
+
+	IntervalMs int // per-host probing overrides, 0 = use the global ones
+	PacketSize int
+
+	IP  string // address being pinged for a host name; UI thread only
+	PTR string // reverse DNS name of the pinged address (ping.reverse_dns); UI thread only
+
+	Paused bool   // left out of pinging until resumed, not saved; UI thread only
+	Err    string // why probing gave up this session (see keepProbing); UI thread only
 
 	buf *Ring
 }
 
+// SampleFunc receives every new sample along with the host it was taken
+// of. Called from ping goroutines, so it must be quick and thread-safe.
+type SampleFunc func(h *Host, s Sample)
+
+type sampleSub struct {
+	id int
+	fn SampleFunc
+}
+
 type AppModel struct {
 	mu             sync.RWMutex
 	hosts          []*Host
 	pingIntervalMs int // current ping interval (ms)
 	cfg            *AppConfig
 	saveQ          DebouncedSaver
+
+	batchMu    sync.Mutex
+	batch      int  // nesting depth of BeginBatch
+	batchDirty bool // a save was requested while batching
+
+	subMu  sync.RWMutex
+	subs   []sampleSub
+	nextID int
 }
 
 func NewAppModel() *AppModel {
@@ -130,6 +108,19 @@ func NewAppModel() *AppModel {
 
 func (m *AppModel) Config() *AppConfig { return m.cfg }
 
+// EnsureConfig returns the config to save settings into. When settings.yml
+// could not be loaded it attaches a fresh default one, filled with the hosts
+// and interval as they are; unlike LoadFromConfig it doesn't reload them.
+func (m *AppModel) EnsureConfig() *AppConfig {
+	if m.cfg == nil {
+		c := defaultConfig()
+		c.Ping.Hosts = m.HostConfigs()
+		c.Ping.IntervalMs = m.PingIntervalMs()
+		m.cfg = c
+	}
+	return m.cfg
+}
+
 // Call on startup
 func (m *AppModel) LoadFromConfig(cfg *AppConfig) {
 	if cfg == nil {
@@ -146,7 +137,9 @@ func (m *AppModel) LoadFromConfig(cfg *AppConfig) {
 	m.ClearHosts()
 	for _, h := range cfg.Ping.Hosts {
 		if h.Enabled {
-			m.AddHost(h.Name, h.Addr, DefaultRingCap)
+			nh := m.AddHost(h.Name, h.Addr, m.RingCap())
+			nh.Note, nh.WarnMs, nh.BadMs = h.Note, h.WarnMs, h.BadMs
+			nh.IntervalMs, nh.PacketSize = h.IntervalMs, h.PacketSize
 		}
 	}
 }
@@ -156,25 +149,71 @@ func (m *AppModel) SnapshotConfig(winGeom WindowConfig) *AppConfig {
 	if m.cfg == nil {
 		m.cfg = defaultConfig()
 	}
-	// hosts snapshot
-	var hosts []HostConfig
-	for _, h := range m.Hosts() {
-		hosts = append(hosts, HostConfig{Name: h.Name, Addr: h.Addr, Enabled: true})
-	}
-
 	m.cfg.Ping.IntervalMs = m.PingIntervalMs()
-	m.cfg.Ping.Hosts = hosts
+	m.cfg.Ping.Hosts = m.HostConfigs()
 	m.cfg.Window = winGeom
 	return m.cfg
 }
 
+// HostConfigs converts the current host list to its persisted form.
+func (m *AppModel) HostConfigs() []HostConfig {
+	var hosts []HostConfig
+	for _, h := range m.Hosts() {
+		hosts = append(hosts, HostConfig{Name: h.Name, Addr: h.Addr, Enabled: true, Note: h.Note,
+			WarnMs: h.WarnMs, BadMs: h.BadMs, IntervalMs: h.IntervalMs, PacketSize: h.PacketSize})
+	}
+	return hosts
+}
+
 // Save (debounced)
 func (m *AppModel) SaveConfigAsync() {
+	if m.deferSave() {
+		return
+	}
 	m.saveQ.Trigger(400*time.Millisecond, func() {
+		// a batch may have started after the trigger; it saves on EndBatch
+		if m.deferSave() {
+			return
+		}
 		_ = SaveConfig(m.SnapshotConfig(WindowConfig{})) // window filled on close
 	})
 }
 
+// BeginBatch suppresses autosave until the matching EndBatch, so bulk edits
+// write the config once and never persist a half-applied state. Nests.
+func (m *AppModel) BeginBatch() {
+	m.batchMu.Lock()
+	m.batch++
+	m.batchMu.Unlock()
+}
+
+// EndBatch closes a batch; the outermost one saves if anything asked to.
+func (m *AppModel) EndBatch() {
+	m.batchMu.Lock()
+	if m.batch > 0 {
+		m.batch--
+	}
+	save := m.batch == 0 && m.batchDirty
+	if save {
+		m.batchDirty = false
+	}
+	m.batchMu.Unlock()
+	if save {
+		m.SaveConfigAsync()
+	}
+}
+
+// deferSave reports whether a batch is open, remembering that a save is due.
+func (m *AppModel) deferSave() bool {
+	m.batchMu.Lock()
+	defer m.batchMu.Unlock()
+	if m.batch == 0 {
+		return false
+	}
+	m.batchDirty = true
+	return true
+}
+
 func (m *AppModel) Hosts() []*Host {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -188,7 +227,7 @@ func (m *AppModel) AddHost(name, addr string, ringCap int) *Host {
 		Name:  name,
 		Addr:  addr,
 		State: HostStopped,
-		buf:   NewRing(ringCap),
+		buf:   monitor.NewRing(ringCap),
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -197,19 +236,77 @@ func (m *AppModel) AddHost(name, addr string, ringCap int) *Host {
 	return h
 }
 
-func (m *AppModel) RemoveHostAt(idx int) bool {
+// RemoveHosts removes the hosts at indices, given in any order; duplicates
+// and indices out of range are ignored. It returns the removed indices,
+// highest first, which is also the order to drop matching list rows in.
+func (m *AppModel) RemoveHosts(indices []int) []int {
+	idx := slices.Clone(indices)
+	slices.Sort(idx)
+	idx = slices.Compact(idx)
+	slices.Reverse(idx) // from the end, so lower indices stay valid
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if idx < 0 || idx >= len(m.hosts) {
-		return false
+	removed := idx[:0]
+	for _, i := range idx {
+		if i < 0 || i >= len(m.hosts) {
+			continue
+		}
+		m.hosts = append(m.hosts[:i], m.hosts[i+1:]...)
+		removed = append(removed, i)
 	}
-	// compact slice
-	m.hosts = append(m.hosts[:idx], m.hosts[idx+1:]...)
-	// reassign ColorI for stable legend order/colors
 	for i := range m.hosts {
 		m.hosts[i].ColorI = i
 	}
-	return true
+	return removed
+}
+
+// FindHost returns the first host pinging addr, or nil.
+func (m *AppModel) FindHost(addr string) *Host {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, h := range m.hosts {
+		if h.Addr == addr {
+			return h
+		}
+	}
+	return nil
+}
+
+// EditHost applies hc to h. The name, address and note are read off the UI
+// thread (sample log, metrics, reports), so they change under m.mu; read
+// them there through Ident.
+func (m *AppModel) EditHost(h *Host, hc HostConfig) {
+	m.mu.Lock()
+	h.Name, h.Addr, h.Note = hc.Name, hc.Addr, hc.Note
+	m.mu.Unlock()
+	h.WarnMs, h.BadMs = hc.WarnMs, hc.BadMs
+	h.IntervalMs, h.PacketSize = hc.IntervalMs, hc.PacketSize
+}
+
+// HostIdent is what names a host in logs, exports and reports.
+type HostIdent struct {
+	Name, Addr, Note string
+}
+
+// Ident returns h's name, address and note; safe on any goroutine.
+func (m *AppModel) Ident(h *Host) HostIdent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return h.ident()
+}
+
+// ident is Ident for the UI thread, which is the only one changing them.
+func (h *Host) ident() HostIdent { return HostIdent{Name: h.Name, Addr: h.Addr, Note: h.Note} }
+
+// HostAt returns the host at idx, or nil if out of range.
+func (m *AppModel) HostAt(idx int) *Host {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if idx < 0 || idx >= len(m.hosts) {
+		return nil
+	}
+	return m.hosts[idx]
 }
 
 func (m *AppModel) Count() int {
@@ -218,6 +315,30 @@ func (m *AppModel) Count() int {
 	return len(m.hosts)
 }
 
+// Thresholds returns the warn/bad RTT limits for h: its own when set,
+// otherwise the global ping.warn_ms/bad_ms. Zero means that level is off.
+func (m *AppModel) Thresholds(h *Host) (warn, bad float64) {
+	if m.cfg != nil {
+		warn, bad = m.cfg.Ping.WarnMs, m.cfg.Ping.BadMs
+	}
+	if h.WarnMs > 0 {
+		warn = h.WarnMs
+	}
+	if h.BadMs > 0 {
+		bad = h.BadMs
+	}
+	return warn, bad
+}
+
+// RingCap is how many samples a newly added host keeps: ping.ring_capacity,
+// or DefaultRingCap.
+func (m *AppModel) RingCap() int {
+	if m.cfg != nil && m.cfg.Ping.RingCapacity > 0 {
+		return m.cfg.Ping.RingCapacity
+	}
+	return DefaultRingCap
+}
+
 // -------- ping interval --------
 
 func (m *AppModel) SetPingIntervalMs(v int) {
@@ -246,7 +367,7 @@ func (m *AppModel) AddHostWithCap(name, addr string, ringCap int) *Host {
 		Name:  name,
 		Addr:  addr,
 		State: HostStopped,
-		buf:   NewRing(ringCap),
+		buf:   monitor.NewRing(ringCap),
 	}
 	m.mu.Lock()
 	h.ColorI = len(m.hosts)
@@ -260,3 +381,37 @@ func (m *AppModel) ClearHosts() {
 	m.hosts = m.hosts[:0]
 	m.mu.Unlock()
 }
+
+// -------- sample subscribers --------
+
+// Subscribe registers fn for every sample the ping backend produces.
+// The returned func removes the subscription (safe to call more than once).
+func (m *AppModel) Subscribe(fn SampleFunc) (unsubscribe func()) {
+	m.subMu.Lock()
+	m.nextID++
+	id := m.nextID
+	m.subs = append(m.subs, sampleSub{id: id, fn: fn})
+	m.subMu.Unlock()
+
+	return func() {
+		m.subMu.Lock()
+		defer m.subMu.Unlock()
+		for i, s := range m.subs {
+			if s.id == id {
+				m.subs = append(m.subs[:i:i], m.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify fans a sample of h out to the subscribers (ProbingBackend.Run
+// callback). They are called without subMu held, so one may unsubscribe.
+func (m *AppModel) notify(h *Host, s Sample) {
+	m.subMu.RLock()
+	subs := slices.Clone(m.subs)
+	m.subMu.RUnlock()
+	for _, sub := range subs {
+		sub.fn(h, s)
+	}
+}
@@ -0,0 +1,130 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/e1z0/speedping/internal/monitor"
+	"github.com/mappu/miqt/qt"
+	"github.com/mappu/miqt/qt/mainthread"
+)
+
+// MTU probe: when large pings to a host keep failing while small ones get
+// through, the path has a smaller MTU than the hosts on either end think
+// and something drops the "fragmentation needed" replies. The probe finds
+// the largest packet that passes with the don't-fragment bit set, the
+// number to configure on the tunnel or router in the way.
+
+// mtuProbe asks for a host and a ceiling and runs the search in the
+// background, showing the result in the dialog. Closing it stops the search.
+func (ui *UI) mtuProbe() {
+	hosts := ui.model.Hosts()
+	if len(hosts) == 0 {
+		return
+	}
+	dlg := qt.NewQDialog(ui.main.QWidget)
+	dlg.SetWindowTitle("MTU probe")
+	form := qt.NewQFormLayout(nil)
+	dlg.SetLayout(form.QLayout)
+
+	hostCombo := qt.NewQComboBox(nil)
+	for _, h := range hosts {
+		hostCombo.AddItem(h.Name + " (" + h.Addr + ")")
+	}
+	if row := ui.hostList.CurrentRow(); row >= 0 && row < len(hosts) {
+		hostCombo.SetCurrentIndex(row)
+	}
+	ceiling := qt.NewQSpinBox(nil)
+	ceiling.SetRange(576, monitor.MTUMax)
+	ceiling.SetValue(monitor.MTUDefault)
+	ceiling.SetSuffix(" bytes")
+	ceiling.SetToolTip("Largest packet to try; raise it to check jumbo frames")
+	result := qt.NewQLabel3("Finds the largest ping that gets through without being fragmented.")
+	result.SetWordWrap(true)
+	result.SetTextInteractionFlags(qt.TextSelectableByMouse)
+
+	form.AddRow3("Host:", hostCombo.QWidget)
+	form.AddRow3("Up to:", ceiling.QWidget)
+	form.AddRow(nil, result.QWidget)
+
+	buttons := qt.NewQDialogButtonBox4(qt.QDialogButtonBox__Close)
+	btnRun := buttons.AddButton2("Probe", qt.QDialogButtonBox__ActionRole)
+	buttons.OnRejected(func() { dlg.Reject() })
+	form.AddRow(nil, buttons.QWidget)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	closed := false
+	btnRun.OnClicked(func() {
+		h := hosts[max(hostCombo.CurrentIndex(), 0)]
+		top := ceiling.Value()
+		btnRun.SetEnabled(false)
+		result.SetStyleSheet("")
+		result.SetText(fmt.Sprintf("Probing %s…", h.Addr))
+		pb := ProbingBackend{MaxRTT: time.Second, Source: ui.pingSource()}
+		go func() {
+			r, err := pb.PathMTU(ctx, h.Addr, top)
+			mainthread.Wait(func() {
+				if closed {
+					return
+				}
+				btnRun.SetEnabled(true)
+				msg, lvl := mtuText(h.Name, r, err)
+				log.Printf("MTU probe: %s\n", msg)
+				result.SetText(msg)
+				result.SetStyleSheet(diagCSS(lvl))
+				ui.main.StatusBar().ShowMessage2("MTU probe: "+msg, 30000)
+			})
+		}()
+	})
+
+	dlg.Exec()
+	closed = true
+	cancel()
+}
+
+// mtuText words a PathMTU outcome, e.g. "router (192.168.1.1): path MTU
+// 1492 bytes (1464 bytes of ping payload), 1500 didn't get through".
+func mtuText(name string, r monitor.MTUResult, err error) (string, diagLevel) {
+	who := name
+	if r.Addr != "" && r.Addr != name {
+		who += " (" + r.Addr + ")"
+	}
+	switch {
+	case errors.Is(err, monitor.ErrMTUUnreachable):
+		return who + ": " + err.Error() + "; check that it answers pings at all.", diagBad
+	case err != nil:
+		return who + ": " + err.Error(), diagWarn
+	case r.AtLimit:
+		return fmt.Sprintf("%s: %d byte packets get through unfragmented; the path MTU is at least that (%d probes in %s s).",
+			who, r.MTU, r.Probes, formatFloat(r.Took.Seconds(), 1)), diagGood
+	}
+	lvl := diagInfo
+	if r.MTU < monitor.MTUDefault {
+		lvl = diagWarn
+	}
+	return fmt.Sprintf("%s: path MTU %d bytes (%d bytes of ping payload); larger packets with don't-fragment set are lost (%d probes in %s s).",
+		who, r.MTU, r.MTU-r.Overhead(), r.Probes, formatFloat(r.Took.Seconds(), 1)), lvl
+}
@@ -0,0 +1,97 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// Pausing one host stops just its prober, leaving the others and the
+// settings alone. The graph gets a gap where the host was paused. Paused
+// hosts stay paused across restarts until resumed or the app quits.
+
+// setPaused pauses or resumes the hosts; while pinging, their probers are
+// stopped or started right away.
+func (ui *UI) setPaused(hosts []*Host, on bool) {
+	for row, h := range ui.model.Hosts() {
+		if h.Paused == on || !slices.Contains(hosts, h) {
+			continue
+		}
+		h.Paused = on
+		if ui.running {
+			if on {
+				if r := ui.hostRuns[h]; r != nil {
+					r.cancel()
+				}
+				gap := Sample{T: time.Now(), MS: -1, State: SampleGap}
+				h.buf.Push(gap)
+				ui.model.notify(h, gap)
+			} else {
+				ui.startHost(h)
+			}
+		}
+		ui.syncHostItem(row, h)
+	}
+	ui.graph.Update()
+}
+
+// hostListMenu offers pausing or resuming the selected hosts (or the one
+// under the cursor) and editing the one under the cursor.
+func (ui *UI) hostListMenu(pos *qt.QPoint) {
+	it := ui.hostList.ItemAt(pos)
+	if it == nil {
+		return
+	}
+	if !it.IsSelected() {
+		ui.hostList.SetCurrentItem(it)
+	}
+	var sel []*Host
+	paused := 0
+	for _, s := range ui.hostList.SelectedItems() {
+		if h := ui.model.HostAt(ui.hostList.Row(s)); h != nil {
+			sel = append(sel, h)
+			if h.Paused {
+				paused++
+			}
+		}
+	}
+	if len(sel) == 0 {
+		return
+	}
+
+	menu := qt.NewQMenu(ui.hostList.QWidget)
+	if paused < len(sel) {
+		act := menu.AddAction("Pause")
+		act.OnTriggered(func() { ui.setPaused(sel, true) })
+	}
+	if paused > 0 {
+		act := menu.AddAction("Resume")
+		act.OnTriggered(func() { ui.setPaused(sel, false) })
+	}
+	row := ui.hostList.Row(it)
+	menu.AddSeparator()
+	edit := menu.AddAction("Edit…")
+	edit.OnTriggered(func() { ui.editHost(row) })
+	menu.Exec3(ui.hostList.Viewport().MapToGlobal(pos), nil)
+}
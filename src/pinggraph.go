@@ -22,11 +22,22 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/e1z0/speedping/internal/monitor"
 	"github.com/mappu/miqt/qt"
 )
 
+// graphView remembers where the plot was drawn so mouse positions can be
+// mapped back to time/latency outside of paint().
+type graphView struct {
+	ok                       bool
+	left, right, top, bottom float64
+	start, end               time.Time
+	yMin, yMax               float64
+}
+
 type GraphWidget struct {
 	qt.QWidget
 
@@ -34,21 +45,82 @@ type GraphWidget struct {
 	timeSpan  time.Duration
 	marginPx  float64
 	frameRate int
+	showLoss  bool      // per-host loss % badges in the top-right corner
+	showWorst bool      // callouts at the slowest reply and longest loss run
+	flapAt    int       // transitions per window that mark a host flapping (0 = off)
+	frozenAt  time.Time // when set, the window ends here instead of now (loaded history)
+	lossStrip int       // height (px at 96 DPI) of the loss strip under the plot; 0 = top ticks
+
+	connectLoss bool    // draw losses at lossMs instead of breaking the line
+	lossMs      float64 // 0 = on the time axis
+
+	// ghost overlay: a saved session drawn faded behind the live series,
+	// shifted so its first sample lines up with ghostAnchor (elapsed time)
+	ghost       []ghostSeries
+	ghostLabel  string
+	ghostOn     bool
+	ghostAnchor time.Time // zero: the earliest live sample
+
+	markers   []Marker // user annotations, oldest first
+	markerPen *qt.QPen
 
 	ticker      *qt.QTimer
 	mouseX      int
 	mouseInside bool
+
+	view graphView // layout of the last painted frame (for mouse → data mapping)
+
+	// per-host sample buffers reused between frames (see snapshotHosts)
+	snaps monitor.FrameBuffers
+	rings []*Ring
+
+	// paint resources that never change, created once
+	seriesCols []*qt.QColor
+	seriesPens []*qt.QPen
+	latePen    *qt.QPen
+	gapPen     *qt.QPen   // suspend/resume marker
+	warnCol    *qt.QColor // threshold colors (see rttLevelOf)
+	badCol     *qt.QColor
+	warnPen    *qt.QPen
+	badPen     *qt.QPen
+	okCol      *qt.QColor // loss strip: reply
+	ghostPens  []*qt.QPen // faded, dashed seriesPens
+	tipBg      *qt.QColor
+	tipFg      *qt.QColor
+
+	menuExtra func(menu *qt.QMenu) // appends to the context menu, may be nil
+
+	focus      *Host // drawn thicker until focusUntil, see FocusHost
+	focusUntil time.Time
 }
 
+// hostFocusFor is how long FocusHost's emphasis lasts.
+const hostFocusFor = 3 * time.Second
+
 func NewGraphWidget(model *AppModel) *GraphWidget {
 	g := &GraphWidget{}
 	g.QWidget = *qt.NewQWidget(nil)
-	g.SetMinimumSize2(800, 320)
+	sc := dpiScale(g.QPaintDevice)
+	g.SetMinimumSize2(int(800*sc), int(320*sc))
 
 	g.model = model
 	g.timeSpan = 60 * time.Second
 	g.marginPx = 40
 	g.frameRate = 30
+	g.showLoss = true
+
+	// static colors/pens: late marker and tooltip here, the palette's in ApplyPalette
+	g.ApplyPalette()
+	g.latePen = qt.NewQPen3(qcolor(255, 0, 0, 255))
+	g.latePen.SetCosmetic(true)
+	g.latePen.SetWidthF(1.5)
+	g.gapPen = qt.NewQPen3(qcolor(150, 150, 150, 200))
+	g.gapPen.SetCosmetic(true)
+	g.gapPen.SetStyle(qt.DotLine)
+	g.tipFg = qcolor(255, 255, 255, 220)
+	g.markerPen = qt.NewQPen()
+	g.markerPen.SetCosmetic(true)
+	g.markerPen.SetStyle(qt.DashDotLine)
 
 	// enable hover
 	g.SetMouseTracking(true)
@@ -62,24 +134,599 @@ func NewGraphWidget(model *AppModel) *GraphWidget {
 	g.OnPaintEvent(func(super func(*qt.QPaintEvent), e *qt.QPaintEvent) {
 		g.paint()
 	})
+	g.OnContextMenuEvent(func(super func(*qt.QContextMenuEvent), e *qt.QContextMenuEvent) {
+		g.showContextMenu(e)
+	})
+	// Ctrl+C once the graph was clicked
+	g.SetFocusPolicy(qt.ClickFocus)
+	copyKey := qt.NewQShortcut2(qt.NewQKeySequence2(copyGraphShortcut), &g.QWidget)
+	copyKey.SetContext(qt.WidgetShortcut)
+	copyKey.OnActivated(func() { g.CopyImage() })
 	return g
 }
 
+// showContextMenu offers copying the reading(s) under the cursor.
+func (g *GraphWidget) showContextMenu(e *qt.QContextMenuEvent) {
+	v := g.view
+	x, y := float64(e.X()), float64(e.Y())
+	inPlot := v.ok && x >= v.left && x <= v.right
+	if !inPlot && g.menuExtra == nil {
+		return
+	}
+
+	menu := qt.NewQMenu(&g.QWidget)
+	if inPlot {
+		t := unmapX(x, v.start, v.end, v.left, v.right)
+		one := menu.AddAction("Copy value")
+		all := menu.AddAction("Copy all at this time")
+		one.OnTriggered(func() { copyToClipboard(g.readingAt(t, y)) })
+		all.OnTriggered(func() { copyToClipboard(g.readingsAt(t)) })
+	}
+	img := menu.AddAction("Copy graph image\t" + copyGraphShortcut)
+	img.OnTriggered(func() { g.CopyImage() })
+	if g.menuExtra != nil {
+		g.menuExtra(menu)
+	}
+	menu.Exec3(e.GlobalPos(), nil)
+}
+
+const copyGraphShortcut = "Ctrl+C"
+
+// CopyImage puts the graph, as shown and at the screen's pixel density, on
+// the clipboard.
+func (g *GraphWidget) CopyImage() {
+	img := g.RenderImage(g.Width(), g.Height(), g.DevicePixelRatioF())
+	if img == nil {
+		return
+	}
+	qt.QGuiApplication_Clipboard().SetImage(img)
+}
+
+// SetMenuExtra lets the owner add entries to the graph's context menu.
+func (g *GraphWidget) SetMenuExtra(fn func(menu *qt.QMenu)) { g.menuExtra = fn }
+
+// readingAt describes the sample nearest to (t, y): the host whose nearest
+// sample in time lies closest to the cursor vertically.
+func (g *GraphWidget) readingAt(t time.Time, y float64) string {
+	v := g.view
+	hosts := g.model.Hosts()
+	snaps := g.snapshotHosts(hosts)
+	bestI, bestDY := -1, 0.0
+	var best Sample
+	for i := range hosts {
+		s, ok := nearestSample(snaps[i], t)
+		if !ok {
+			continue
+		}
+		sy := v.top // losses are marked along the top edge
+		if s.MS >= 0 {
+			sy = mapY(s.MS, v.yMin, v.yMax, v.top, v.bottom)
+		}
+		dy := sy - y
+		if dy < 0 {
+			dy = -dy
+		}
+		if bestI < 0 || dy < bestDY {
+			bestI, bestDY, best = i, dy, s
+		}
+	}
+	if bestI < 0 {
+		return ""
+	}
+	h := hosts[bestI]
+	return fmt.Sprintf("%s (%s) %s: %s", h.Name, h.Addr, best.T.Format("2006-01-02 15:04:05.000"), readingText(best))
+}
+
+// readingsAt lists every host's nearest sample at t.
+func (g *GraphWidget) readingsAt(t time.Time) string {
+	hosts := g.model.Hosts()
+	snaps := g.snapshotHosts(hosts)
+	var b strings.Builder
+	b.WriteString(t.Format("2006-01-02 15:04:05"))
+	for i, h := range hosts {
+		s, ok := nearestSample(snaps[i], t)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s (%s): %s", h.Name, h.Addr, readingText(s))
+	}
+	return b.String()
+}
+
+func readingText(s Sample) string {
+	switch {
+	case s.State == SampleGap:
+		return "gap (suspended)"
+	case s.MS < 0:
+		return "loss"
+	case s.State == SampleLate:
+		return formatFloat(s.MS, valuePrec(1)) + " ms (late)"
+	}
+	return formatFloat(s.MS, valuePrec(1)) + " ms"
+}
+
+// nearestSample returns the sample closest in time to t.
+func nearestSample(samples []Sample, t time.Time) (Sample, bool) {
+	best := Sample{}
+	bestDT := time.Duration(1<<62 - 1)
+	for _, s := range samples {
+		dt := s.T.Sub(t)
+		if dt < 0 {
+			dt = -dt
+		}
+		if dt < bestDT {
+			bestDT = dt
+			best = s
+		}
+	}
+	return best, len(samples) > 0
+}
+
 func (g *GraphWidget) StartTicker() {
 	g.ticker = qt.NewQTimer()
 	g.ticker.OnTimeout(func() { g.Update() })
-	ms := g.frameRateToMs()
-	if ms <= 0 {
-		ms = 33
+	g.ticker.Start(frameRateToMs(g.frameRate))
+}
+
+// SetFrameRate changes the repaint rate, live if the ticker already runs.
+func (g *GraphWidget) SetFrameRate(fps int) {
+	g.frameRate = fps
+	if g.ticker != nil {
+		g.ticker.SetInterval(frameRateToMs(fps))
+	}
+}
+
+// ApplyConfig sets every view toggle from c at once (graph: in settings.yml).
+func (g *GraphWidget) ApplyConfig(c GraphConfig) {
+	g.showLoss, g.showWorst, g.flapAt = c.ShowLoss, c.ShowWorst, c.FlapThreshold
+	g.connectLoss, g.lossMs = c.ConnectLoss, c.LossMs
+	g.lossStrip = 0
+	if c.LossStrip {
+		g.lossStrip = c.LossStripPx
+		if g.lossStrip <= 0 {
+			g.lossStrip = defaultConfig().Graph.LossStripPx
+		}
+	}
+	g.Update()
+}
+
+func (g *GraphWidget) SetShowLoss(on bool) { g.showLoss = on; g.Update() }
+
+func (g *GraphWidget) SetShowWorst(on bool) { g.showWorst = on; g.Update() }
+
+func (g *GraphWidget) SetFlapThreshold(n int) { g.flapAt = n; g.Update() }
+
+// SetConnectLoss draws lost probes as points at ms (0 = the time axis,
+// above the plot = its top) so the line dips through them, or breaks the
+// line at them when off.
+func (g *GraphWidget) SetConnectLoss(on bool, ms float64) {
+	g.connectLoss, g.lossMs = on, ms
+	g.Update()
+}
+
+// FocusHost draws h's line thicker for a few seconds so it can be found
+// among the others.
+func (g *GraphWidget) FocusHost(h *Host) {
+	g.focus, g.focusUntil = h, time.Now().Add(hostFocusFor)
+	g.Update()
+}
+
+func (g *GraphWidget) TimeSpan() time.Duration { return g.timeSpan }
+
+// SetLossStrip shows loss as a strip of px (per host, or aggregated when
+// there are many hosts) under the plot instead of ticks; 0 hides it.
+func (g *GraphWidget) SetLossStrip(px int) { g.lossStrip = px; g.Update() }
+
+// FreezeAt pins the right edge of the graph to t; zero resumes following now.
+func (g *GraphWidget) FreezeAt(t time.Time) { g.frozenAt = t; g.Update() }
+
+func (g *GraphWidget) SetTimeSpan(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	g.timeSpan = d
+	g.Update()
+}
+
+// gridStep is the spacing of the vertical time grid.
+const gridStep = 10 * time.Second
+
+// above this many hosts the loss strip collapses into one worst-case row
+const maxStripRows = 4
+
+type rttLevel int
+
+const (
+	levelGood rttLevel = iota
+	levelWarn
+	levelBad
+)
+
+// rttLevelOf classifies a reply against the warn/bad thresholds (0 = off).
+func rttLevelOf(ms, warn, bad float64) rttLevel {
+	switch {
+	case bad > 0 && ms >= bad:
+		return levelBad
+	case warn > 0 && ms >= warn:
+		return levelWarn
+	}
+	return levelGood
+}
+
+// ApplyPalette rebuilds the series and state colors from the active palette
+// and contrast setting (see setPalette).
+func (g *GraphWidget) ApplyPalette() {
+	g.seriesCols, g.seriesPens, g.ghostPens = nil, nil, nil
+	for i := range palette.series {
+		col := seriesColor(i)
+		pen := qt.NewQPen3(col)
+		pen.SetCosmetic(true)
+		pen.SetWidthF(2.0)
+		g.seriesCols = append(g.seriesCols, col)
+		g.seriesPens = append(g.seriesPens, pen)
+		gp := qt.NewQPen3(qcolor(col.Red(), col.Green(), col.Blue(), contrastAlpha(110)))
+		gp.SetCosmetic(true)
+		gp.SetStyle(qt.DashLine)
+		g.ghostPens = append(g.ghostPens, gp)
+	}
+	g.warnCol = stateColor(palette.warn, 255)
+	g.badCol = stateColor(palette.bad, 255)
+	g.warnPen = qt.NewQPen3(g.warnCol)
+	g.warnPen.SetCosmetic(true)
+	g.badPen = qt.NewQPen3(g.badCol)
+	g.badPen.SetCosmetic(true)
+	g.okCol = stateColor(palette.ok, 255)
+	g.tipBg = qcolor(0, 0, 0, contrastAlpha(160))
+	g.Update()
+}
+
+// levelPen returns the pen for lv, or base (the series pen) when good.
+func (g *GraphWidget) levelPen(lv rttLevel, base *qt.QPen) *qt.QPen {
+	switch lv {
+	case levelWarn:
+		return g.warnPen
+	case levelBad:
+		return g.badPen
+	}
+	return base
+}
+
+// snapshotHosts copies every host's ring into a scratch buffer that is kept
+// across frames, so steady-state painting doesn't allocate per host.
+// The returned slice is index-aligned with hosts.
+func (g *GraphWidget) snapshotHosts(hosts []*Host) [][]Sample {
+	g.rings = g.rings[:0]
+	for _, h := range hosts {
+		g.rings = append(g.rings, h.buf)
+	}
+	return g.snaps.Snapshot(g.rings)
+}
+
+type ghostSeries struct {
+	addr    string
+	samples []Sample // oldest first
+}
+
+// SetOverlay shows the history of hosts (a saved session) as a ghost behind
+// the live series, labelled label in the legend.
+func (g *GraphWidget) SetOverlay(label string, hosts []SessionHost) {
+	g.ghost = g.ghost[:0]
+	for _, sh := range hosts {
+		if len(sh.History) > 0 {
+			g.ghost = append(g.ghost, ghostSeries{addr: sh.Addr, samples: sh.History})
+		}
+	}
+	g.ghostLabel = label
+	g.ghostOn = len(g.ghost) > 0
+	g.Update()
+}
+
+func anySamples(snaps [][]Sample) bool {
+	for _, tmp := range snaps {
+		if len(tmp) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// paintWorst calls out the slowest reply and the longest loss run in the
+// window across all hosts; ties go to the most recent. The caller clips.
+func (g *GraphWidget) paintWorst(p *qt.QPainter, fm *qt.QFontMetricsF, hosts []*Host, snaps [][]Sample, startT, now time.Time, v graphView, sc float64) {
+	slowI, runI := -1, -1
+	var slow, run monitor.Worst
+	for i := range hosts {
+		w := monitor.WorstIn(snaps[i], startT, now)
+		if w.HasSlowest && (slowI < 0 || w.Slowest.MS > slow.Slowest.MS ||
+			w.Slowest.MS == slow.Slowest.MS && w.Slowest.T.After(slow.Slowest.T)) {
+			slowI, slow = i, w
+		}
+		if w.RunLen > 0 && (runI < 0 || w.RunLen > run.RunLen ||
+			w.RunLen == run.RunLen && w.RunEnd.After(run.RunEnd)) {
+			runI, run = i, w
+		}
+	}
+
+	callout := func(x, y float64, text string, col *qt.QColor) {
+		tw := fm.Width(text) + 8
+		tx, ty := x+6*sc, y-fm.Height()-10*sc
+		if tx+tw > v.right {
+			tx = x - 6*sc - tw
+		}
+		if ty < v.top {
+			ty = y + 6*sc
+		}
+		p.FillRect4(qt.NewQRectF4(tx, ty, tw, fm.Height()+4), g.tipBg)
+		p.SetPen(col)
+		p.DrawStaticText2(qt.NewQPoint2(int(tx+4), int(ty+2)), qt.NewQStaticText2(text))
+	}
+
+	if slowI >= 0 {
+		s := slow.Slowest
+		x := mapX(s.T, startT, now, v.left, v.right)
+		y := mapY(s.MS, v.yMin, v.yMax, v.top, v.bottom)
+		r := 5 * sc
+		pen := qt.NewQPen3(g.tipFg)
+		pen.SetCosmetic(true)
+		pen.SetWidthF(lineWidth(1.5 * sc))
+		p.SetPenWithPen(pen)
+		p.SetBrush(qt.NewQBrush())
+		p.DrawEllipse(qt.NewQRectF4(x-r, y-r, 2*r, 2*r))
+		callout(x, y, "max "+formatFloat(s.MS, valuePrec(0))+" ms · "+hosts[slowI].Name, g.tipFg)
+	}
+	if runI >= 0 {
+		// a bar along the top edge spanning the run, one interval wide at least
+		step := time.Duration(g.model.PingIntervalMs()) * time.Millisecond
+		x0 := mapX(run.RunStart, startT, now, v.left, v.right)
+		x1 := mapX(run.RunEnd.Add(step), startT, now, v.left, v.right)
+		y := v.top + 2*sc
+		p.FillRect4(qt.NewQRectF4(x0, y, maxf(x1-x0, 2*sc), 4*sc), g.badCol)
+		callout((x0+x1)/2, y+4*sc+fm.Height()+14*sc, fmt.Sprintf("%d lost in a row · %s", run.RunLen, hosts[runI].Name), g.badCol)
+	}
+}
+
+// Marker is a labelled point in time drawn as a vertical line on the graph,
+// e.g. "rebooted router".
+type Marker struct {
+	T     time.Time `json:"t"`
+	Label string    `json:"label"`
+}
+
+// AddMarker annotates the graph at t.
+func (g *GraphWidget) AddMarker(t time.Time, label string) {
+	g.markers = append(g.markers, Marker{T: t, Label: label})
+	g.Update()
+}
+
+// SetMarkers replaces all markers (nil clears them).
+func (g *GraphWidget) SetMarkers(ms []Marker) {
+	g.markers = append(g.markers[:0], ms...)
+	g.Update()
+}
+
+func (g *GraphWidget) Markers() []Marker { return g.markers }
+
+// legendMaxFrac is how much of the plot's height a legend column may take
+// before the legend wraps into another column.
+const legendMaxFrac = 0.5
+
+// legendFullMax is the most hosts listed with address, PTR and note; with
+// more, entries show the name only so that the columns stay narrow.
+const legendFullMax = 6
+
+type legendEntry struct {
+	text string
+	col  *qt.QColor // chip color; nil for entries without a chip
+	bad  bool       // drawn in the "bad" color (flapping)
+}
+
+// paintLegend lists the hosts (and extra, when set, on a line of its own)
+// in the plot's top-left corner, on a backdrop so the series underneath
+// don't run through the text. Entries run down columns of at most
+// legendMaxFrac of the plot's height; what doesn't fit left of maxX is
+// counted in a last "+N more" entry, which also warns of flapping hosts
+// among them.
+func (g *GraphWidget) paintLegend(p *qt.QPainter, fm *qt.QFontMetricsF, txt, bg *qt.QColor, hosts []*Host, snaps [][]Sample, startT time.Time, extra string, v graphView, maxX, sc float64) {
+	entries := make([]legendEntry, 0, len(hosts)+1)
+	for i, host := range hosts {
+		e := legendEntry{text: host.Name, col: g.seriesCols[i%len(g.seriesCols)]}
+		if len(hosts) <= legendFullMax {
+			e.text += " (" + host.Addr + ")"
+			if host.PTR != "" {
+				e.text += " · " + host.PTR
+			}
+			if host.Note != "" {
+				e.text += " — " + host.Note
+			}
+		}
+		if g.flapAt > 0 {
+			if n := monitor.FlapCount(snaps[i], startT); n >= g.flapAt {
+				e.text += fmt.Sprintf("  ⚠ flapping (%d×)", n)
+				e.bad = true
+			}
+		}
+		entries = append(entries, e)
+	}
+	if extra != "" {
+		entries = append(entries, legendEntry{text: extra})
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	rowH := fm.Height() + 4
+	chipW, chipH := 12*sc, 10*sc
+	pad, colGap := 4*sc, 14*sc
+	entryW := func(e legendEntry) float64 {
+		if e.col == nil {
+			return fm.Width(e.text)
+		}
+		return chipW + 6 + fm.Width(e.text)
+	}
+	rows := max(1, min(int((v.bottom-v.top)*legendMaxFrac/rowH), len(entries)))
+
+	// lay the columns out left to right while they fit
+	type legendCol struct {
+		from, to int
+		w        float64
+	}
+	var cols []legendCol
+	x := v.left + pad
+	for from := 0; from < len(entries); from += rows {
+		c := legendCol{from: from, to: min(from+rows, len(entries))}
+		for _, e := range entries[c.from:c.to] {
+			c.w = maxf(c.w, entryW(e))
+		}
+		if len(cols) > 0 && x+c.w > maxX-pad {
+			break
+		}
+		cols = append(cols, c)
+		x += c.w + colGap
+	}
+	if last := &cols[len(cols)-1]; last.to < len(entries) {
+		more := legendEntry{text: fmt.Sprintf("+%d more", len(entries)-last.to+1)}
+		flapping := 0
+		for _, e := range entries[last.to-1:] {
+			if e.bad {
+				flapping++
+			}
+		}
+		if flapping > 0 {
+			more.text += fmt.Sprintf("  ⚠ %d flapping", flapping)
+			more.bad = true
+		}
+		last.to--
+		entries[last.to] = more
+		last.to++
+		x += maxf(entryW(more)-last.w, 0)
+		last.w = maxf(last.w, entryW(more))
+	}
+
+	backdrop := qcolor(bg.Red(), bg.Green(), bg.Blue(), 200)
+	p.FillRect4(qt.NewQRectF4(v.left+pad/2, v.top, x-colGap-v.left, float64(cols[0].to-cols[0].from)*rowH+2), backdrop)
+	x = v.left + pad
+	for _, c := range cols {
+		for i, e := range entries[c.from:c.to] {
+			y := v.top + 2 + float64(i)*rowH
+			tx := x
+			if e.col != nil {
+				p.FillRect4(qt.NewQRectF4(x, y+(fm.Height()-chipH)/2, chipW, chipH), e.col)
+				tx += chipW + 6
+			}
+			p.SetPen(txt)
+			if e.bad {
+				p.SetPen(g.badCol)
+			}
+			p.DrawStaticText2(qt.NewQPoint2(int(tx), int(y)), qt.NewQStaticText2(e.text))
+		}
+		x += c.w + colGap
+	}
+}
+
+// paintMarkers draws the markers inside the window as dash-dotted lines
+// with their label at the top. The caller clips to the plot.
+func (g *GraphWidget) paintMarkers(p *qt.QPainter, fm *qt.QFontMetricsF, txt *qt.QColor, startT, now time.Time, v graphView, sc float64) {
+	if len(g.markers) == 0 {
+		return
+	}
+	g.markerPen.SetColor(qcolor(txt.Red(), txt.Green(), txt.Blue(), contrastAlpha(150)))
+	g.markerPen.SetWidthF(lineWidth(1.2 * sc))
+	for _, m := range g.markers {
+		if m.T.Before(startT) || m.T.After(now) {
+			continue
+		}
+		x := mapX(m.T, startT, now, v.left, v.right)
+		p.SetPenWithPen(g.markerPen)
+		line := qt.NewQPainterPath2(qt.NewQPointF3(x, v.top))
+		line.LineTo(qt.NewQPointF3(x, v.bottom))
+		p.DrawPath(line)
+		if m.Label == "" {
+			continue
+		}
+		// label just right of the line, or left of it near the right edge
+		lw := fm.Width(m.Label) + 8
+		lx := x + 3
+		if lx+lw > v.right {
+			lx = x - 3 - lw
+		}
+		ly := v.bottom - fm.Height() - 8*sc
+		p.FillRect4(qt.NewQRectF4(lx, ly, lw, fm.Height()+4), g.tipBg)
+		p.SetPen(g.tipFg)
+		p.DrawStaticText2(qt.NewQPoint2(int(lx+4), int(ly+2)), qt.NewQStaticText2(m.Label))
 	}
-	g.ticker.Start(ms)
 }
 
-func (g *GraphWidget) frameRateToMs() int {
-	if g.frameRate <= 0 {
-		return 33
+// HasOverlay reports whether an overlay with data is loaded.
+func (g *GraphWidget) HasOverlay() bool { return len(g.ghost) > 0 }
+
+func (g *GraphWidget) SetOverlayVisible(on bool) {
+	g.ghostOn = on
+	g.Update()
+}
+
+// AnchorOverlay makes the overlay's first sample line up with t, normally
+// the moment pinging was started.
+func (g *GraphWidget) AnchorOverlay(t time.Time) { g.ghostAnchor = t }
+
+// ghostShift is what to add to overlay times to put them on the live axis.
+func (g *GraphWidget) ghostShift(snaps [][]Sample, fallback time.Time) time.Duration {
+	var first time.Time
+	for _, gs := range g.ghost {
+		if t := gs.samples[0].T; first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+	anchor := g.ghostAnchor
+	if anchor.IsZero() {
+		for _, tmp := range snaps {
+			if len(tmp) > 0 && (anchor.IsZero() || tmp[0].T.Before(anchor)) {
+				anchor = tmp[0].T
+			}
+		}
+	}
+	if anchor.IsZero() {
+		anchor = fallback
+	}
+	return anchor.Sub(first)
+}
+
+// paintGhost draws the overlay lines (replies only, broken at losses).
+func (g *GraphWidget) paintGhost(p *qt.QPainter, hosts []*Host, shift time.Duration, startT, now time.Time, v graphView, sc float64) {
+	for gi, gs := range g.ghost {
+		ci := gi // same color as the live host with this address, if any
+		for i, h := range hosts {
+			if h.Addr == gs.addr {
+				ci = i
+				break
+			}
+		}
+		pen := g.ghostPens[ci%len(g.ghostPens)]
+		pen.SetWidthF(lineWidth(1.5 * sc))
+		p.SetPenWithPen(pen)
+		var path *qt.QPainterPath
+		for _, s := range gs.samples {
+			t := s.T.Add(shift)
+			if t.Before(startT) || t.After(now) {
+				continue
+			}
+			if s.MS < 0 {
+				if path != nil {
+					p.DrawPath(path)
+					path = nil
+				}
+				continue
+			}
+			pt := qt.NewQPointF3(mapX(t, startT, now, v.left, v.right), mapY(s.MS, v.yMin, v.yMax, v.top, v.bottom))
+			if path == nil {
+				path = qt.NewQPainterPath2(pt)
+			} else {
+				path.LineTo(pt)
+			}
+		}
+		if path != nil {
+			p.DrawPath(path)
+		}
 	}
-	return int(1000 / g.frameRate)
 }
 
 func (g *GraphWidget) paint() {
@@ -94,6 +741,31 @@ func (g *GraphWidget) paint() {
 		return
 	}
 	defer p.End()
+	g.render(p, w, h, dpiScale(g.QPaintDevice), g.mouseInside)
+}
+
+// RenderImage draws the graph as it stands into a new w×h image, without
+// the hover crosshair, e.g. for exporting it as PNG. dpr is the device pixel
+// ratio: the image holds w*dpr × h*dpr pixels, laid out like the w×h widget.
+func (g *GraphWidget) RenderImage(w, h int, dpr float64) *qt.QImage {
+	dpr = maxf(dpr, 1)
+	img := qt.NewQImage3(int(float64(w)*dpr), int(float64(h)*dpr), qt.QImage__Format_ARGB32)
+	img.SetDevicePixelRatio(dpr)
+	p := qt.NewQPainter()
+	if !p.Begin(img.QPaintDevice) {
+		return nil
+	}
+	view := g.view // the on-screen layout, for mouse mapping
+	g.render(p, float64(w), float64(h), dpiScale(g.QPaintDevice), false)
+	g.view = view
+	p.End()
+	return img
+}
+
+// render paints a w×h frame with p; hover adds the crosshair and readout at
+// the mouse position.
+func (g *GraphWidget) render(p *qt.QPainter, w, h, sc float64, hover bool) {
+	margin := g.marginPx * sc
 
 	// High quality lines
 	p.SetRenderHint2(qt.QPainter__Antialiasing, true)
@@ -101,24 +773,54 @@ func (g *GraphWidget) paint() {
 	bg := g.Palette().ColorWithCr(qt.QPalette__Window)
 	txt := g.Palette().ColorWithCr(qt.QPalette__WindowText)
 	gridCol := qt.NewQColor()
-	gridCol.SetRgb2(txt.Red(), txt.Green(), txt.Blue(), 80)
+	gridCol.SetRgb2(txt.Red(), txt.Green(), txt.Blue(), contrastAlpha(80))
 
 	p.FillRect4(qt.NewQRectF4(0, 0, w, h), bg)
 
 	now := time.Now()
+	if !g.frozenAt.IsZero() {
+		now = g.frozenAt
+	}
 	startT := now.Add(-g.timeSpan)
 
+	// one snapshot per host per frame, shared by all passes below
+	hosts := g.model.Hosts()
+	snaps := g.snapshotHosts(hosts)
+
+	showGhost := g.ghostOn && len(g.ghost) > 0
+	if !showGhost && !anySamples(snaps) {
+		g.view = graphView{}
+		msg := noDataText
+		if len(hosts) == 0 {
+			msg = "No hosts to ping yet."
+		}
+		paintNoData(p, w, h, txt, msg)
+		return
+	}
+	var shift time.Duration
+	if showGhost {
+		shift = g.ghostShift(snaps, startT)
+	}
+
 	// ---- dynamic Y range (with headroom) ----
 	yMin := 0.0
 	yMax := 0.0
-	for _, host := range g.model.Hosts() {
-		tmp := host.buf.Snapshot(nil)
+	for _, tmp := range snaps {
 		for _, s := range tmp {
 			if s.MS >= 0 && s.MS > yMax {
 				yMax = s.MS
 			}
 		}
 	}
+	if showGhost {
+		for _, gs := range g.ghost {
+			for _, s := range gs.samples {
+				if t := s.T.Add(shift); s.MS > yMax && !t.Before(startT) && !t.After(now) {
+					yMax = s.MS
+				}
+			}
+		}
+	}
 	if yMax <= 0 {
 		yMax = 1
 	}
@@ -145,17 +847,39 @@ func (g *GraphWidget) paint() {
 	const yLabelGap = 8.0
 	const axisTitleGap = 6.0
 
-	left := g.marginPx + maxYLabelW + yLabelGap + fm.Height() + axisTitleGap // room for labels + vertical "ms"
-	bottomPad := fm.Height() + 10.0                                          // room for time labels
-	top := g.marginPx
-	bottom := h - g.marginPx - bottomPad
-	right := w - g.marginPx
+	left := margin + maxYLabelW + yLabelGap + fm.Height() + axisTitleGap // room for labels + vertical "ms"
+	bottomPad := fm.Height() + 10.0                                      // room for time labels
+
+	// loss strip band between plot and time labels: one row per host,
+	// or a single worst-case row once there are too many hosts to stack
+	stripRows, stripH, stripBand := 0, 0.0, 0.0
+	if g.lossStrip > 0 && len(hosts) > 0 {
+		stripRows = len(hosts)
+		if stripRows > maxStripRows {
+			stripRows = 1
+		}
+		stripH = float64(g.lossStrip) * sc
+		stripBand = stripH*float64(stripRows) + 4
+	}
+
+	top := margin
+	bottom := h - margin - bottomPad - stripBand
+	right := w - margin
 
-	if right-left < 40 || bottom-top < 40 {
-		return // too small to render nicely
+	// too small for axes and labels: just the lines, down to tinyPlotPx
+	compact := right-left < minPlotPx || bottom-top < minPlotPx
+	if compact {
+		stripRows, stripBand = 0, 0
+		left, top, right, bottom = compactPad, compactPad, w-compactPad, h-compactPad
+		if right-left < tinyPlotPx || bottom-top < tinyPlotPx {
+			g.view = graphView{}
+			return
+		}
 	}
 
 	plotRect := qt.NewQRectF4(left, top, right-left, bottom-top)
+	g.view = graphView{ok: true, left: left, right: right, top: top, bottom: bottom,
+		start: startT, end: now, yMin: yMin, yMax: yMax}
 
 	// ---- Y grid (clipped) ----
 	p.Save()
@@ -169,32 +893,33 @@ func (g *GraphWidget) paint() {
 	}
 	p.Restore()
 
-	// Y labels (no clip)
-	p.SetPen(txt)
-	for i, v := range ticks {
-		y := mapY(v, yMin, yMax, top, bottom)
-		lbl := qt.NewQStaticText2(yLabels[i])
-		p.DrawStaticText2(qt.NewQPoint2(int(left-maxYLabelW-yLabelGap), int(y-fm.Height()/2)), lbl)
-	}
+	if !compact {
+		// Y labels (no clip)
+		p.SetPen(txt)
+		for i, v := range ticks {
+			y := mapY(v, yMin, yMax, top, bottom)
+			lbl := qt.NewQStaticText2(yLabels[i])
+			p.DrawStaticText2(qt.NewQPoint2(int(left-maxYLabelW-yLabelGap), int(y-fm.Height()/2)), lbl)
+		}
 
-	// ---- vertical axis title "ms" ----
-	p.Save()
-	title := "ms"
-	tx := left - maxYLabelW - yLabelGap - axisTitleGap
-	ty := (top + bottom) / 2
-	p.Translate2(tx, ty)
-	p.Rotate(-90)
-	p.SetPen(txt)
-	p.DrawStaticText2(qt.NewQPoint2(int(-fm.Height()/2), int(-fm.Width(title)/2)), qt.NewQStaticText2(title))
-	p.Restore()
+		// ---- vertical axis title "ms" ----
+		p.Save()
+		title := "ms"
+		tx := left - maxYLabelW - yLabelGap - axisTitleGap
+		ty := (top + bottom) / 2
+		p.Translate2(tx, ty)
+		p.Rotate(-90)
+		p.SetPen(txt)
+		p.DrawStaticText2(qt.NewQPoint2(int(-fm.Height()/2), int(-fm.Width(title)/2)), qt.NewQStaticText2(title))
+		p.Restore()
+	}
 
 	// ---- X grid every 10s (clipped) ----
-	var xTicks []time.Time
+	xTicks := timeTicks(startT, now, gridStep)
 	p.Save()
 	p.SetClipRect3(plotRect, qt.ReplaceClip)
 	p.SetPen(gridCol)
-	for t := startT.Truncate(10 * time.Second); !t.After(now); t = t.Add(10 * time.Second) {
-		xTicks = append(xTicks, t)
+	for _, t := range xTicks {
 		x := mapX(t, startT, now, left, right)
 		path := qt.NewQPainterPath2(qt.NewQPointF3(x, top))
 		path.LineTo(qt.NewQPointF3(x, bottom))
@@ -205,20 +930,32 @@ func (g *GraphWidget) paint() {
 	// ---- series (clipped; cosmetic pen for HiDPI) ----
 	p.Save()
 	p.SetClipRect3(plotRect, qt.ReplaceClip)
-	for i, host := range g.model.Hosts() {
-		tmp := host.buf.Snapshot(nil)
+	if showGhost {
+		g.paintGhost(p, hosts, shift, startT, now, g.view, sc)
+	}
+	for i := range hosts {
+		tmp := snaps[i]
 		if len(tmp) == 0 {
 			continue
 		}
 
-		col := seriesColor(i)
-		pen := qt.NewQPen3(col)
-		pen.SetCosmetic(true)
-		pen.SetWidthF(2.0)
+		width := 2.0
+		if hosts[i] == g.focus && time.Now().Before(g.focusUntil) {
+			width = 4.0
+		}
+		pen := g.seriesPens[i%len(g.seriesPens)]
+		pen.SetWidthF(lineWidth(width * sc))
+		g.warnPen.SetWidthF(lineWidth(width * sc))
+		g.badPen.SetWidthF(lineWidth(width * sc))
 		p.SetPenWithPen(pen)
+		warn, bad := g.model.Thresholds(hosts[i])
 
+		// the line is split into sub-paths whenever the threshold level
+		// changes; each sub-path starts at the previous point so it stays joined
 		var path *qt.QPainterPath
 		var havePath bool
+		pathLv := levelGood
+		var lastX, lastY float64
 
 		for _, s := range tmp {
 			if s.T.Before(startT) {
@@ -229,24 +966,48 @@ func (g *GraphWidget) paint() {
 			switch s.State {
 			case SampleOK:
 				y := mapY(s.MS, yMin, yMax, top, bottom)
-				if !havePath {
+				lv := rttLevelOf(s.MS, warn, bad)
+				switch {
+				case !havePath:
 					path = qt.NewQPainterPath2(qt.NewQPointF3(x, y))
 					havePath = true
-				} else {
+					pathLv = lv
+					p.SetPenWithPen(g.levelPen(lv, pen))
+				case lv != pathLv:
+					p.DrawPath(path)
+					path = qt.NewQPainterPath2(qt.NewQPointF3(lastX, lastY))
+					path.LineTo(qt.NewQPointF3(x, y))
+					pathLv = lv
+					p.SetPenWithPen(g.levelPen(lv, pen))
+				default:
 					path.LineTo(qt.NewQPointF3(x, y))
 				}
+				lastX, lastY = x, y
 
 			case SampleLoss:
-				// flush any existing path before disjoint marker
-				if havePath && path != nil {
+				if g.connectLoss {
+					// dip to the loss value and carry on from there
+					y := maxf(mapY(g.lossMs, yMin, yMax, top, bottom), top)
+					if havePath {
+						path.LineTo(qt.NewQPointF3(x, y))
+					} else {
+						path = qt.NewQPainterPath2(qt.NewQPointF3(x, y))
+						havePath = true
+						p.SetPenWithPen(g.levelPen(pathLv, pen))
+					}
+					lastX, lastY = x, y
+				} else if havePath && path != nil {
+					// flush any existing path before disjoint marker
 					p.DrawPath(path)
 					havePath = false
 					path = nil
 				}
-				// short tick at top
-				tk := qt.NewQPainterPath2(qt.NewQPointF3(x, top))
-				tk.LineTo(qt.NewQPointF3(x, top+12))
-				p.DrawPath(tk)
+				// short tick at top (the loss strip shows it otherwise)
+				if stripRows == 0 {
+					tk := qt.NewQPainterPath2(qt.NewQPointF3(x, top))
+					tk.LineTo(qt.NewQPointF3(x, top+12*sc))
+					p.DrawPath(tk)
+				}
 
 			case SampleLate:
 				// flush path before disjoint marker
@@ -255,13 +1016,10 @@ func (g *GraphWidget) paint() {
 					havePath = false
 					path = nil
 				}
-				r := 3.0
+				r := 3.0 * sc
 				y := mapY(s.MS, yMin, yMax, top, bottom)
-				red := qcolor(255, 0, 0, 255)
-				pr := qt.NewQPen3(red)
-				pr.SetCosmetic(true)
-				pr.SetWidthF(1.5)
-				p.SetPenWithPen(pr)
+				g.latePen.SetWidthF(lineWidth(1.5 * sc))
+				p.SetPenWithPen(g.latePen)
 				// hollow square
 				rect := qt.NewQRectF4(x-r, y-r, 2*r, 2*r)
 				// draw square via path
@@ -272,50 +1030,136 @@ func (g *GraphWidget) paint() {
 				box.LineTo(qt.NewQPointF3(rect.X(), rect.Y()))
 				p.DrawPath(box)
 				// restore main pen
-				p.SetPenWithPen(pen)
+				p.SetPenWithPen(g.levelPen(pathLv, pen))
+
+			case SampleGap:
+				// the machine slept: break the line and mark it top to bottom
+				if havePath && path != nil {
+					p.DrawPath(path)
+					havePath = false
+					path = nil
+				}
+				g.gapPen.SetWidthF(lineWidth(1.5 * sc))
+				p.SetPenWithPen(g.gapPen)
+				gp := qt.NewQPainterPath2(qt.NewQPointF3(x, top))
+				gp.LineTo(qt.NewQPointF3(x, bottom))
+				p.DrawPath(gp)
+				p.SetPenWithPen(g.levelPen(pathLv, pen))
 			}
 		}
 		if havePath && path != nil {
 			p.DrawPath(path)
 		}
 	}
+	if !compact {
+		g.paintMarkers(p, fm, txt, startT, now, g.view, sc)
+		if g.showWorst {
+			g.paintWorst(p, fm, hosts, snaps, startT, now, g.view, sc)
+		}
+	}
 	p.Restore()
+	if compact {
+		return
+	}
+
+	// ---- loss strip (below plot) ----
+	if stripRows > 0 {
+		step := time.Duration(g.model.PingIntervalMs()) * time.Millisecond
+		stripTop := bottom + 2
+		// OK spans first, loss on top: an aggregated row shows the worst case
+		for _, lost := range []bool{false, true} {
+			col := g.okCol
+			if lost {
+				col = g.badCol
+			}
+			for i := range hosts {
+				y := stripTop
+				if stripRows > 1 {
+					y += float64(i) * stripH
+				}
+				tmp := snaps[i]
+				for j, s := range tmp {
+					if s.State == SampleGap || (s.State == SampleLoss) != lost {
+						continue
+					}
+					// a sample covers until the next one, at most one interval
+					end := s.T.Add(step)
+					if j+1 < len(tmp) && tmp[j+1].T.Before(end) {
+						end = tmp[j+1].T
+					}
+					if !end.After(startT) {
+						continue
+					}
+					x0 := mapX(s.T, startT, now, left, right)
+					x1 := mapX(end, startT, now, left, right)
+					p.FillRect4(qt.NewQRectF4(x0, y, maxf(x1-x0, 1), stripH), col)
+				}
+			}
+		}
+	}
 
 	// ---- legend (outside clip, left top) ----
-	legendY := top + 2
-	for i, host := range g.model.Hosts() {
-		col := seriesColor(i)
-		chip := qt.NewQRectF4(left+4, legendY+float64(i*18), 12, 10)
-		p.FillRect4(chip, col)
-		lbl := qt.NewQStaticText2(host.Name + " (" + host.Addr + ")")
-		p.SetPen(txt)
-		p.DrawStaticText2(qt.NewQPoint2(int(left+22), int(legendY+float64(i*18))), lbl)
+	ghostText := ""
+	if showGhost {
+		ghostText = "┄ overlay: " + g.ghostLabel + " (aligned by elapsed time)"
+	}
+	// the loss badges go right top; the legend stops short of the widest
+	type lossBadge struct {
+		i    int
+		text string
+		w    float64
+	}
+	var badges []lossBadge
+	legendMax := right
+	if g.showLoss {
+		dot := 8 * sc
+		for i, host := range hosts {
+			pct, ok := monitor.WindowLoss(snaps[i], startT)
+			if !ok {
+				continue
+			}
+			text := host.Name + "  " + formatFloat(pct, 1) + "% loss"
+			b := lossBadge{i: i, text: text, w: fm.Width(text) + dot + 14}
+			badges = append(badges, b)
+			legendMax = min(legendMax, right-b.w-4-8*sc)
+		}
 	}
+	g.paintLegend(p, fm, txt, bg, hosts, snaps, startT, ghostText, g.view, legendMax, sc)
 
-	// ---- X time labels (clamped + no overlap) ----
+	// ---- loss % badges (right top; tooltip paints over) ----
+	badgeY := top + 4
+	for _, b := range badges {
+		dot := 8 * sc
+		bx := right - b.w - 4
+		if bx < left {
+			bx = left
+		}
+		p.FillRect4(qt.NewQRectF4(bx, badgeY, b.w, fm.Height()+6), g.tipBg)
+		p.FillRect4(qt.NewQRectF4(bx+5, badgeY+(fm.Height()+6-dot)/2, dot, dot), g.seriesCols[b.i%len(g.seriesCols)])
+		p.SetPen(g.tipFg)
+		p.DrawStaticText2(qt.NewQPoint2(int(bx+dot+9), int(badgeY+3)), qt.NewQStaticText2(b.text))
+		badgeY += fm.Height() + 10
+	}
+
+	// ---- X time labels (under their grid lines, sliding out at the edges) ----
+	// fractional positions: rounding to whole pixels makes them stutter
+	p.Save()
+	p.SetClipRect3(qt.NewQRectF4(left, bottom, right-left, h-bottom), qt.ReplaceClip)
 	p.SetPen(txt)
-	prevRight := left - 6
+	tickPx := (right - left) * gridStep.Seconds() / g.timeSpan.Seconds()
+	every := labelEvery(tickPx, fm.Width("00:00:00"))
 	for _, t := range xTicks {
-		x := mapX(t, startT, now, left, right)
-		text := t.Format("15:04:05")
-		tw := fm.Width(text)
-
-		posX := x - tw/2
-		if posX < left {
-			posX = left
-		}
-		if posX+tw > right {
-			posX = right - tw
-		}
-		if posX < prevRight+6 {
+		if !labelled(t, gridStep, every) {
 			continue
 		}
-		p.DrawStaticText2(qt.NewQPoint2(int(posX), int(bottom+4)), qt.NewQStaticText2(text))
-		prevRight = posX + tw
+		text := t.Format("15:04:05")
+		x := mapX(t, startT, now, left, right) - fm.Width(text)/2
+		p.DrawStaticText(qt.NewQPointF3(x, bottom+stripBand+4), qt.NewQStaticText2(text))
 	}
+	p.Restore()
 
 	// ---- hover crosshair + readout (crosshair clipped; tooltip outside) ----
-	if g.mouseInside && g.mouseX >= int(left) && g.mouseX <= int(right) {
+	if hover && g.mouseX >= int(left) && g.mouseX <= int(right) {
 		x := float64(g.mouseX)
 		// crosshair inside plot
 		p.Save()
@@ -330,54 +1174,64 @@ func (g *GraphWidget) paint() {
 
 		// nearest per series + tooltip text lines
 		tAtX := unmapX(x, startT, now, left, right)
-		boxLeft := x + 8
-		if boxLeft > right-200 {
-			boxLeft = right - 200
-		}
 		boxTop := top + 8
 
-		lines := []string{tAtX.Format("15:04:05")}
-		for i, host := range g.model.Hosts() {
-			tmp := host.buf.Snapshot(nil)
-			if len(tmp) == 0 {
+		lines := []string{tipTime(tAtX)}
+		lineCols := []*qt.QColor{g.tipFg}
+		for i, host := range hosts {
+			best, ok := nearestSample(snaps[i], tAtX)
+			if !ok {
 				continue
 			}
-			best := Sample{}
-			bestDT := time.Duration(1<<62 - 1)
-			for _, s := range tmp {
-				dt := s.T.Sub(tAtX)
-				if dt < 0 {
-					dt = -dt
-				}
-				if dt < bestDT {
-					bestDT = dt
-					best = s
-				}
-			}
 			val := "loss"
-			if best.MS >= 0 {
-				val = fmt.Sprintf("%.0f ms", best.MS)
+			switch {
+			case best.State == SampleGap:
+				val = "gap"
+			case best.MS >= 0:
+				val = formatFloat(best.MS, valuePrec(0)) + " ms"
 			}
 			lines = append(lines, fmt.Sprintf("%s: %s", host.Name, val))
+			col := g.tipFg
+			if best.MS >= 0 {
+				warn, bad := g.model.Thresholds(host)
+				switch rttLevelOf(best.MS, warn, bad) {
+				case levelWarn:
+					col = g.warnCol
+				case levelBad:
+					col = g.badCol
+				}
+			}
+			lineCols = append(lineCols, col)
 
 			// small dot marker inside plot
 			if best.MS >= 0 {
 				y := mapY(best.MS, yMin, yMax, top, bottom)
 				p.Save()
 				p.SetClipRect3(plotRect, qt.ReplaceClip)
-				p.FillRect4(qt.NewQRectF4(mapX(best.T, startT, now, left, right)-2, y-2, 4, 4), seriesColor(i))
+				d := 4 * sc
+				p.FillRect4(qt.NewQRectF4(mapX(best.T, startT, now, left, right)-d/2, y-d/2, d, d), g.seriesCols[i%len(g.seriesCols)])
 				p.Restore()
 			}
 		}
 
 		// draw tooltip box
-		boxW := 150.0
-		boxH := float64(16*len(lines) + 8)
-		p.FillRect4(qt.NewQRectF4(boxLeft, boxTop, boxW, boxH), qcolor(0, 0, 0, 160))
-		p.SetPen(qcolor(255, 255, 255, 220))
+		// size the box from the text so it scales with the font/DPI
+		lineH := fm.Height() + 2
+		boxW := 0.0
+		for _, s := range lines {
+			boxW = maxf(boxW, fm.Width(s))
+		}
+		boxW += 12
+		boxH := lineH*float64(len(lines)) + 8
+		boxLeft := x + 8
+		if boxLeft > right-boxW {
+			boxLeft = right - boxW
+		}
+		p.FillRect4(qt.NewQRectF4(boxLeft, boxTop, boxW, boxH), g.tipBg)
 		for i, s := range lines {
+			p.SetPen(lineCols[i])
 			lbl := qt.NewQStaticText2(s)
-			p.DrawStaticText2(qt.NewQPoint2(int(boxLeft+6), int(boxTop+6+float64(16*i))), lbl)
+			p.DrawStaticText2(qt.NewQPoint2(int(boxLeft+6), int(boxTop+4+lineH*float64(i))), lbl)
 		}
 	}
 }
@@ -0,0 +1,296 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// Preferences gathers the settings in one place: latency thresholds,
+// history length, start-up and logging, colors and frame rate, alerts,
+// scheduled export, the HTTP endpoints and the public IP lookup. Those with
+// a control of their own (the status bar's frame rate and colors, the
+// sample log checkbox) are applied through that control, so both stay in
+// step. The dialog edits its own widgets; nothing reaches the config, or
+// settings.yml, before Apply or OK.
+
+const prefsShortcut = "Ctrl+,"
+
+// prefsForm is one section of the dialog.
+func prefsForm(col *qt.QVBoxLayout, title string) *qt.QFormLayout {
+	box := qt.NewQGroupBox4(title, nil)
+	form := qt.NewQFormLayout(nil)
+	box.SetLayout(form.QLayout)
+	col.AddWidget(box.QWidget)
+	return form
+}
+
+func prefsSpin(lo, hi, v int, suffix string) *qt.QSpinBox {
+	sb := qt.NewQSpinBox(nil)
+	sb.SetRange(lo, hi)
+	sb.SetSuffix(suffix)
+	sb.SetValue(v)
+	return sb
+}
+
+func prefsDouble(hi, v float64, suffix string) *qt.QDoubleSpinBox {
+	sb := qt.NewQDoubleSpinBox(nil)
+	sb.SetRange(0, hi)
+	sb.SetDecimals(1)
+	sb.SetSpecialValueText("off")
+	sb.SetSuffix(suffix)
+	sb.SetValue(v)
+	return sb
+}
+
+func prefsEdit(v, placeholder string) *qt.QLineEdit {
+	ed := qt.NewQLineEdit(nil)
+	ed.SetText(v)
+	ed.SetPlaceholderText(placeholder)
+	ed.SetMinimumWidth(scaledPx(280))
+	return ed
+}
+
+// showPreferences opens the dialog on the current settings.
+func (ui *UI) showPreferences() {
+	c := ui.model.Config()
+	if c == nil {
+		return
+	}
+	def := defaultConfig()
+	dlg := qt.NewQDialog(ui.main.QWidget)
+	dlg.SetWindowTitle("Preferences")
+	col := qt.NewQVBoxLayout(nil)
+	dlg.SetLayout(col.QLayout)
+
+	form := prefsForm(col, "Ping")
+	warn := prefsDouble(10000, c.Ping.WarnMs, " ms")
+	warn.SetToolTip("Lines turn amber at or above this RTT; hosts can override it")
+	bad := prefsDouble(10000, c.Ping.BadMs, " ms")
+	bad.SetToolTip("Lines turn red at or above this RTT; hosts can override it")
+	px := c.Graph.LossStripPx
+	if px <= 0 {
+		px = def.Graph.LossStripPx
+	}
+	stripPx := prefsSpin(2, 40, px, " px")
+	form.AddRow3("Warn above:", warn.QWidget)
+	form.AddRow3("Bad above:", bad.QWidget)
+	form.AddRow3("Loss strip height:", stripPx.QWidget)
+	lossAt := prefsDouble(10000, c.Graph.LossMs, " ms")
+	lossAt.SetSpecialValueText("time axis")
+	lossAt.SetToolTip("Where \"Connect through loss\" draws a lost probe, e.g. a high value so losses spike up instead of down")
+	form.AddRow3("Loss drawn at:", lossAt.QWidget)
+	reresolve := prefsSpin(0, 24*60, c.Ping.ReresolveMin, " min")
+	reresolve.SetSpecialValueText("off")
+	reresolve.SetToolTip("Look host names up again this often and follow them to a new address (CDN, load balancers); applies from the next Start")
+	form.AddRow3("Re-resolve names every:", reresolve.QWidget)
+	rdns := qt.NewQCheckBox4("Show reverse DNS names of pinged addresses", nil)
+	rdns.SetChecked(c.Ping.ReverseDNS)
+	rdns.SetToolTip("Look up the PTR name of each host's address and show it in the graph legend and the host's tooltip")
+	form.AddRow(nil, rdns.QWidget)
+	ringCap := prefsSpin(minRingCap, maxRingCap, ui.model.RingCap(), " samples")
+	ringCap.SetSingleStep(100)
+	ringCap.SetToolTip("History kept per host for the graph and stats; applies to hosts added from now on and at the next launch")
+	form.AddRow3("Keep per host:", ringCap.QWidget)
+	autoStart := qt.NewQCheckBox4("Start pinging when the app opens", nil)
+	autoStart.SetChecked(c.Ping.AutoStart)
+	form.AddRow(nil, autoStart.QWidget)
+	logSamples := qt.NewQCheckBox4(ui.chkLog.Text(), nil)
+	logSamples.SetChecked(ui.chkLog.IsChecked())
+	form.AddRow(nil, logSamples.QWidget)
+
+	form = prefsForm(col, "Display")
+	palette := qt.NewQComboBox(nil)
+	for _, p := range paletteNames {
+		palette.AddItem(p[1])
+	}
+	palette.SetCurrentIndex(ui.palCombo.CurrentIndex())
+	hiCon := qt.NewQCheckBox4(ui.chkHiCon.Text(), nil)
+	hiCon.SetChecked(ui.chkHiCon.IsChecked())
+	fps := qt.NewQComboBox(nil)
+	for _, r := range frameRates {
+		fps.AddItem(fmt.Sprintf("%d fps", r))
+	}
+	fps.SetCurrentIndex(ui.fpsCombo.CurrentIndex())
+	saver := qt.NewQCheckBox4(ui.chkSaver.Text(), nil)
+	saver.SetChecked(ui.chkSaver.IsChecked())
+	saver.SetToolTip(ui.chkSaver.ToolTip())
+	comma := qt.NewQCheckBox4(ui.chkComma.Text(), nil)
+	comma.SetChecked(ui.chkComma.IsChecked())
+	comma.SetToolTip(ui.chkComma.ToolTip())
+	form.AddRow3("Colors:", palette.QWidget)
+	form.AddRow(nil, hiCon.QWidget)
+	form.AddRow3("Frame rate:", fps.QWidget)
+	form.AddRow(nil, saver.QWidget)
+	form.AddRow(nil, comma.QWidget)
+	decimals := qt.NewQComboBox(nil)
+	decimals.AddItem("Default")
+	for d := 0; d <= 2; d++ {
+		decimals.AddItem(strconv.Itoa(d))
+	}
+	decimals.SetCurrentIndex(min(max(c.View.Decimals, -1), 2) + 1)
+	decimals.SetToolTip("Decimals of RTTs and rates in graph labels and tooltips, e.g. 2 for sub-millisecond LAN latency")
+	millis := qt.NewQCheckBox4("Milliseconds in tooltip times", nil)
+	millis.SetChecked(c.View.TipMillis)
+	scale, scales := uiScaleCombo(c.View.UIScale)
+	form.AddRow3("Value decimals:", decimals.QWidget)
+	form.AddRow(nil, millis.QWidget)
+	form.AddRow3("UI scale:", scale.QWidget)
+
+	form = prefsForm(col, "Alerts")
+	alertLoss := prefsDouble(100, c.Alert.LossPct, " %")
+	alertRTT := prefsDouble(10000, c.Alert.RTTms, " ms")
+	alertWin := prefsSpin(5, 3600, max(c.Alert.WindowSec, 5), " s")
+	alertWin.SetToolTip("How far back each check looks")
+	alertCool := prefsSpin(0, 24*3600, c.Alert.CooldownSec, " s")
+	alertCool.SetToolTip("Minimum time between two alerts for the same host")
+	webhook := prefsEdit(c.Alert.WebhookURL, "https://…")
+	command := prefsEdit(c.Alert.Command, "run with: <state> <host> <addr>")
+	form.AddRow3("Loss at or above:", alertLoss.QWidget)
+	form.AddRow3("Average RTT at or above:", alertRTT.QWidget)
+	form.AddRow3("Window:", alertWin.QWidget)
+	form.AddRow3("Cooldown:", alertCool.QWidget)
+	form.AddRow3("Webhook URL:", webhook.QWidget)
+	form.AddRow3("Command:", command.QWidget)
+
+	form = prefsForm(col, "Scheduled export")
+	expOn := qt.NewQCheckBox4("Save the graph (PNG) and samples (CSV) on a schedule", nil)
+	expOn.SetChecked(c.Export.Enabled)
+	expEvery := prefsSpin(1, 24*60, max(c.Export.IntervalMin, 1), " min")
+	expDir := prefsEdit(c.Export.Dir, exportsDir())
+	form.AddRow(nil, expOn.QWidget)
+	form.AddRow3("Every:", expEvery.QWidget)
+	form.AddRow3("Folder:", expDir.QWidget)
+
+	form = prefsForm(col, "Network")
+	metricsOn := qt.NewQCheckBox4("Prometheus metrics", nil)
+	metricsOn.SetChecked(c.Metrics.Enabled)
+	metricsAt := prefsEdit(c.Metrics.Listen, def.Metrics.Listen)
+	apiOn := qt.NewQCheckBox4("JSON API", nil)
+	apiOn.SetChecked(c.API.Enabled)
+	apiAt := prefsEdit(c.API.Listen, def.API.Listen)
+	pubURL := prefsEdit(c.Net.PublicIPURL, def.Net.PublicIPURL)
+	form.AddRow(metricsOn.QWidget, metricsAt.QWidget)
+	form.AddRow(apiOn.QWidget, apiAt.QWidget)
+	form.AddRow3("Public IP lookup:", pubURL.QWidget)
+
+	// apply stores the dialog's values; false (with a message) when they
+	// don't make sense together
+	apply := func() bool {
+		text := func(ed *qt.QLineEdit, def string) string {
+			if s := strings.TrimSpace(ed.Text()); s != "" {
+				return s
+			}
+			return def
+		}
+		if w, b := warn.Value(), bad.Value(); w > 0 && b > 0 && w >= b {
+			qt.QMessageBox_Warning(dlg.QWidget, "Preferences", "\"Warn above\" must be lower than \"Bad above\".")
+			warn.SetFocus()
+			return false
+		}
+
+		c.Ping.WarnMs, c.Ping.BadMs = warn.Value(), bad.Value()
+		c.Graph.LossStripPx = stripPx.Value()
+		c.Ping.ReresolveMin = reresolve.Value()
+		if on := rdns.IsChecked(); on != c.Ping.ReverseDNS {
+			c.Ping.ReverseDNS = on
+			ui.applyReverseDNS()
+		}
+		c.Ping.RingCapacity = ringCap.Value()
+		if c.Ping.RingCapacity == DefaultRingCap {
+			c.Ping.RingCapacity = 0
+		}
+		c.Ping.AutoStart = autoStart.IsChecked()
+		ui.chkLog.SetChecked(logSamples.IsChecked()) // its handler starts or stops the log
+		c.Graph.LossMs = lossAt.Value()
+		ui.graph.SetConnectLoss(c.Graph.ConnectLoss, c.Graph.LossMs)
+		ui.applyLossStrip()
+		ui.graph.Update()
+
+		c.View.Decimals = decimals.CurrentIndex() - 1
+		c.View.TipMillis = millis.IsChecked()
+		valueDecimals.Store(int32(c.View.Decimals))
+		tipMillis.Store(c.View.TipMillis)
+		if s := scales[max(scale.CurrentIndex(), 0)]; s != c.View.UIScale {
+			c.View.UIScale = s
+			applyUIScale(s)
+		}
+		// through the status bar controls, whose handlers apply and save
+		ui.palCombo.SetCurrentIndex(palette.CurrentIndex())
+		ui.chkHiCon.SetChecked(hiCon.IsChecked())
+		ui.fpsCombo.SetCurrentIndex(fps.CurrentIndex())
+		ui.chkSaver.SetChecked(saver.IsChecked())
+		ui.chkComma.SetChecked(comma.IsChecked())
+
+		c.Alert.LossPct, c.Alert.RTTms = alertLoss.Value(), alertRTT.Value()
+		c.Alert.WindowSec, c.Alert.CooldownSec = alertWin.Value(), alertCool.Value()
+		c.Alert.WebhookURL = strings.TrimSpace(webhook.Text())
+		c.Alert.Command = strings.TrimSpace(command.Text())
+
+		exp := AutoExportConfig{Enabled: expOn.IsChecked(), IntervalMin: expEvery.Value(), Dir: strings.TrimSpace(expDir.Text())}
+		if exp != c.Export {
+			c.Export = exp
+			ui.stopAutoExport()
+			ui.startAutoExport(exp)
+		}
+
+		var failed []string
+		m := MetricsConfig{Enabled: metricsOn.IsChecked(), Listen: text(metricsAt, def.Metrics.Listen)}
+		if m != c.Metrics {
+			c.Metrics = m
+			if err := ui.startMetrics(m); err != nil {
+				failed = append(failed, "Metrics: "+err.Error())
+			}
+		}
+		a := APIConfig{Enabled: apiOn.IsChecked(), Listen: text(apiAt, def.API.Listen)}
+		if a != c.API {
+			c.API = a
+			if err := ui.startAPI(a); err != nil {
+				failed = append(failed, "JSON API: "+err.Error())
+			}
+		}
+		if url := text(pubURL, def.Net.PublicIPURL); url != c.Net.PublicIPURL {
+			c.Net.PublicIPURL = url
+			ui.refreshPublicIP()
+		}
+
+		ui.model.SaveConfigAsync()
+		if len(failed) > 0 {
+			qt.QMessageBox_Warning(dlg.QWidget, "Preferences", strings.Join(failed, "\n"))
+		}
+		return true
+	}
+
+	buttons := qt.NewQDialogButtonBox4(qt.QDialogButtonBox__Ok | qt.QDialogButtonBox__Apply | qt.QDialogButtonBox__Cancel)
+	buttons.Button(qt.QDialogButtonBox__Apply).OnClicked(func() { apply() })
+	buttons.OnAccepted(func() {
+		if apply() {
+			dlg.Accept()
+		}
+	})
+	buttons.OnRejected(func() { dlg.Reject() })
+	col.AddWidget(buttons.QWidget)
+	dlg.Exec()
+}
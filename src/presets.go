@@ -0,0 +1,77 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/e1z0/speedping/internal/netinfo"
+	"github.com/mappu/miqt/qt"
+)
+
+// quick-pick targets for the ping and traceroute inputs
+
+type targetPreset struct {
+	Name string
+	Addr string // empty => detect the default gateway when picked
+}
+
+var targetPresets = []targetPreset{
+	{Name: "Cloudflare DNS", Addr: "1.1.1.1"},
+	{Name: "Google DNS", Addr: "8.8.8.8"},
+	{Name: "Quad9 DNS", Addr: "9.9.9.9"},
+	{Name: "Gateway", Addr: ""},
+}
+
+// newPresetCombo builds a "Presets…" drop-down. Picking an entry hands its
+// name/address to onPick (the caller fills its own, still editable, fields)
+// and snaps the combo back to the hint item.
+func newPresetCombo(onPick func(name, addr string)) *qt.QComboBox {
+	cb := qt.NewQComboBox(nil)
+	cb.AddItem("Presets…")
+	for _, p := range targetPresets {
+		if p.Addr == "" {
+			cb.AddItem(p.Name)
+		} else {
+			cb.AddItem(fmt.Sprintf("%s (%s)", p.Name, p.Addr))
+		}
+	}
+
+	cb.OnActivated(func(idx int) {
+		cb.SetCurrentIndex(0)
+		if idx <= 0 || idx > len(targetPresets) {
+			return
+		}
+		p := targetPresets[idx-1]
+		addr := p.Addr
+		if addr == "" {
+			gw, err := netinfo.DefaultGateway()
+			if err != nil {
+				log.Printf("Unable to detect default gateway: %s\n", err)
+				return
+			}
+			addr = gw
+		}
+		onPick(p.Name, addr)
+	})
+	return cb
+}
@@ -0,0 +1,117 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/e1z0/speedping/internal/dns"
+	"github.com/mappu/miqt/qt/mainthread"
+)
+
+// Reverse DNS (ping.reverse_dns): the address each host is pinged at is
+// looked up in the background and its PTR name shown in the graph legend
+// and the host's tooltip, which gives a bare IP some context. Names stay
+// blank until the lookup is back; results, failures included, are cached
+// per address until the app quits.
+
+// pingedIP is the address h is pinged at: the one its name resolved to, or
+// the address itself for an IP literal; "" before the first Start.
+func (h *Host) pingedIP() string {
+	if h.IP != "" {
+		return h.IP
+	}
+	if net.ParseIP(h.Addr) != nil {
+		return h.Addr
+	}
+	return ""
+}
+
+func (ui *UI) reverseDNSOn() bool {
+	c := ui.model.Config()
+	return c != nil && c.Ping.ReverseDNS
+}
+
+// lookupPTR sets h.PTR from the cache or, the first time its address comes
+// up, from a background lookup that fills in every host at that address.
+func (ui *UI) lookupPTR(h *Host) {
+	ip := h.pingedIP()
+	if ip == "" || !ui.reverseDNSOn() {
+		return
+	}
+	if name, ok := ui.ptrCache[ip]; ok {
+		ui.setPTR(h, name)
+		return
+	}
+	if h.PTR != "" {
+		ui.setPTR(h, "") // the address moved; don't show the old name meanwhile
+	}
+	if ui.ptrBusy[ip] {
+		return
+	}
+	if ui.ptrCache == nil {
+		ui.ptrCache, ui.ptrBusy = map[string]string{}, map[string]bool{}
+	}
+	ui.ptrBusy[ip] = true
+	go func() {
+		name, err := dns.Reverse(context.Background(), ip)
+		if err != nil {
+			name = ""
+		}
+		mainthread.Wait(func() {
+			delete(ui.ptrBusy, ip)
+			ui.ptrCache[ip] = name
+			if !ui.reverseDNSOn() {
+				return
+			}
+			for _, hh := range ui.model.Hosts() {
+				if hh.pingedIP() == ip {
+					ui.setPTR(hh, name)
+				}
+			}
+		})
+	}()
+}
+
+// setPTR shows name for h, unless it only repeats the host's own name.
+func (ui *UI) setPTR(h *Host, name string) {
+	if strings.EqualFold(name, h.Addr) || strings.EqualFold(name, h.Name) {
+		name = ""
+	}
+	h.PTR = name
+	ui.syncHost(h)
+}
+
+// applyReverseDNS looks every host up after the setting was turned on, or
+// clears their names after it was turned off.
+func (ui *UI) applyReverseDNS() {
+	on := ui.reverseDNSOn()
+	for _, h := range ui.model.Hosts() {
+		switch {
+		case on:
+			ui.lookupPTR(h)
+		case h.PTR != "":
+			ui.setPTR(h, "")
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"math"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// RateReadout is the Speed tab's big live number: the latest throughput in
+// bold with the peak of the run beside it, sized from the widget's height
+// so it grows with the window (e.g. for a projector).
+type RateReadout struct {
+	qt.QWidget
+
+	cur, peak float64 // Mbps
+	have      bool    // any interval since Reset
+	unit      rateUnit
+}
+
+func NewRateReadout() *RateReadout {
+	r := &RateReadout{unit: rateUnitNamed("Mbps")}
+	r.QWidget = *qt.NewQWidget(nil)
+	sc := dpiScale(r.QPaintDevice)
+	r.SetMinimumHeight(int(60 * sc))
+	r.OnPaintEvent(func(super func(*qt.QPaintEvent), e *qt.QPaintEvent) { r.paint() })
+	return r
+}
+
+// Set shows mbps as the current rate and raises the peak if needed.
+func (r *RateReadout) Set(mbps float64) {
+	r.cur, r.peak, r.have = mbps, math.Max(r.peak, mbps), true
+	r.Update()
+}
+
+// Reset clears the readout for a new run.
+func (r *RateReadout) Reset() { r.cur, r.peak, r.have = 0, 0, false; r.Update() }
+
+func (r *RateReadout) SetUnit(name string) { r.unit = rateUnitNamed(name); r.Update() }
+
+func (r *RateReadout) paint() {
+	W, H := float64(r.Width()), float64(r.Height())
+	if W < 4 || H < 4 {
+		return
+	}
+	p := qt.NewQPainter()
+	if !p.Begin(r.QPaintDevice) {
+		return
+	}
+	defer p.End()
+	p.SetRenderHint2(qt.QPainter__TextAntialiasing, true)
+	txt := r.Palette().ColorWithCr(qt.QPalette__WindowText)
+
+	big, peak := "–", ""
+	if r.have {
+		big = r.unit.format(r.cur)
+		peak = "peak " + r.unit.format(r.peak)
+	}
+
+	// the number takes ~70% of the height, shrunk if it would overflow
+	font := r.Font()
+	font.SetBold(true)
+	font.SetPixelSize(max(int(H*0.7), 8))
+	fm := qt.NewQFontMetricsF(font)
+	if w := fm.Width(big); w > W*0.7 {
+		font.SetPixelSize(max(int(H*0.7*W*0.7/w), 8))
+		fm = qt.NewQFontMetricsF(font)
+	}
+	p.SetFont(font)
+	p.SetPen(txt)
+	bigW := fm.Width(big)
+	x := (W - bigW) / 2
+	y := (H - fm.Height()) / 2
+	p.DrawStaticText(qt.NewQPointF3(x, y), qt.NewQStaticText2(big))
+	baseline := y + fm.Ascent()
+
+	if peak == "" {
+		return
+	}
+	small := r.Font()
+	small.SetPixelSize(max(int(H*0.2), 8))
+	sfm := qt.NewQFontMetricsF(small)
+	p.SetFont(small)
+	p.SetPen(qcolor(txt.Red(), txt.Green(), txt.Blue(), 170))
+	p.DrawStaticText(qt.NewQPointF3(min(x+bigW+H*0.15, W-sfm.Width(peak)), baseline-sfm.Ascent()), qt.NewQStaticText2(peak))
+}
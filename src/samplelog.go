@@ -0,0 +1,217 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Continuous per-sample CSV logging (opt-in, ping.log_samples).
+// Samples are handed over through a buffered channel so the ping callbacks
+// never wait on disk; a single writer goroutine owns the file.
+
+const (
+	sampleLogName    = "samples.csv"
+	sampleLogMaxSize = 10 << 20 // rotate after ~10 MB
+	sampleLogKeep    = 3        // samples.csv.1 .. samples.csv.3
+	sampleLogQueue   = 1024
+)
+
+type sampleRec struct {
+	host HostIdent
+	s    Sample
+}
+
+type SampleLogger struct {
+	path     string
+	ch       chan sampleRec
+	disabled atomic.Bool
+	warn     sync.Once
+	done     chan struct{}
+
+	mu     sync.RWMutex // guards closed vs. sends on ch
+	closed bool
+}
+
+// NewSampleLogger starts a logger writing into dir (usually logsDir()).
+func NewSampleLogger(dir string) *SampleLogger {
+	l := &SampleLogger{
+		path: filepath.Join(dir, sampleLogName),
+		ch:   make(chan sampleRec, sampleLogQueue),
+		done: make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Log queues a sample; it never blocks (samples are dropped if the writer lags).
+func (l *SampleLogger) Log(id HostIdent, s Sample) {
+	if l == nil || l.disabled.Load() {
+		return
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return
+	}
+	select {
+	case l.ch <- sampleRec{host: id, s: s}:
+	default:
+	}
+}
+
+// Close flushes pending samples and closes the file.
+func (l *SampleLogger) Close() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.ch)
+	}
+	l.mu.Unlock()
+	<-l.done
+}
+
+func (l *SampleLogger) fail(err error) {
+	l.disabled.Store(true)
+	l.warn.Do(func() {
+		log.Printf("Sample logging disabled: %s\n", err)
+	})
+}
+
+func (l *SampleLogger) run() {
+	defer close(l.done)
+
+	var (
+		f    *os.File
+		w    *bufio.Writer
+		size int64
+	)
+	open := func() error {
+		if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+			return err
+		}
+		var err error
+		f, err = os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		st, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		size = st.Size()
+		w = bufio.NewWriter(f)
+		if size == 0 {
+			n, _ := w.WriteString(sampleCSVHeader)
+			size += int64(n)
+		}
+		return nil
+	}
+	closeFile := func() {
+		if f != nil {
+			_ = w.Flush()
+			_ = f.Close()
+			f, w = nil, nil
+		}
+	}
+	defer closeFile()
+
+	if err := open(); err != nil {
+		l.fail(err)
+		for range l.ch { // drain until closed
+		}
+		return
+	}
+
+	flush := time.NewTicker(2 * time.Second)
+	defer flush.Stop()
+
+	for {
+		select {
+		case rec, ok := <-l.ch:
+			if !ok {
+				return
+			}
+			if l.disabled.Load() {
+				continue
+			}
+			n, err := w.WriteString(sampleCSVLine(rec.host, rec.s))
+			if err != nil {
+				l.fail(err)
+				closeFile()
+				continue
+			}
+			size += int64(n)
+			if size >= sampleLogMaxSize {
+				closeFile()
+				rotateFiles(l.path, sampleLogKeep)
+				if err := open(); err != nil {
+					l.fail(err)
+				}
+			}
+		case <-flush.C:
+			if w != nil {
+				if err := w.Flush(); err != nil {
+					l.fail(err)
+					closeFile()
+				}
+			}
+		}
+	}
+}
+
+// rotateFiles shifts path -> path.1 -> path.2 ... keeping at most keep backups.
+func rotateFiles(path string, keep int) {
+	_ = os.Remove(fmt.Sprintf("%s.%d", path, keep))
+	for i := keep - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	_ = os.Rename(path, path+".1")
+}
+
+const sampleCSVHeader = "time,name,addr,seq,ms,state,note\n"
+
+// sampleCSVLine formats s of the host id as one CSV row (see sampleCSVHeader).
+func sampleCSVLine(id HostIdent, s Sample) string {
+	return fmt.Sprintf("%s,%s,%s,%d,%s,%s,%s\n",
+		s.T.Format(time.RFC3339Nano), csvField(id.Name), csvField(id.Addr),
+		s.Seq, strconv.FormatFloat(s.MS, 'f', 3, 64), s.State, csvField(id.Note))
+}
+
+// csvField quotes s if it contains CSV special characters.
+func csvField(s string) string {
+	if strings.ContainsAny(s, ",\"\r\n") {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return s
+}
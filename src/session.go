@@ -0,0 +1,139 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/e1z0/speedping/internal/monitor"
+)
+
+// A session is a named snapshot of the ping tab: host list, interval, graph
+// span and (optionally) the samples collected so far. Unlike settings.yml it
+// is only written and read on request, so it can be reopened later to compare.
+
+const sessionFilter = "SpeedPing session (*.json)"
+
+type Session struct {
+	Saved      time.Time     `json:"saved"`
+	IntervalMs int           `json:"interval_ms"`
+	SpanSec    float64       `json:"span_sec"`
+	Hosts      []SessionHost `json:"hosts"`
+	Markers    []Marker      `json:"markers,omitempty"` // graph annotations
+
+	Dropped int `json:"-"` // custom probe hosts LoadSession left out
+}
+
+type SessionHost struct {
+	Name       string   `json:"name"`
+	Addr       string   `json:"addr"`
+	Note       string   `json:"note,omitempty"`
+	WarnMs     float64  `json:"warn_ms,omitempty"`
+	BadMs      float64  `json:"bad_ms,omitempty"`
+	IntervalMs int      `json:"interval_ms,omitempty"` // probing overrides, 0 = global
+	PacketSize int      `json:"packet_size,omitempty"`
+	History    []Sample `json:"history,omitempty"` // oldest first
+}
+
+// SaveSession writes the current hosts (and their rings when history is set)
+// to path. span is the graph's visible time window; markers are stored
+// along with the history.
+func (m *AppModel) SaveSession(path string, span time.Duration, history bool, markers []Marker) error {
+	sess := Session{
+		Saved:      time.Now(),
+		IntervalMs: m.PingIntervalMs(),
+		SpanSec:    span.Seconds(),
+	}
+	if history {
+		sess.Markers = markers
+	}
+	for _, h := range m.Hosts() {
+		sh := SessionHost{Name: h.Name, Addr: h.Addr, Note: h.Note, WarnMs: h.WarnMs, BadMs: h.BadMs,
+			IntervalMs: h.IntervalMs, PacketSize: h.PacketSize}
+		if history {
+			sh.History = h.buf.Snapshot(nil)
+		}
+		sess.Hosts = append(sess.Hosts, sh)
+	}
+	b, err := json.MarshalIndent(sess, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadSession parses the session at path without applying it.
+func ReadSession(path string) (*Session, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sess Session
+	if err := json.Unmarshal(b, &sess); err != nil {
+		return nil, err
+	}
+	if len(sess.Hosts) == 0 {
+		return nil, errors.New("session has no hosts")
+	}
+	return &sess, nil
+}
+
+// LoadSession replaces the host list with the one stored at path, refilling
+// rings from the saved history. Custom probe hosts are left out (counted in
+// Dropped): a session file may come from anyone, and they run commands.
+// Pinging must be stopped by the caller first.
+func (m *AppModel) LoadSession(path string) (*Session, error) {
+	sess, err := ReadSession(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ClearHosts()
+	for _, sh := range sess.Hosts {
+		if _, custom := monitor.CommandLine(sh.Addr); custom {
+			log.Printf("Session %s: not loading custom probe %q\n", path, sh.Addr)
+			sess.Dropped++
+			continue
+		}
+		h := m.AddHost(sh.Name, sh.Addr, max(m.RingCap(), len(sh.History)))
+		h.Note, h.WarnMs, h.BadMs = sh.Note, sh.WarnMs, sh.BadMs
+		h.IntervalMs, h.PacketSize = sh.IntervalMs, sh.PacketSize
+		for _, s := range sh.History {
+			h.buf.Push(s)
+		}
+	}
+	if sess.IntervalMs > 0 {
+		m.SetPingIntervalMs(sess.IntervalMs)
+	}
+	return sess, nil
+}
@@ -0,0 +1,100 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// childRuns tracks the iperf3/traceroute processes we started, so closing
+// the window can cancel them and wait until they have really exited instead
+// of leaving orphans behind (Windows console children like to linger).
+type childRuns struct {
+	mu   sync.Mutex
+	runs map[*childRun]struct{}
+}
+
+type childRun struct {
+	cancel context.CancelFunc
+	exited chan struct{}
+}
+
+// shutdownWait bounds how long closing the window waits for children.
+const shutdownWait = 3 * time.Second
+
+// Start registers a run cancelled by cancel. Call the returned func once its
+// process has exited (after the iperf done / traceroute event channel closed).
+func (c *childRuns) Start(cancel context.CancelFunc) (exited func()) {
+	r := &childRun{cancel: cancel, exited: make(chan struct{})}
+	c.mu.Lock()
+	if c.runs == nil {
+		c.runs = make(map[*childRun]struct{})
+	}
+	c.runs[r] = struct{}{}
+	c.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			delete(c.runs, r)
+			c.mu.Unlock()
+			close(r.exited)
+		})
+	}
+}
+
+// Shutdown cancels every run and waits up to timeout for them to exit.
+// It must be called on the UI thread; Qt events keep being processed while
+// waiting so goroutines blocked in mainthread.Wait can get to their exit.
+func (c *childRuns) Shutdown(timeout time.Duration) bool {
+	c.mu.Lock()
+	var pending []*childRun
+	for r := range c.runs {
+		r.cancel()
+		pending = append(pending, r)
+	}
+	c.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for _, r := range pending {
+		for !isClosed(r.exited) {
+			if time.Now().After(deadline) {
+				return false
+			}
+			qt.QCoreApplication_ProcessEvents()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	return true
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,113 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"log"
+
+	"github.com/e1z0/speedping/internal/netinfo"
+	"github.com/mappu/miqt/qt"
+)
+
+// Source address selection: on a machine with several interfaces or a VPN,
+// pings (ping.source) and speed tests (speed.bind) can be sent from one
+// chosen local address to test that path. The list is read from the
+// interfaces each time it is opened; a saved address that has since
+// disappeared stays selectable but marked, and nothing is started from it.
+
+// sourcePicker is a combo box of "Automatic" plus the usable local addresses.
+type sourcePicker struct {
+	*qt.QComboBox
+	addrs []string // address behind each item, "" = automatic
+}
+
+// newSourcePicker builds a picker with sel preselected. Hook OnActivated
+// to react to the user's choice.
+func newSourcePicker(sel, tip string) *sourcePicker {
+	sp := &sourcePicker{QComboBox: qt.NewQComboBox(nil)}
+	sp.SetToolTip(tip)
+	sp.fill(sel)
+	sp.OnShowPopup(func(super func()) {
+		sp.fill(sp.Addr())
+		super()
+	})
+	return sp
+}
+
+// fill lists "Automatic" and every usable local address, selecting sel.
+func (sp *sourcePicker) fill(sel string) {
+	addrs, err := netinfo.SourceAddrs()
+	if err != nil {
+		log.Printf("Listing network interfaces failed: %s\n", err)
+	}
+	sp.Clear()
+	sp.addrs = append(sp.addrs[:0], "")
+	sp.AddItem("Automatic")
+	cur := 0
+	for _, a := range addrs {
+		if a.IP == sel {
+			cur = len(sp.addrs)
+		}
+		sp.AddItem(a.Iface + "  " + a.IP)
+		sp.addrs = append(sp.addrs, a.IP)
+	}
+	if sel != "" && cur == 0 {
+		cur = len(sp.addrs)
+		sp.AddItem(sel + " (not available)")
+		sp.addrs = append(sp.addrs, sel)
+	}
+	sp.SetCurrentIndex(cur)
+}
+
+// Addr is the selected address, "" for automatic.
+func (sp *sourcePicker) Addr() string {
+	if i := sp.CurrentIndex(); i > 0 && i < len(sp.addrs) {
+		return sp.addrs[i]
+	}
+	return ""
+}
+
+// Check returns why the selected address can't be used right now, or nil
+// (always for automatic). The reason is logged too.
+func (sp *sourcePicker) Check() error {
+	src := sp.Addr()
+	if src == "" {
+		return nil
+	}
+	err := netinfo.CheckSource(src)
+	if err != nil {
+		log.Printf("Source address %s unusable: %s\n", src, err)
+	}
+	return err
+}
+
+// pingSource is the address pings are sent from, "" for automatic.
+func (ui *UI) pingSource() string { return ui.srcPick.Addr() }
+
+// checkPingSource tells the user when pinging can't start from the chosen
+// address, or returns true when it can.
+func (ui *UI) checkPingSource() bool {
+	if ui.srcPick.Check() != nil {
+		ui.main.StatusBar().ShowMessage2("Not pinging: source "+ui.pingSource()+" is not on any active interface (pick another under Source)", 30000)
+		return false
+	}
+	return true
+}
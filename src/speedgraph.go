@@ -21,16 +21,14 @@
 package main
 
 import (
-	"fmt"
+	"math"
 	"time"
 
+	"github.com/e1z0/speedping/internal/iperf"
 	"github.com/mappu/miqt/qt"
 )
 
-type mbpsSample struct {
-	T    time.Time
-	Mbps float64
-}
+type mbpsSample = iperf.Sample
 
 type mbpsRing struct {
 	data  []mbpsSample
@@ -66,6 +64,48 @@ func (r *mbpsRing) snapshot(dst []mbpsSample) []mbpsSample {
 	return dst
 }
 
+// rateUnit is a throughput display unit. Samples stay in Mbps; perMbps
+// converts them for labels only.
+type rateUnit struct {
+	name    string
+	perMbps float64
+	prec    int // decimals in readouts
+}
+
+var rateUnits = []rateUnit{
+	{"Kbps", 1000, 0},
+	{"Mbps", 1, 1},
+	{"Gbps", 0.001, 2},
+	{"MB/s", 0.125, 1},
+}
+
+// rateUnitNamed returns the unit called name, Mbps when unknown.
+func rateUnitNamed(name string) rateUnit {
+	for _, u := range rateUnits {
+		if u.name == name {
+			return u
+		}
+	}
+	return rateUnits[1]
+}
+
+// format renders mbps in u, e.g. "0.61 Gbps".
+func (u rateUnit) format(mbps float64) string {
+	return formatFloat(mbps*u.perMbps, valuePrec(u.prec)) + " " + u.name
+}
+
+// tickDecimals is how many decimals tick labels need to tell ticks apart.
+func tickDecimals(ticks []float64) int {
+	if len(ticks) < 2 {
+		return 0
+	}
+	step := ticks[1] - ticks[0]
+	if step <= 0 || step >= 1 {
+		return 0
+	}
+	return int(math.Ceil(-math.Log10(step) - 1e-9))
+}
+
 type SpeedGraphWidget struct {
 	qt.QWidget
 
@@ -76,6 +116,11 @@ type SpeedGraphWidget struct {
 
 	ring *mbpsRing
 
+	avgMbps float64 // end-of-test average (0 = none yet)
+	showAvg bool    // draw avgMbps as a dashed line
+	smooth  bool    // spline through samples instead of straight segments
+	unit    rateUnit
+
 	mouseX      int
 	mouseInside bool
 }
@@ -83,11 +128,13 @@ type SpeedGraphWidget struct {
 func NewSpeedGraphWidget() *SpeedGraphWidget {
 	w := &SpeedGraphWidget{}
 	w.QWidget = *qt.NewQWidget(nil)
-	w.SetMinimumSize2(800, 240)
+	sc := dpiScale(w.QPaintDevice)
+	w.SetMinimumSize2(int(800*sc), int(240*sc))
 	w.span = 60 * time.Second
 	w.marginPx = 40
 	w.frameRate = 30
 	w.ring = newMbpsRing(600) // ~10 minutes @ 1s; plenty for scrolling window
+	w.unit = rateUnitNamed("Mbps")
 
 	w.SetMouseTracking(true)
 	w.OnEnterEvent(func(super func(*qt.QEvent), e *qt.QEvent) { w.mouseInside = true })
@@ -103,17 +150,34 @@ func NewSpeedGraphWidget() *SpeedGraphWidget {
 func (w *SpeedGraphWidget) StartTicker() {
 	w.ticker = qt.NewQTimer()
 	w.ticker.OnTimeout(func() { w.Update() })
-	ms := 1000 / w.frameRate
-	if ms <= 0 {
-		ms = 33
+	w.ticker.Start(frameRateToMs(w.frameRate))
+}
+
+// SetFrameRate changes the repaint rate, live if the ticker already runs.
+func (w *SpeedGraphWidget) SetFrameRate(fps int) {
+	w.frameRate = fps
+	if w.ticker != nil {
+		w.ticker.SetInterval(frameRateToMs(fps))
 	}
-	w.ticker.Start(ms)
 }
 
+// Samples returns a copy of the samples held by the graph, oldest first.
+func (w *SpeedGraphWidget) Samples() []mbpsSample { return w.ring.snapshot(nil) }
+
 func (w *SpeedGraphWidget) AppendMbps(v float64) {
 	w.ring.push(mbpsSample{T: time.Now(), Mbps: v})
 }
 
+// SetAverage sets the end-of-test average (0 clears it).
+func (w *SpeedGraphWidget) SetAverage(mbps float64) { w.avgMbps = mbps; w.Update() }
+
+func (w *SpeedGraphWidget) SetShowAverage(on bool) { w.showAvg = on; w.Update() }
+
+func (w *SpeedGraphWidget) SetSmooth(on bool) { w.smooth = on; w.Update() }
+
+// SetUnit switches the Y labels and tooltip to the unit called name.
+func (w *SpeedGraphWidget) SetUnit(name string) { w.unit = rateUnitNamed(name); w.Update() }
+
 func (w *SpeedGraphWidget) paint() {
 	W := float64(w.Width())
 	H := float64(w.Height())
@@ -128,14 +192,17 @@ func (w *SpeedGraphWidget) paint() {
 	defer p.End()
 	p.SetRenderHint2(qt.QPainter__Antialiasing, true)
 
+	sc := dpiScale(w.QPaintDevice)
+	margin := w.marginPx * sc
+
 	// ----- palette-aware colors -----
 	bg := w.Palette().ColorWithCr(qt.QPalette__Window)
 	txt := w.Palette().ColorWithCr(qt.QPalette__WindowText)
 	// Grid color: faint version of text
 	gridCol := qt.NewQColor()
-	gridCol.SetRgb2(txt.Red(), txt.Green(), txt.Blue(), 80)
-	lineCol := qcolor(90, 180, 255, 255)
-	tooltipBg := qcolor(0, 0, 0, 160)
+	gridCol.SetRgb2(txt.Red(), txt.Green(), txt.Blue(), contrastAlpha(80))
+	lineCol := seriesColor(0)
+	tooltipBg := qcolor(0, 0, 0, contrastAlpha(160))
 
 	p.FillRect4(qt.NewQRectF4(0, 0, W, H), bg)
 
@@ -144,6 +211,10 @@ func (w *SpeedGraphWidget) paint() {
 
 	// ---- collect points in window ----
 	buf := w.ring.snapshot(nil)
+	if len(buf) == 0 {
+		paintNoData(p, W, H, txt, noDataText)
+		return
+	}
 	var pts []mbpsSample
 	for _, s := range buf {
 		if s.T.After(startT) {
@@ -151,14 +222,18 @@ func (w *SpeedGraphWidget) paint() {
 		}
 	}
 
-	// ---- dynamic Y scale with headroom ----
+	// ---- dynamic Y scale with headroom, in the display unit ----
+	u := w.unit
 	yMin := 0.0
 	yMax := 0.0
 	for _, s := range pts {
-		if s.Mbps > yMax {
-			yMax = s.Mbps
+		if v := s.Mbps * u.perMbps; v > yMax {
+			yMax = v
 		}
 	}
+	if v := w.avgMbps * u.perMbps; w.showAvg && v > yMax {
+		yMax = v
+	}
 	// ensure at least a floor
 	if yMax <= 0 {
 		yMax = 1
@@ -172,11 +247,12 @@ func (w *SpeedGraphWidget) paint() {
 
 	// ----- dynamic margins from font metrics -----
 	fm := qt.NewQFontMetricsF(p.Font())
-	// widest possible Y label among ticks, format like "1000Mbps"
+	// widest possible Y label among ticks, format like "1000 Mbps"
 	maxYLabelW := 0.0
 	yLabels := make([]string, len(ticks))
+	prec := tickDecimals(ticks)
 	for i, v := range ticks {
-		s := fmt.Sprintf("%.0f Mbps", v)
+		s := formatFloat(v, prec) + " " + u.name
 		yLabels[i] = s
 		if w := fm.Width(s); w > maxYLabelW {
 			maxYLabelW = w
@@ -185,14 +261,19 @@ func (w *SpeedGraphWidget) paint() {
 	const yLabelGap = 8.0
 	const axisTitleGap = 6.0
 
-	left := w.marginPx + maxYLabelW + yLabelGap + fm.Height() + axisTitleGap // space for Y labels + vertical "Mbps"
-	bottomPad := fm.Height() + 10.0                                          // room for time labels
-	top := w.marginPx
-	bottom := H - w.marginPx - bottomPad
-	right := W - w.marginPx
-
-	if right-left < 40 || bottom-top < 40 {
-		return // too small to render nicely
+	left := margin + maxYLabelW + yLabelGap + fm.Height() + axisTitleGap // space for Y labels + vertical unit
+	bottomPad := fm.Height() + 10.0                                      // room for time labels
+	top := margin
+	bottom := H - margin - bottomPad
+	right := W - margin
+
+	// too small for axes and labels: just the line, down to tinyPlotPx
+	compact := right-left < minPlotPx || bottom-top < minPlotPx
+	if compact {
+		left, top, right, bottom = compactPad, compactPad, W-compactPad, H-compactPad
+		if right-left < tinyPlotPx || bottom-top < tinyPlotPx {
+			return
+		}
 	}
 
 	// ---- plot rect + clipping for grid/series ----
@@ -210,24 +291,26 @@ func (w *SpeedGraphWidget) paint() {
 	}
 	p.Restore()
 
-	// Y labels (no clip)
-	p.SetPen(txt)
-	for i, v := range ticks {
-		y := mapY(v, yMin, yMax, top, bottom)
-		lbl := qt.NewQStaticText2(yLabels[i])
-		p.DrawStaticText2(qt.NewQPoint2(int(left-maxYLabelW-yLabelGap), int(y-fm.Height()/2)), lbl)
-	}
+	if !compact {
+		// Y labels (no clip)
+		p.SetPen(txt)
+		for i, v := range ticks {
+			y := mapY(v, yMin, yMax, top, bottom)
+			lbl := qt.NewQStaticText2(yLabels[i])
+			p.DrawStaticText2(qt.NewQPoint2(int(left-maxYLabelW-yLabelGap), int(y-fm.Height()/2)), lbl)
+		}
 
-	// ---- vertical axis title "Mbps" ----
-	p.Save()
-	title := "Mbps"
-	tx := left - maxYLabelW - yLabelGap - axisTitleGap
-	ty := (top + bottom) / 2
-	p.Translate2(tx, ty)
-	p.Rotate(-90)
-	p.SetPen(txt)
-	p.DrawStaticText2(qt.NewQPoint2(int(-fm.Height()/2), int(-fm.Width(title)/2)), qt.NewQStaticText2(title))
-	p.Restore()
+		// ---- vertical axis title (the unit) ----
+		p.Save()
+		title := u.name
+		tx := left - maxYLabelW - yLabelGap - axisTitleGap
+		ty := (top + bottom) / 2
+		p.Translate2(tx, ty)
+		p.Rotate(-90)
+		p.SetPen(txt)
+		p.DrawStaticText2(qt.NewQPoint2(int(-fm.Height()/2), int(-fm.Width(title)/2)), qt.NewQStaticText2(title))
+		p.Restore()
+	}
 
 	// --- Grid X each 10s ---
 	var xTicks []time.Time
@@ -249,17 +332,25 @@ func (w *SpeedGraphWidget) paint() {
 		p.SetClipRect3(plotRect, qt.ReplaceClip)
 		pen := qt.NewQPen3(lineCol)
 		pen.SetCosmetic(true)
-		pen.SetWidthF(2.0)
+		pen.SetWidthF(lineWidth(2.0 * sc))
 		p.SetPenWithPen(pen)
 
 		var path *qt.QPainterPath
-		for i, s := range pts {
-			x := mapX(s.T, startT, now, left, right)
-			y := mapY(s.Mbps, yMin, yMax, top, bottom)
-			if i == 0 {
-				path = qt.NewQPainterPath2(qt.NewQPointF3(x, y))
-			} else {
-				path.LineTo(qt.NewQPointF3(x, y))
+		if w.smooth {
+			xy := make([]plotPt, len(pts))
+			for i, s := range pts {
+				xy[i] = plotPt{mapX(s.T, startT, now, left, right), mapY(s.Mbps*u.perMbps, yMin, yMax, top, bottom)}
+			}
+			path = smoothPath(xy, bottom)
+		} else {
+			for i, s := range pts {
+				x := mapX(s.T, startT, now, left, right)
+				y := mapY(s.Mbps*u.perMbps, yMin, yMax, top, bottom)
+				if i == 0 {
+					path = qt.NewQPainterPath2(qt.NewQPointF3(x, y))
+				} else {
+					path.LineTo(qt.NewQPointF3(x, y))
+				}
 			}
 		}
 		if path != nil {
@@ -268,6 +359,29 @@ func (w *SpeedGraphWidget) paint() {
 		p.Restore()
 	}
 
+	// ---- end-of-test average (dashed) ----
+	if w.showAvg && w.avgMbps > 0 {
+		y := mapY(w.avgMbps*u.perMbps, yMin, yMax, top, bottom)
+		p.Save()
+		p.SetClipRect3(plotRect, qt.ReplaceClip)
+		ap := qt.NewQPen3(stateColor(palette.ok, 220))
+		ap.SetCosmetic(true)
+		ap.SetWidthF(lineWidth(1.5 * sc))
+		ap.SetStyle(qt.DashLine)
+		p.SetPenWithPen(ap)
+		path := qt.NewQPainterPath2(qt.NewQPointF3(left, y))
+		path.LineTo(qt.NewQPointF3(right, y))
+		p.DrawPath(path)
+		if !compact {
+			lbl := "avg " + u.format(w.avgMbps)
+			p.DrawStaticText2(qt.NewQPoint2(int(right-fm.Width(lbl)-4), int(y-fm.Height()-2)), qt.NewQStaticText2(lbl))
+		}
+		p.Restore()
+	}
+	if compact {
+		return
+	}
+
 	// ---- X time labels (clamped to plot; prevent overlaps) ----
 	p.SetPen(txt)
 	prevRight := left - 6 // last drawn label's right edge
@@ -323,14 +437,16 @@ func (w *SpeedGraphWidget) paint() {
 			}
 		}
 		// tooltip (outside clip)
-		box := qt.NewQRectF4(x+8, top+8, 170, 40)
+		// box sized from the font so it scales with DPI
+		lineH := fm.Height() + 2
+		box := qt.NewQRectF4(x+8, top+8, maxf(170*sc, fm.Width("000000.00 "+u.name)+12), 2*lineH+8)
 		if box.X()+box.Width() > right {
 			box.SetX(right - box.Width())
 		}
 		p.FillRect4(box, tooltipBg)
 		// tooltip text uses normal text color for contrast
 		p.SetPen(qcolor(255, 255, 255, 220))
-		p.DrawStaticText2(qt.NewQPoint2(int(box.X()+6), int(box.Y()+6)), qt.NewQStaticText2(tAtX.Format("15:04:05")))
-		p.DrawStaticText2(qt.NewQPoint2(int(box.X()+6), int(box.Y()+22)), qt.NewQStaticText2(fmt.Sprintf("%.1f Mbps", best.Mbps)))
+		p.DrawStaticText2(qt.NewQPoint2(int(box.X()+6), int(box.Y()+6)), qt.NewQStaticText2(tipTime(tAtX)))
+		p.DrawStaticText2(qt.NewQPoint2(int(box.X()+6), int(box.Y()+6+lineH)), qt.NewQStaticText2(u.format(best.Mbps)))
 	}
 }
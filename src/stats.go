@@ -0,0 +1,65 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/e1z0/speedping/internal/monitor"
+)
+
+// Report computes a HostReport for every host, in Hosts() order.
+func (m *AppModel) Report() []HostReport { return m.ReportSince(time.Time{}) }
+
+// ReportEnvelope is Report as shared outside the app ("Copy stats as
+// JSON"): the host reports with when and by what they were taken.
+type ReportEnvelope struct {
+	App     string       `json:"app"`
+	Version string       `json:"version"`
+	Time    time.Time    `json:"time"`
+	Hosts   []HostReport `json:"hosts"`
+}
+
+// ReportJSON is the live Report at now, wrapped in a ReportEnvelope and
+// indented.
+func (m *AppModel) ReportJSON(now time.Time) ([]byte, error) {
+	hosts := m.Report()
+	if hosts == nil {
+		hosts = []HostReport{} // "[]" rather than "null"
+	}
+	return json.MarshalIndent(ReportEnvelope{App: AppName, Version: AppVersion, Time: now, Hosts: hosts}, "", "  ")
+}
+
+// ReportSince is Report limited to the samples newer than t.
+func (m *AppModel) ReportSince(t time.Time) []HostReport {
+	hosts := m.Hosts()
+	out := make([]HostReport, 0, len(hosts))
+	var buf []Sample
+	for _, h := range hosts {
+		buf = h.buf.Snapshot(buf)
+		id := m.Ident(h)
+		r := monitor.ComputeReport(id.Name, id.Addr, samplesSince(buf, t))
+		r.Note = id.Note
+		out = append(out, r)
+	}
+	return out
+}
@@ -0,0 +1,182 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/e1z0/speedping/internal/monitor"
+	"github.com/mappu/miqt/qt"
+)
+
+// Session summary (ping.summary_on_stop): when a session started with the
+// Start button ends, by hand or by a "Stop after" condition, the per-host
+// results since Start are shown in a dialog that can be copied or saved.
+
+// summaryRow is one host's line in the summary.
+type summaryRow struct {
+	HostReport
+	Duration time.Duration // first to last sample in the session
+	Streak   int           // longest run of losses
+}
+
+// sessionSummary collects the rows for the samples taken in [start, end].
+func (m *AppModel) sessionSummary(start, end time.Time) []summaryRow {
+	reports := m.ReportSince(start)
+	rows := make([]summaryRow, 0, len(reports))
+	var buf []Sample
+	for i, h := range m.Hosts() {
+		if i >= len(reports) {
+			break
+		}
+		buf = h.buf.Snapshot(buf)
+		in := samplesSince(buf, start)
+		row := summaryRow{HostReport: reports[i]}
+		if len(in) > 0 {
+			row.Duration = in[len(in)-1].T.Sub(in[0].T)
+		}
+		row.Streak = monitor.WorstIn(in, start, end).RunLen
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// summaryText lays rows out as a fixed-width table under a heading naming
+// the session's time span and, when set, why it ended.
+func summaryText(rows []summaryRow, start, end time.Time, reason string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SpeedPing session %s – %s (%s)\n",
+		start.Format("2006-01-02 15:04:05"), end.Format("15:04:05"), end.Sub(start).Round(time.Second))
+	if reason != "" {
+		fmt.Fprintf(&b, "Stopped: %s\n", reason)
+	}
+	b.WriteString("\n")
+
+	head := []string{"Host", "Duration", "Probes", "Loss %", "Min", "Avg", "Max", "Jitter", "Worst streak"}
+	cells := [][]string{head}
+	for _, r := range rows {
+		name := r.Name
+		if name != r.Addr {
+			name += " (" + r.Addr + ")"
+		}
+		ms := func(v float64) string {
+			if r.Samples == 0 || r.LossPct >= 100 {
+				return "-"
+			}
+			return formatFloat(v, 1)
+		}
+		cells = append(cells, []string{
+			name,
+			r.Duration.Round(time.Second).String(),
+			strconv.Itoa(r.Samples),
+			formatFloat(r.LossPct, 1),
+			ms(r.Min), ms(r.Avg), ms(r.Max), ms(r.Jitter),
+			strconv.Itoa(r.Streak),
+		})
+	}
+	width := make([]int, len(head))
+	for _, row := range cells {
+		for i, c := range row {
+			width[i] = max(width[i], len([]rune(c)))
+		}
+	}
+	for _, row := range cells {
+		for i, c := range row {
+			pad := strings.Repeat(" ", width[i]-len([]rune(c)))
+			if i == 0 {
+				b.WriteString(c + pad) // names left-aligned, numbers right
+			} else {
+				b.WriteString("  " + pad + c)
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nRTT and jitter in ms; worst streak is the most probes lost in a row.\n")
+	return b.String()
+}
+
+// showSessionSummary pops the summary of the session begun at ui.sessionStart
+// up, unless turned off or no session was started with Start.
+func (ui *UI) showSessionSummary(reason string) {
+	start := ui.sessionStart
+	ui.sessionStart = time.Time{}
+	if start.IsZero() || !ui.chkSummary.IsChecked() || ui.model.Count() == 0 {
+		return
+	}
+	end := time.Now()
+	text := summaryText(ui.model.sessionSummary(start, end), start, end, reason)
+	dlg, _, _ := ui.summaryDialog("Session summary", text, start)
+	dlg.Show()
+}
+
+// summaryDialog builds a dialog showing text with Copy, Save… and Close
+// buttons; callers may add widgets to col and buttons before showing it.
+func (ui *UI) summaryDialog(title, text string, start time.Time) (dlg *qt.QDialog, col *qt.QVBoxLayout, buttons *qt.QDialogButtonBox) {
+	dlg = qt.NewQDialog(ui.main.QWidget)
+	dlg.SetWindowTitle(title)
+	dlg.SetAttribute(qt.WA_DeleteOnClose)
+	col = qt.NewQVBoxLayout(nil)
+	dlg.SetLayout(col.QLayout)
+
+	view := qt.NewQPlainTextEdit(nil)
+	view.SetReadOnly(true)
+	view.SetLineWrapMode(qt.QPlainTextEdit__NoWrap)
+	view.SetFont(qt.QFontDatabase_SystemFont(qt.QFontDatabase__FixedFont))
+	view.SetPlainText(text)
+	sc := dpiScale(ui.graph.QPaintDevice)
+	view.SetMinimumSize2(int(640*sc), int(200*sc))
+	col.AddWidget(view.QWidget)
+
+	buttons = qt.NewQDialogButtonBox4(qt.QDialogButtonBox__Close)
+	btnCopy := buttons.AddButton2("Copy", qt.QDialogButtonBox__ActionRole)
+	btnSave := buttons.AddButton2("Save…", qt.QDialogButtonBox__ActionRole)
+	btnCopy.OnClicked(func() { copyToClipboard(text) })
+	btnSave.OnClicked(func() { saveSummary(dlg.QWidget, text, start) })
+	buttons.OnRejected(func() { dlg.Close() })
+	col.AddWidget(buttons.QWidget)
+	return dlg, col, buttons
+}
+
+// saveSummary asks for a file and writes text to it.
+func saveSummary(parent *qt.QWidget, text string, start time.Time) {
+	name := filepath.Join(exportsDir(), "summary-"+start.Format("20060102-150405")+".txt")
+	path := qt.QFileDialog_GetSaveFileName4(parent, "Save summary", name, "Text (*.txt)")
+	if path == "" {
+		return
+	}
+	if filepath.Ext(path) == "" {
+		path += ".txt"
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err == nil {
+		err = os.WriteFile(path, []byte(text), 0o644)
+	}
+	if err != nil {
+		log.Printf("Unable to save summary %s: %s\n", path, err)
+		qt.QMessageBox_Warning(parent, "Save summary", err.Error())
+	}
+}
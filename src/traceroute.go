@@ -22,17 +22,24 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"math"
+	"net"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/e1z0/speedping/internal/dns"
+	"github.com/e1z0/speedping/internal/monitor"
 	traceroute_wrapper "github.com/e1z0/speedping/internal/traceroute"
 	"github.com/mappu/miqt/qt"
 	"github.com/mappu/miqt/qt/mainthread"
 )
 
-func buildTracerouteTab(model *AppModel) *qt.QWidget {
+func buildTracerouteTab(model *AppModel, runs *childRuns) (*qt.QWidget, *TracerMap) {
 	page := qt.NewQWidget(nil)
 	col := qt.NewQVBoxLayout(nil)
 	page.SetLayout(col.QLayout)
@@ -48,34 +55,76 @@ func buildTracerouteTab(model *AppModel) *qt.QWidget {
 	probes := qt.NewQLineEdit(nil)
 	probes.SetText("1")
 	noDNS := qt.NewQCheckBox4("Don't resolve", nil)
+	family := qt.NewQComboBox(nil)
+	family.SetToolTip("Address family to trace a dual-stack name over; falls back to the other one when the name has no such address")
+	for _, f := range traceFamilies {
+		family.AddItem(f.name)
+	}
+	deltaMode := qt.NewQCheckBox4("Per-hop delta", nil)
+	deltaMode.SetToolTip("Plot the delay each hop adds instead of the RTT to it")
+	repeat := qt.NewQCheckBox4("Repeat", nil)
+	repeat.SetToolTip("Trace again after each run and keep a per-hop RTT history")
+	colors := qt.NewQComboBox(nil)
+	colors.SetToolTip("Map colors; traceroute.colors in settings.yml overrides single colors")
+	for _, p := range mapPresetNames {
+		colors.AddItem(p[1])
+	}
+	var saveNow func()
+	presets := newPresetCombo(func(_, addr string) {
+		target.SetText(addr)
+		saveNow()
+	})
 
 	start := qt.NewQPushButton(nil)
 	start.SetText("Start")
 	stop := qt.NewQPushButton(nil)
 	stop.SetText("Stop")
 	stop.SetEnabled(false)
+	btnCopy, btnTerm := commandButtons()
+	// copy the hops: click for plain text, the arrow offers Markdown
+	copyRes := qt.NewQToolButton(nil)
+	copyRes.SetText("Copy results")
+	copyRes.SetToolTip("Copy the hops of the last run as text; the arrow offers a Markdown table")
+	copyRes.SetPopupMode(qt.QToolButton__MenuButtonPopup)
+	copyMenu := qt.NewQMenu(nil)
+	copyText := copyMenu.AddAction("Plain text")
+	copyMD := copyMenu.AddAction("Markdown table")
+	copyRes.SetMenu(copyMenu)
 	status := qt.NewQLabel6("Idle.", nil, 0)
+	// the command line of the current run, once traceroute has started
+	cmdLabel := qt.NewQLabel6("", nil, 0)
+	cmdLabel.SetTextInteractionFlags(qt.TextSelectableByMouse)
+	cmdLabel.SetVisible(false)
 
 	row.AddWidget(qt.NewQLabel6("Target:", nil, 0).QWidget)
 	row.AddWidget(target.QWidget)
+	row.AddWidget(presets.QWidget)
 	row.AddWidget(qt.NewQLabel6("Max hops:", nil, 0).QWidget)
 	row.AddWidget(maxHops.QWidget)
 	row.AddWidget(qt.NewQLabel6("Timeout(s):", nil, 0).QWidget)
 	row.AddWidget(timeout.QWidget)
 	row.AddWidget(qt.NewQLabel6("Probes:", nil, 0).QWidget)
 	row.AddWidget(probes.QWidget)
+	row.AddWidget(family.QWidget)
 	row.AddWidget(noDNS.QWidget)
+	row.AddWidget(deltaMode.QWidget)
+	row.AddWidget(repeat.QWidget)
+	row.AddWidget(colors.QWidget)
 	row.AddStretch()
 	row.AddWidget(start.QWidget)
 	row.AddWidget(stop.QWidget)
+	row.AddWidget(btnCopy.QWidget)
+	row.AddWidget(btnTerm.QWidget)
+	row.AddWidget(copyRes.QWidget)
 
 	col.AddLayout(row.QLayout)
 	col.AddWidget(status.QWidget)
+	col.AddWidget(cmdLabel.QWidget)
 
 	// Table
 	table := qt.NewQTableWidget(nil)
-	table.SetColumnCount(3)
-	table.SetHorizontalHeaderLabels([]string{"Hop", "Address", "RTT (ms)"})
+	table.SetColumnCount(4)
+	table.SetHorizontalHeaderLabels([]string{"Hop", "Address", "RTT (ms)", "History"})
 	table.HorizontalHeader().SetStretchLastSection(true)
 	col.AddWidget2(table.QWidget, 1)
 
@@ -94,7 +143,7 @@ func buildTracerouteTab(model *AppModel) *qt.QWidget {
 		if c.Trace.TimeoutSec <= 0 {
 			c.Trace.TimeoutSec = 1.0
 		}
-		timeout.SetText(fmt.Sprintf("%.1f", c.Trace.TimeoutSec))
+		timeout.SetText(strconv.FormatFloat(c.Trace.TimeoutSec, 'f', -1, 64))
 
 		if c.Trace.Probes <= 0 {
 			c.Trace.Probes = 1
@@ -102,6 +151,20 @@ func buildTracerouteTab(model *AppModel) *qt.QWidget {
 		probes.SetText(fmt.Sprint(c.Trace.Probes))
 
 		noDNS.SetChecked(c.Trace.DontResolve)
+		for i, f := range traceFamilies {
+			if f.family == c.Trace.Family {
+				family.SetCurrentIndex(i)
+			}
+		}
+		deltaMode.SetChecked(c.Trace.Delta)
+		repeat.SetChecked(c.Trace.Repeat)
+		tmap.SetDeltaMode(c.Trace.Delta)
+		for i, p := range mapPresetNames {
+			if p[0] == c.Trace.Colors.Preset {
+				colors.SetCurrentIndex(i)
+			}
+		}
+		tmap.SetTheme(mapThemeFrom(c.Trace.Colors))
 
 		// pulse speed
 		if c.Trace.PulseSeconds > 0 {
@@ -110,18 +173,17 @@ func buildTracerouteTab(model *AppModel) *qt.QWidget {
 	}
 
 	// ---- SAVE helper (debounced by model) ----
-	saveNow := func() {
-		c := model.Config()
-		if c == nil {
-			c = defaultConfig()
-			model.LoadFromConfig(c)
-		}
-
+	saveNow = func() {
+		c := model.EnsureConfig()
 		c.Trace.Target = strings.TrimSpace(target.Text())
 		c.Trace.MaxHops = atoiDefault(maxHops.Text(), 30)
 		c.Trace.TimeoutSec = atofDefault(timeout.Text(), 1.0)
 		c.Trace.Probes = atoiDefault(probes.Text(), 1)
 		c.Trace.DontResolve = noDNS.IsChecked()
+		c.Trace.Family = traceFamilies[max(family.CurrentIndex(), 0)].family
+		c.Trace.Delta = deltaMode.IsChecked()
+		c.Trace.Repeat = repeat.IsChecked()
+		c.Trace.Colors.Preset = mapPresetNames[colors.CurrentIndex()][0]
 		// keep current pulse speed (tmap already has it); if we want a hidden default, persist it:
 		if c.Trace.PulseSeconds <= 0 {
 			c.Trace.PulseSeconds = 6.0
@@ -136,114 +198,401 @@ func buildTracerouteTab(model *AppModel) *qt.QWidget {
 	timeout.OnEditingFinished(saveNow)
 	probes.OnEditingFinished(saveNow)
 	noDNS.OnToggled(func(bool) { saveNow() })
+	family.OnCurrentIndexChanged(func(int) { saveNow() })
+	repeat.OnToggled(func(bool) { saveNow() })
+	deltaMode.OnToggled(func(on bool) {
+		tmap.SetDeltaMode(on)
+		saveNow()
+	})
+	colors.OnCurrentIndexChanged(func(int) {
+		saveNow()
+		tmap.SetTheme(mapThemeFrom(model.Config().Trace.Colors))
+	})
+
+	// options for a run with the settings as saved by saveNow
+	runOptions := func() traceroute_wrapper.Options {
+		c := model.Config()
+		return traceroute_wrapper.Options{
+			Target:      c.Trace.Target,
+			MaxHops:     c.Trace.MaxHops,
+			Timeout:     time.Duration(c.Trace.TimeoutSec*1000) * time.Millisecond,
+			Probes:      c.Trace.Probes,
+			DontResolve: c.Trace.DontResolve,
+			Family:      c.Trace.Family,
+		}
+	}
+	wireCommandButtons(btnCopy, btnTerm, status, func() (string, []string, error) {
+		saveNow()
+		return traceroute_wrapper.Command(runOptions())
+	})
 
 	// Runtime
-	var cancel context.CancelFunc
+	var (
+		cancel   context.CancelFunc
+		stopped  bool
+		cycles   int
+		hopRows  map[int]int // hop index -> table row, kept across repeats
+		hopLines map[int]*HopSparkline
+		lastHops map[int]traceroute_wrapper.Hop // latest result per hop, for copying
+		tracedAt time.Time
+		tracedTo string
+		hopCap   int // hop events beyond it are malformed output and dropped
+		dropped  int // such events in the current run
+	)
 	setRunning := func(on bool) {
 		start.SetEnabled(!on)
 		stop.SetEnabled(on)
 	}
-
-	start.OnClicked(func() {
-		if !start.IsEnabled() {
-			return
+	setHop := func(h traceroute_wrapper.Hop) {
+		r, ok := hopRows[h.Index]
+		if !ok {
+			r = table.RowCount()
+			table.InsertRow(r)
+			table.SetItem(r, 0, qt.NewQTableWidgetItem2(fmt.Sprintf("%d", h.Index)))
+			line := NewHopSparkline()
+			table.SetCellWidget(r, 3, &line.QWidget)
+			hopRows[h.Index], hopLines[h.Index] = r, line
 		}
-		saveNow()
+		table.SetItem(r, 1, qt.NewQTableWidgetItem2(h.Addr))
+		if h.RTTms < 0 {
+			table.SetItem(r, 2, qt.NewQTableWidgetItem2("timeout"))
+		} else {
+			table.SetItem(r, 2, qt.NewQTableWidgetItem2(formatFloat(h.RTTms, 1)))
+		}
+		hopLines[h.Index].Add(h.RTTms)
+		lastHops[h.Index] = h
+		// map
+		tmap.UpsertHop(h.Index, h.Addr, h.RTTms)
+	}
 
-		tgt := strings.TrimSpace(target.Text())
-		if tgt == "" {
-			status.SetText("Please enter target")
+	copyResults := func(markdown bool) {
+		if len(lastHops) == 0 {
+			status.SetText("Nothing to copy yet.")
 			return
 		}
+		copyToClipboard(traceReport(tracedTo, tracedAt, lastHops, markdown))
+		if markdown {
+			status.SetText("Results copied as a Markdown table.")
+		} else {
+			status.SetText("Results copied.")
+		}
+	}
+	copyRes.OnClicked(func() { copyResults(false) })
+	copyText.OnTriggered(func() { copyResults(false) })
+	copyMD.OnTriggered(func() { copyResults(true) })
+
+	// live "Running… 12s, 7 hops" while a trace is in progress
+	var (
+		runStarted time.Time
+		runNote    string
+		tracing    string                 // "Tracing example.com [2606:…] via IPv6"
+		lastHop    traceroute_wrapper.Hop // highest hop index seen
+		lastOK     traceroute_wrapper.Hop // highest hop that answered
+	)
+	elapsed := qt.NewQTimer()
+	elapsed.SetInterval(500)
+	showProgress := func() {
+		secs := int(time.Since(runStarted).Seconds())
+		status.SetText(fmt.Sprintf("%s… %ds, %d hops%s", tracing, secs, lastHop.Index, runNote))
+	}
+	elapsed.OnTimeout(showProgress)
+	// summary of the finished run: "Reached 1.1.1.1 in 7 hops (3.2 s)" or
+	// "Did not reach example.com [93.184.215.14] within 30 hops (41.0 s)"
+	// name is the target as typed, opt.Target the address traced and
+	// targetIPs what name resolved to when the run started.
+	runSummary := func(opt traceroute_wrapper.Options, name string, targetIPs []string) (string, bool) {
+		took := formatFloat(time.Since(runStarted).Seconds(), 1) + " s"
+		if traceroute_wrapper.Reached(lastOK.Addr, opt.Target, targetIPs) {
+			return fmt.Sprintf("Reached %s in %d hops (%s)", lastOK.Addr, lastOK.Index, took), true
+		}
+		if name != opt.Target {
+			name += " [" + opt.Target + "]"
+		}
+		return fmt.Sprintf("Did not reach %s within %d hops (%s)", name, maxTraceHops(opt), took), false
+	}
 
-		// update config from UI once more before running
-		saveNow()
-
-		c := model.Config()
-		opt := traceroute_wrapper.Options{
-			Target:      c.Trace.Target,
-			MaxHops:     c.Trace.MaxHops,
-			Timeout:     time.Duration(c.Trace.TimeoutSec*1000) * time.Millisecond,
-			Probes:      c.Trace.Probes,
-			DontResolve: c.Trace.DontResolve,
+	// pause between repeated runs
+	again := qt.NewQTimer()
+	again.SetSingleShot(true)
+	again.SetInterval(1000)
+
+	// runOnce starts one traceroute; fresh clears the table and hop history.
+	var (
+		runOnce func(fresh bool)
+		trace   func(ctx context.Context, cn context.CancelFunc, opt traceroute_wrapper.Options, ips []string)
+	)
+	runOnce = func(fresh bool) {
+		opt := runOptions()
+		// the platform may not take the exact value (macOS: whole seconds)
+		runNote = ""
+		if eff := traceroute_wrapper.EffectiveTimeout(opt.Timeout); eff != opt.Timeout {
+			timeout.SetText(strconv.FormatFloat(eff.Seconds(), 'f', -1, 64))
+			saveNow() // settings.yml gets the value actually used
+			runNote = fmt.Sprintf(" (timeout rounded to %gs)", eff.Seconds())
 		}
 
-		table.SetRowCount(0)
-		tmap.Reset()
+		// rows are kept per hop index across repeats; start over when
+		// max hops was lowered so none is left beyond the new limit
+		hopCap, dropped = maxTraceHops(opt), 0
+		for i := range hopRows {
+			if i > hopCap {
+				fresh = true
+				break
+			}
+		}
+		if fresh {
+			table.SetRowCount(0)
+			hopRows, hopLines = map[int]int{}, map[int]*HopSparkline{}
+			lastHops = map[int]traceroute_wrapper.Hop{}
+			cycles = 0
+			tmap.Reset()
+		}
+		cycles++
+		tracedAt, tracedTo = time.Now(), opt.Target
 
 		ctx, cn := context.WithCancel(context.Background())
 		cancel = cn
+		setRunning(true)
+
+		// resolve first, so the status can tell which address and family
+		// are traced
+		status.SetText("Resolving " + opt.Target + "…")
+		go func() {
+			ips, err := dns.Lookup(ctx, opt.Target)
+			mainthread.Wait(func() {
+				switch {
+				case errors.Is(err, context.Canceled):
+					status.SetText("Stopped.")
+					setRunning(false)
+				case err != nil:
+					log.Printf("traceroute: resolving %s: %s\n", opt.Target, err)
+					status.SetText(fmt.Sprintf("Error: unable to resolve %s: %v", opt.Target, err))
+					setRunning(false)
+				default:
+					trace(ctx, cn, opt, ips)
+				}
+			})
+		}()
+	}
+
+	// trace runs traceroute on opt.Target, resolved to ips
+	trace = func(ctx context.Context, cn context.CancelFunc, opt traceroute_wrapper.Options, ips []string) {
+		if ctx.Err() != nil {
+			status.SetText("Stopped.") // while the lookup was returning
+			setRunning(false)
+			return
+		}
+		ip, fam, fellBack := pickFamily(ips, opt.Family)
+		if fellBack {
+			runNote += fmt.Sprintf(" (no IPv%d address, using IPv%d)", opt.Family, fam)
+		}
+		opt.Family = fam
+		tracing = fmt.Sprintf("Tracing %s via IPv%d", opt.Target, fam)
+		if ip != opt.Target {
+			tracing = fmt.Sprintf("Tracing %s [%s] via IPv%d", opt.Target, ip, fam)
+		}
+		// trace the address picked above, not the name: traceroute would
+		// resolve it again and might land on another address or family
+		name := opt.Target
+		opt.Target = ip
 
 		ev, err := traceroute_wrapper.Run(ctx, opt)
 		if err != nil {
 			status.SetText(fmt.Sprintf("Error: %v", err))
+			setRunning(false)
 			return
 		}
 
-		setRunning(true)
-		status.SetText("Running…")
-		tmap.Reset()
-
-		setRunning(true)
+		if cycles > 1 {
+			runNote += fmt.Sprintf(" (run %d)", cycles)
+		}
+		runStarted, lastHop, lastOK = time.Now(), traceroute_wrapper.Hop{}, traceroute_wrapper.Hop{}
+		showProgress()
+		elapsed.Start2()
 
+		exited := runs.Start(cn)
 		go func() {
+			defer exited()
 			for e := range ev {
 				switch e.Kind {
+				case "start":
+					cmd := e.Msg
+					mainthread.Wait(func() {
+						cmdLabel.SetText("Command: " + cmd)
+						cmdLabel.SetVisible(true)
+					})
 				case "hop":
 					h := *e.Hop
 					mainthread.Wait(func() {
-						// table row
-						r := table.RowCount()
-						table.InsertRow(r)
-						table.SetItem(r, 0, qt.NewQTableWidgetItem2(fmt.Sprintf("%d", h.Index)))
-						table.SetItem(r, 1, qt.NewQTableWidgetItem2(h.Addr))
-						if h.RTTms < 0 {
-							table.SetItem(r, 2, qt.NewQTableWidgetItem2("timeout"))
-						} else {
-							table.SetItem(r, 2, qt.NewQTableWidgetItem2(fmt.Sprintf("%.1f", h.RTTms)))
+						if h.Index < 1 || h.Index > hopCap {
+							if dropped++; dropped == 1 {
+								log.Printf("traceroute: ignoring hop %d outside 1..%d: %q\n", h.Index, hopCap, h.Raw)
+							}
+							return
+						}
+						if h.Index >= lastHop.Index {
+							lastHop = h
+						}
+						if h.RTTms >= 0 && h.Addr != "*" && h.Index >= lastOK.Index {
+							lastOK = h
 						}
-						// map
-						tmap.UpsertHop(h.Index, h.Addr, h.RTTms)
+						setHop(h)
+						showProgress()
 					})
 				case "error":
 					msg := e.Msg
 					if e.Err != nil {
 						msg += ": " + e.Err.Error()
 					}
-					mainthread.Wait(func() { status.SetText("Error: " + msg) })
+					mainthread.Wait(func() {
+						elapsed.Stop()
+						status.SetText("Error: " + msg)
+					})
 
 				case "done":
+					canceled := e.Msg == "canceled"
 					mainthread.Wait(func() {
-						status.SetText("Done.")
+						elapsed.Stop()
+						summary, reached := runSummary(opt, name, ips)
+						summary += fmt.Sprintf(" via IPv%d", opt.Family)
+						if dropped > 0 {
+							summary += fmt.Sprintf(" (%d malformed hop lines ignored)", dropped)
+						}
+						tmap.SetDone(reached && !canceled)
+						if canceled {
+							status.SetText(fmt.Sprintf("Stopped after %s s.", formatFloat(time.Since(runStarted).Seconds(), 1)))
+							setRunning(false)
+							return
+						}
+						if repeat.IsChecked() && !stopped {
+							status.SetText(fmt.Sprintf("Run %d: %s. Repeating…", cycles, summary))
+							again.Start2()
+							return
+						}
+						status.SetText(summary + ".")
 						setRunning(false)
-						tmap.SetDone()
 					})
 				}
 			}
 		}()
+	}
+	again.OnTimeout(func() {
+		if !stopped {
+			runOnce(false)
+		}
+	})
+
+	start.OnClicked(func() {
+		if !start.IsEnabled() {
+			return
+		}
+		saveNow()
+
+		tgt := strings.TrimSpace(target.Text())
+		if tgt == "" {
+			status.SetText("Please enter target")
+			return
+		}
+
+		// update config from UI once more before running
+		saveNow()
+		stopped = false
+		runOnce(true)
 	})
 
 	stop.OnClicked(func() {
+		stopped = true
+		if again.IsActive() {
+			// between repeats: nothing running to report "done"
+			again.Stop()
+			status.SetText("Stopped.")
+			setRunning(false)
+		}
 		if cancel != nil {
 			cancel()
 			cancel = nil
 		}
 	})
 
-	return page
+	return page, tmap
+}
+
+// maxTraceHops is opt's hop limit, the wrapper's default when unset.
+func maxTraceHops(opt traceroute_wrapper.Options) int {
+	if opt.MaxHops <= 0 {
+		return 30
+	}
+	return opt.MaxHops
+}
+
+// traceFamilies are the choices of the family combo (traceroute.family).
+var traceFamilies = []struct {
+	family int
+	name   string
+}{
+	{0, "Auto"},
+	{4, "IPv4"},
+	{6, "IPv6"},
+}
+
+// pickFamily picks the address to trace among ips: the first one of the
+// wanted family (4 or 6), or simply the first when want is 0 or the target
+// has no address of that family (fellBack).
+func pickFamily(ips []string, want int) (ip string, family int, fellBack bool) {
+	familyOf := func(a string) int {
+		if p := net.ParseIP(a); p != nil && p.To4() == nil {
+			return 6
+		}
+		return 4
+	}
+	for _, a := range ips {
+		if want == 0 || familyOf(a) == want {
+			return a, familyOf(a), false
+		}
+	}
+	if len(ips) == 0 {
+		return "", want, false
+	}
+	return ips[0], familyOf(ips[0]), true
 }
 
 type TraceHop struct {
 	Hop   int
 	Addr  string
 	RTTms float64 // -1 == timeout
+
+	// answered RTTs over repeated runs (Welford), for the hop's jitter
+	n    int
+	mean float64
+	m2   float64
+}
+
+// add accounts for one more answered RTT.
+func (h *TraceHop) add(rttMs float64) {
+	h.n++
+	d := rttMs - h.mean
+	h.mean += d / float64(h.n)
+	h.m2 += d * (rttMs - h.mean)
+}
+
+// StdDev is the standard deviation of the hop's RTTs; ok is false until
+// it answered at least twice.
+func (h TraceHop) StdDev() (sd float64, ok bool) {
+	if h.n < 2 {
+		return 0, false
+	}
+	return math.Sqrt(h.m2 / float64(h.n-1)), true
 }
 
+// jitterHotMs is the RTT standard deviation at which a path segment is
+// drawn at its hottest and thickest.
+const jitterHotMs = 20.0
+
 type TracerMap struct {
 	qt.QWidget
 
 	margin float64
-	span   int // max hops shown on X (e.g., 30)
+	span   int // hops shown on X; follows the highest hop seen (>= minTraceSpan)
 
 	hops       []TraceHop // ordered by Hop
 	yMax       float64    // dynamic scale
@@ -251,19 +600,141 @@ type TracerMap struct {
 	pulseSpeed float64    // cycles per second (e.g., 0.15 => ~6.7s per loop)
 	lastTick   time.Time  // for dt-based animation
 	anim       *qt.QTimer
+	frameRate  int
 	done       bool
+	reached    bool // last hop is the target (colored as destination)
+	delta      bool // plot per-hop added delay instead of RTT to the hop
+	theme      mapTheme
 
 	mousePos qt.QPoint
 	hoverHop int // -1 if none
 }
 
+// traceReport formats hops in hop order for the clipboard: aligned plain
+// text, or a Markdown table under a heading with target and time.
+func traceReport(target string, at time.Time, hops map[int]traceroute_wrapper.Hop, markdown bool) string {
+	idx := make([]int, 0, len(hops))
+	for i := range hops {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	rtt := func(h traceroute_wrapper.Hop) string {
+		if h.RTTms < 0 {
+			return "timeout"
+		}
+		return strconv.FormatFloat(h.RTTms, 'f', 1, 64) + " ms"
+	}
+
+	var b strings.Builder
+	stamp := at.Format("2006-01-02 15:04:05")
+	if markdown {
+		fmt.Fprintf(&b, "### Traceroute to %s (%s)\n\n", target, stamp)
+		b.WriteString("| Hop | Address | RTT |\n|---:|---|---:|\n")
+		for _, i := range idx {
+			h := hops[i]
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", h.Index, strings.ReplaceAll(h.Addr, "|", `\|`), rtt(h))
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Traceroute to %s (%s)\n", target, stamp)
+	for _, i := range idx {
+		h := hops[i]
+		fmt.Fprintf(&b, "%3d  %-40s %s\n", h.Index, h.Addr, rtt(h))
+	}
+	return b.String()
+}
+
+// minTraceSpan keeps very short paths from stretching into a couple of
+// nodes pinned to the plot edges.
+const minTraceSpan = 5
+
+// mapTheme holds the TracerMap's colors as RGB.
+type mapTheme struct {
+	path, ok, timeout, dst, comet [3]int
+}
+
+var mapPresets = map[string]mapTheme{
+	"neon": {
+		path:    [3]int{90, 180, 255},
+		ok:      [3]int{90, 180, 255},
+		timeout: [3]int{180, 180, 180},
+		dst:     [3]int{120, 255, 170},
+		comet:   [3]int{255, 60, 40},
+	},
+	// Okabe-Ito: blue, grey, yellow and orange stay apart for all common
+	// kinds of color blindness
+	"colorblind": {
+		path:    [3]int{86, 180, 233},
+		ok:      [3]int{86, 180, 233},
+		timeout: [3]int{153, 153, 153},
+		dst:     [3]int{240, 228, 66},
+		comet:   [3]int{230, 159, 0},
+	},
+}
+
+// mapPresetNames lists the presets in combo order: config name, label.
+var mapPresetNames = [][2]string{
+	{"neon", "Neon colors"},
+	{"colorblind", "Colorblind-friendly"},
+}
+
+// mapThemeFrom resolves c into a theme: the preset (neon when empty or
+// unknown) with each valid color set in c replacing the preset's.
+func mapThemeFrom(c MapColors) mapTheme {
+	t, ok := mapPresets[c.Preset]
+	if !ok {
+		t = mapPresets["neon"]
+	}
+	for _, o := range []struct {
+		hex string
+		dst *[3]int
+	}{
+		{c.Path, &t.path},
+		{c.NodeOK, &t.ok},
+		{c.NodeTimeout, &t.timeout},
+		{c.Destination, &t.dst},
+		{c.Comet, &t.comet},
+	} {
+		if o.hex == "" {
+			continue
+		}
+		if rgb, ok := parseHexColor(o.hex); ok {
+			*o.dst = rgb
+		} else {
+			log.Printf("traceroute.colors: ignoring %q, expected #rrggbb\n", o.hex)
+		}
+	}
+	return t
+}
+
+// parseHexColor parses "#rrggbb" (the # is optional).
+func parseHexColor(s string) ([3]int, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) != 6 {
+		return [3]int{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return [3]int{}, false
+	}
+	return [3]int{int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)}, true
+}
+
+// rgba makes a QColor from c; d brightens (or, negative, darkens) each channel.
+func rgba(c [3]int, d, a int) *qt.QColor {
+	ch := func(v int) int { return min(max(v+d, 0), 255) }
+	return qcolor(ch(c[0]), ch(c[1]), ch(c[2]), a)
+}
+
 func NewTracerMap() *TracerMap {
 	g := &TracerMap{}
 	g.QWidget = *qt.NewQWidget(nil)
-	g.SetMinimumSize2(900, 240)
+	sc := dpiScale(g.QPaintDevice)
+	g.SetMinimumSize2(int(900*sc), int(240*sc))
 	g.margin = 36
-	g.span = 30
+	g.span = minTraceSpan
 	g.hoverHop = -1
+	g.theme = mapPresets["neon"]
 
 	g.SetMouseTracking(true)
 	g.OnMouseMoveEvent(func(super func(*qt.QMouseEvent), e *qt.QMouseEvent) {
@@ -285,7 +756,7 @@ func NewTracerMap() *TracerMap {
 			g.lastTick = time.Now()
 			return
 		}
-		// 60 FPS-ish pulse
+		// dt-based, so the pulse speed doesn't depend on the frame rate
 		dt := time.Since(g.lastTick).Seconds()
 		g.lastTick = time.Now()
 		g.pulsePhase += g.pulseSpeed * dt
@@ -293,11 +764,24 @@ func NewTracerMap() *TracerMap {
 		g.pulsePhase -= math.Floor(g.pulsePhase)
 		g.Update()
 	})
-	g.anim.Start(16) // ~60fps
+	g.frameRate = 60
+	g.anim.Start(frameRateToMs(g.frameRate))
 
 	return g
 }
 
+// SetTheme changes the map's colors.
+func (g *TracerMap) SetTheme(t mapTheme) {
+	g.theme = t
+	g.Update()
+}
+
+// SetFrameRate changes the animation rate.
+func (g *TracerMap) SetFrameRate(fps int) {
+	g.frameRate = fps
+	g.anim.SetInterval(frameRateToMs(fps))
+}
+
 // secondsPerLoop: 0 => keep current; otherwise set new speed
 func (g *TracerMap) SetPulseSpeed(secondsPerLoop float64) {
 	if secondsPerLoop <= 0 {
@@ -309,8 +793,10 @@ func (g *TracerMap) SetPulseSpeed(secondsPerLoop float64) {
 func (g *TracerMap) Reset() {
 	g.hops = nil
 	g.yMax = 0
+	g.span = minTraceSpan
 	g.pulsePhase = 0
 	g.done = false
+	g.reached = false
 	g.Update()
 }
 
@@ -319,27 +805,92 @@ func (g *TracerMap) UpsertHop(hop int, addr string, rttMs float64) {
 		if g.hops[i].Hop == hop {
 			g.hops[i].Addr = addr
 			g.hops[i].RTTms = rttMs
+			if rttMs >= 0 {
+				g.hops[i].add(rttMs)
+			}
 			g.recalcY()
 			g.Update()
 			return
 		}
 	}
-	g.hops = append(g.hops, TraceHop{Hop: hop, Addr: addr, RTTms: rttMs})
+	th := TraceHop{Hop: hop, Addr: addr, RTTms: rttMs}
+	if rttMs >= 0 {
+		th.add(rttMs)
+	}
+	g.hops = append(g.hops, th)
 	// keep in hop order (small N, simple bubble insert)
 	for i := len(g.hops) - 1; i > 0 && g.hops[i-1].Hop > g.hops[i].Hop; i-- {
 		g.hops[i-1], g.hops[i] = g.hops[i], g.hops[i-1]
 	}
 	g.recalcY()
+	g.recalcSpan()
+	g.Update()
+}
+
+// SetDone marks the trace finished; reached colors the last hop as the target.
+func (g *TracerMap) SetDone(reached bool) {
+	g.done, g.reached = true, reached
+	g.recalcSpan()
+	g.Update()
+}
+
+// recalcSpan fits the X axis to the highest hop seen so far.
+func (g *TracerMap) recalcSpan() {
+	span := minTraceSpan
+	for _, h := range g.hops {
+		if h.Hop > span {
+			span = h.Hop
+		}
+	}
+	g.span = span
+}
+
+// hopX maps a hop number onto the X axis of the plot.
+func (g *TracerMap) hopX(hop int, left, right float64) float64 {
+	return left + (right-left)*float64(hop-1)/float64(g.span-1)
+}
+
+// SetDeltaMode switches the Y axis between RTT to each hop and the delay
+// each hop adds over the previous responsive one.
+func (g *TracerMap) SetDeltaMode(on bool) {
+	g.delta = on
+	g.recalcY()
 	g.Update()
 }
 
-func (g *TracerMap) SetDone() { g.done = true; g.Update() }
+// hopDeltas returns, per hop, the RTT added since the previous responsive
+// hop (clamped at 0, ECMP can make later hops look faster); -1 for timeouts.
+func hopDeltas(hops []TraceHop) []float64 {
+	out := make([]float64, len(hops))
+	prev := 0.0
+	for i, h := range hops {
+		if h.RTTms < 0 {
+			out[i] = -1
+			continue
+		}
+		out[i] = math.Max(h.RTTms-prev, 0)
+		prev = h.RTTms
+	}
+	return out
+}
+
+// plotValues returns the Y value of every hop for the current mode.
+func (g *TracerMap) plotValues() []float64 {
+	if g.delta {
+		return hopDeltas(g.hops)
+	}
+	out := make([]float64, len(g.hops))
+	for i, h := range g.hops {
+		out[i] = h.RTTms
+	}
+	return out
+}
 
 func (g *TracerMap) recalcY() {
 	max := 1.0
-	for _, h := range g.hops {
-		if h.RTTms > max {
-			max = h.RTTms
+	for _, v := range g.plotValues() {
+		if v > max {
+			max = v
 		}
 	}
 	// headroom + round to nice top (0/5/10 …)
@@ -384,11 +935,18 @@ func (g *TracerMap) paint() {
 	bg := g.Palette().ColorWithCr(qt.QPalette__Window)
 	fg := g.Palette().ColorWithCr(qt.QPalette__WindowText)
 	grid := qt.NewQColor()
-	grid.SetRgb2(fg.Red(), fg.Green(), fg.Blue(), 80)
+	grid.SetRgb2(fg.Red(), fg.Green(), fg.Blue(), contrastAlpha(80))
 	subtle := qt.NewQColor()
-	subtle.SetRgb2(fg.Red(), fg.Green(), fg.Blue(), 140)
+	subtle.SetRgb2(fg.Red(), fg.Green(), fg.Blue(), contrastAlpha(140))
 
 	p.FillRect4(qt.NewQRectF4(0, 0, W, H), bg)
+	if len(g.hops) == 0 {
+		paintNoData(p, W, H, fg, noDataText)
+		return
+	}
+
+	sc := dpiScale(g.QPaintDevice)
+	margin := g.margin * sc
 
 	fm := qt.NewQFontMetricsF(p.Font())
 	// measure widest Y label among our ticks
@@ -396,7 +954,7 @@ func (g *TracerMap) paint() {
 	maxYLabelW := 0.0
 	for i := 0; i <= yticks; i++ {
 		v := g.yMax * float64(i) / float64(yticks)
-		s := fmt.Sprintf("%.0f ms", v)
+		s := formatFloat(v, 0) + " ms"
 		if w := fm.Width(s); w > maxYLabelW {
 			maxYLabelW = w
 		}
@@ -407,12 +965,17 @@ func (g *TracerMap) paint() {
 	axisTitleGap := 10.0
 	titleRotWidth := fm.Width("ms") // rotated height ≈ unrotated width
 
-	left := g.margin + maxYLabelW + yLabelGap + titleRotWidth + axisTitleGap
-	right := W - g.margin
-	bottom := H - g.margin - (fm.Height() + 10)
-	top := g.margin
-	if right-left < 40 || bottom-top < 40 {
-		return
+	left := margin + maxYLabelW + yLabelGap + titleRotWidth + axisTitleGap
+	right := W - margin
+	bottom := H - margin - (fm.Height() + 10)
+	top := margin
+	// too small for axes and labels: just path and nodes, down to tinyPlotPx
+	compact := right-left < minPlotPx || bottom-top < minPlotPx
+	if compact {
+		left, top, right, bottom = compactPad, compactPad, W-compactPad, H-compactPad
+		if right-left < tinyPlotPx || bottom-top < tinyPlotPx {
+			return
+		}
 	}
 	plot := qt.NewQRectF4(left, top, right-left, bottom-top)
 	// --- Y grid + labels ---
@@ -429,54 +992,58 @@ func (g *TracerMap) paint() {
 	}
 	p.Restore()
 
-	// labels
-	p.SetPen(fg)
-	for i := 0; i <= yticks; i++ {
-		v := g.yMax * float64(i) / float64(yticks)
-		y := top + (bottom-top)*(1-v/g.yMax)
-		lbl := qt.NewQStaticText2(fmt.Sprintf("%.0f ms", v))
-		p.DrawStaticText2(qt.NewQPoint2(int(left-fm.Width(lbl.Text())-8), int(y-fm.Height()/2)), lbl)
+	if !compact {
+		// labels
+		p.SetPen(fg)
+		for i := 0; i <= yticks; i++ {
+			v := g.yMax * float64(i) / float64(yticks)
+			y := top + (bottom-top)*(1-v/g.yMax)
+			lbl := qt.NewQStaticText2(formatFloat(v, 0) + " ms")
+			p.DrawStaticText2(qt.NewQPoint2(int(left-fm.Width(lbl.Text())-8), int(y-fm.Height()/2)), lbl)
+		}
+		// vertical axis title "ms" — placed left of the labels, no overlap
+		p.Save()
+		title := "ms"
+		tx := left - maxYLabelW - yLabelGap - axisTitleGap - titleRotWidth/2
+		ty := (top + bottom) / 2
+		p.Translate2(tx, ty)
+		p.Rotate(-90)
+		p.SetPen(fg)
+		p.DrawStaticText2(qt.NewQPoint2(0, 0), qt.NewQStaticText2(title))
+		p.Restore()
 	}
-	// vertical axis title "ms" — placed left of the labels, no overlap
-	p.Save()
-	title := "ms"
-	tx := left - maxYLabelW - yLabelGap - axisTitleGap - titleRotWidth/2
-	ty := (top + bottom) / 2
-	p.Translate2(tx, ty)
-	p.Rotate(-90)
-	p.SetPen(fg)
-	p.DrawStaticText2(qt.NewQPoint2(0, 0), qt.NewQStaticText2(title))
-	p.Restore()
 
 	// --- X grid (hop numbers) ---
 	p.Save()
 	p.SetClipRect3(plot, qt.ReplaceClip)
 	p.SetPen(grid)
 	for hop := 1; hop <= g.span; hop++ {
-		x := left + (right-left)*float64(hop-1)/float64(g.span-1)
+		x := g.hopX(hop, left, right)
 		path := qt.NewQPainterPath2(qt.NewQPointF3(x, top))
 		path.LineTo(qt.NewQPointF3(x, bottom))
 		p.DrawPath(path)
 	}
 	p.Restore()
-	// X labels (clamped, non-overlap)
-	prevR := left - 6
-	for hop := 1; hop <= g.span; hop++ {
-		t := fmt.Sprintf("%d", hop)
-		tw := fm.Width(t)
-		x := left + (right-left)*float64(hop-1)/float64(g.span-1)
-		pos := x - tw/2
-		if pos < left {
-			pos = left
-		}
-		if pos+tw > right {
-			pos = right - tw
-		}
-		if pos < prevR+6 {
-			continue
+	if !compact {
+		// X labels (clamped, non-overlap)
+		prevR := left - 6
+		for hop := 1; hop <= g.span; hop++ {
+			t := fmt.Sprintf("%d", hop)
+			tw := fm.Width(t)
+			x := g.hopX(hop, left, right)
+			pos := x - tw/2
+			if pos < left {
+				pos = left
+			}
+			if pos+tw > right {
+				pos = right - tw
+			}
+			if pos < prevR+6 {
+				continue
+			}
+			p.DrawStaticText2(qt.NewQPoint2(int(pos), int(bottom+4)), qt.NewQStaticText2(t))
+			prevR = pos + tw
 		}
-		p.DrawStaticText2(qt.NewQPoint2(int(pos), int(bottom+4)), qt.NewQStaticText2(t))
-		prevR = pos + tw
 	}
 
 	// --- Path & nodes ---
@@ -484,73 +1051,112 @@ func (g *TracerMap) paint() {
 	p.SetClipRect3(plot, qt.ReplaceClip)
 
 	// neon path pen
-	neon := qt.NewQColor()
-	neon.SetRgb2(90, 180, 255, 220)
-	pen := qt.NewQPen3(neon)
+	pen := qt.NewQPen3(rgba(g.theme.path, 0, 220))
 	pen.SetCosmetic(true)
-	pen.SetWidthF(2.2)
+	pen.SetWidthF(lineWidth(2.2 * sc))
 	p.SetPenWithPen(pen)
 
-	// Build polyline through OK hops (timeouts break the line)
-	var path *qt.QPainterPath
-	var have bool
-	for _, hhop := range g.hops {
-		if hhop.RTTms < 0 {
-			if have && path != nil {
+	vals := g.plotValues()
+	deltas := hopDeltas(g.hops) // tooltip shows both RTT and delta
+
+	// Once repeated runs give hops a spread, each segment is drawn hotter
+	// and thicker by the jitter of the hop it leads to; until then the
+	// path is uniform.
+	jittery := false
+	for _, h := range g.hops {
+		if _, ok := h.StdDev(); ok {
+			jittery = true
+			break
+		}
+	}
+	if jittery {
+		prev := -1
+		for i, hhop := range g.hops {
+			if hhop.RTTms < 0 {
+				prev = -1
+				continue
+			}
+			if prev >= 0 {
+				t := 0.0
+				if sd, ok := hhop.StdDev(); ok {
+					t = min(sd/jitterHotMs, 1)
+				}
+				var c [3]int
+				for k := range c {
+					c[k] = g.theme.path[k] + int(t*float64(g.theme.timeout[k]-g.theme.path[k]))
+				}
+				seg := qt.NewQPen3(rgba(c, 0, 220))
+				seg.SetCosmetic(true)
+				seg.SetWidthF(lineWidth(2.2 * sc * (1 + 1.5*t)))
+				p.SetPenWithPen(seg)
+				a := g.hops[prev]
+				path := qt.NewQPainterPath2(qt.NewQPointF3(g.hopX(a.Hop, left, right), top+(bottom-top)*(1-vals[prev]/g.yMax)))
+				path.LineTo(qt.NewQPointF3(g.hopX(hhop.Hop, left, right), top+(bottom-top)*(1-vals[i]/g.yMax)))
 				p.DrawPath(path)
-				have, path = false, nil
 			}
-			continue
+			prev = i
 		}
-		x := left + (right-left)*float64(hhop.Hop-1)/float64(g.span-1)
-		y := top + (bottom-top)*(1-hhop.RTTms/g.yMax)
-		if !have {
-			path = qt.NewQPainterPath2(qt.NewQPointF3(x, y))
-			have = true
-		} else {
-			path.LineTo(qt.NewQPointF3(x, y))
+	} else {
+		// Build polyline through OK hops (timeouts break the line)
+		var path *qt.QPainterPath
+		var have bool
+		for i, hhop := range g.hops {
+			if hhop.RTTms < 0 {
+				if have && path != nil {
+					p.DrawPath(path)
+					have, path = false, nil
+				}
+				continue
+			}
+			x := g.hopX(hhop.Hop, left, right)
+			y := top + (bottom-top)*(1-vals[i]/g.yMax)
+			if !have {
+				path = qt.NewQPainterPath2(qt.NewQPointF3(x, y))
+				have = true
+			} else {
+				path.LineTo(qt.NewQPointF3(x, y))
+			}
+		}
+		if have && path != nil {
+			p.DrawPath(path)
 		}
-	}
-	if have && path != nil {
-		p.DrawPath(path)
 	}
 
 	// Nodes
-	okFill := qt.NewQColor()
-	okFill.SetRgb2(90, 180, 255, 255)
-	toFill := qt.NewQColor()
-	toFill.SetRgb2(180, 180, 180, 255)
-	dstFill := qt.NewQColor()
-	dstFill.SetRgb2(120, 255, 170, 255)
+	okFill := rgba(g.theme.ok, 0, 255)
+	toFill := rgba(g.theme.timeout, 0, 255)
+	dstFill := rgba(g.theme.dst, 0, 255)
 
 	// Track hovered hop to paint tooltip later (top-most, no clip)
 	var hovered *TraceHop
+	var hoveredDelta float64
 	var hoveredX, hoveredY float64
 	for i, hhop := range g.hops {
-		x := left + (right-left)*float64(hhop.Hop-1)/float64(g.span-1)
+		x := g.hopX(hhop.Hop, left, right)
 		var y float64
 		if hhop.RTTms < 0 {
 			y = bottom - 2 // timeouts sit near baseline
 		} else {
-			y = top + (bottom-top)*(1-hhop.RTTms/g.yMax)
+			y = top + (bottom-top)*(1-vals[i]/g.yMax)
 		}
 
-		r := 4.0
+		r := 4.0 * sc
 		rect := qt.NewQRectF4(x-r, y-r, 2*r, 2*r)
 
 		// glow halo
 		halo := qt.NewQColor()
-		if i == len(g.hops)-1 && g.done && hhop.RTTms >= 0 {
+		if i == len(g.hops)-1 && g.done && g.reached && hhop.RTTms >= 0 {
 			halo.SetRgb2(dstFill.Red(), dstFill.Green(), dstFill.Blue(), 80)
 		} else if hhop.RTTms >= 0 {
 			halo.SetRgb2(okFill.Red(), okFill.Green(), okFill.Blue(), 70)
 		} else {
 			halo.SetRgb2(toFill.Red(), toFill.Green(), toFill.Blue(), 60)
 		}
-		p.FillRect4(qt.NewQRectF4(rect.X()-2, rect.Y()-2, rect.Width()+4, rect.Height()+4), halo)
+		hr := 2 * sc
+		p.FillRect4(qt.NewQRectF4(rect.X()-hr, rect.Y()-hr, rect.Width()+2*hr, rect.Height()+2*hr), halo)
 
 		// core
-		if i == len(g.hops)-1 && g.done && hhop.RTTms >= 0 {
+		if i == len(g.hops)-1 && g.done && g.reached && hhop.RTTms >= 0 {
 			p.FillRect4(rect, dstFill)
 		} else if hhop.RTTms >= 0 {
 			p.FillRect4(rect, okFill)
@@ -561,20 +1167,24 @@ func (g *TracerMap) paint() {
 		// hover label
 		if g.hoverHop == hhop.Hop {
 			hovered = &g.hops[i]
+			hoveredDelta = deltas[i]
 			hoveredX, hoveredY = x, y
 		}
 	}
 	p.Restore()
+	if compact {
+		return // no room for the comet or a tooltip
+	}
 
 	// --- Comet pulse (head + tapered tail) ---
 	if len(g.hops) >= 2 {
 		// collect OK hops as points
 		type pt struct{ x, y float64 }
 		pts := []pt{}
-		for _, h := range g.hops {
+		for i, h := range g.hops {
 			if h.RTTms >= 0 {
-				x := left + (right-left)*float64(h.Hop-1)/float64(g.span-1)
-				y := top + (bottom-top)*(1-h.RTTms/g.yMax)
+				x := g.hopX(h.Hop, left, right)
+				y := top + (bottom-top)*(1-vals[i]/g.yMax)
 				pts = append(pts, pt{x, y})
 			}
 		}
@@ -636,11 +1246,11 @@ func (g *TracerMap) paint() {
 				p.SetPenWithPen(noPen)
 
 				// ---- Tail: draw N samples behind the head with fading alpha and widening radius
-				tailLen := 80.0 // total tail length in pixels along the path
-				samples := 18   // number of dabs in tail
-				baseR := 2.0    // smallest tail radius at the very end
-				headR := 6.0    // radius near the head (tail blends into head)
-				maxAlpha := 160 // max opacity near head for tail dabs
+				tailLen := 80.0 * sc // total tail length in pixels along the path
+				samples := 18        // number of dabs in tail
+				baseR := 2.0 * sc    // smallest tail radius at the very end
+				headR := 6.0 * sc    // radius near the head (tail blends into head)
+				maxAlpha := 160      // max opacity near head for tail dabs
 
 				for i := 0; i < samples; i++ {
 					// s goes from 0 (near head) to 1 (tail end)
@@ -658,47 +1268,39 @@ func (g *TracerMap) paint() {
 						alpha = 0
 					}
 
-					// warm red tail (slightly dimmer than head)
-					clr := qt.NewQColor()
-					clr.SetRgb2(255, 60, 40, alpha)
-					p.SetBrush(qt.NewQBrush3(clr))
+					// tail in the comet color (slightly dimmer than head)
+					p.SetBrush(qt.NewQBrush3(rgba(g.theme.comet, 0, alpha)))
 
 					p.DrawEllipse(qt.NewQRectF4(tx-r, ty-r, 2*r, 2*r))
 				}
 
-				// ---- Head: bright core + red mantle + subtle forward “flare”
+				// ---- Head: bright core + mantle + subtle forward “flare”
 				// Forward flare: a tiny elongated dab in the direction of travel
 				if dv > 0 {
-					fl := 8.0 // flare length
-					fw := 4.0 // flare width (radius)
+					fl := 8.0 * sc // flare length
+					fw := 4.0 * sc // flare width (radius)
 					fx := px + ux*fl*0.5
 					fy := py + uy*fl*0.5
 
-					flClr := qt.NewQColor()
-					flClr.SetRgb2(255, 80, 60, 120)
-					p.SetBrush(qt.NewQBrush3(flClr))
+					p.SetBrush(qt.NewQBrush3(rgba(g.theme.comet, 20, 120)))
 					// Draw two overlapping ellipses along the direction to fake elongation
 					p.DrawEllipse(qt.NewQRectF4(fx-fw, fy-fw, 2*fw, 2*fw))
 					p.DrawEllipse(qt.NewQRectF4(px-fw, py-fw, 2*fw, 2*fw))
 				}
 
-				// Red mantle (soft)
-				mantle := qt.NewQColor()
-				mantle.SetRgb2(255, 40, 20, 200)
-				p.SetBrush(qt.NewQBrush3(mantle))
-				p.DrawEllipse(qt.NewQRectF4(px-7, py-7, 14, 14))
+				// Mantle (soft), a shade darker than the tail
+				p.SetBrush(qt.NewQBrush3(rgba(g.theme.comet, -20, 200)))
+				p.DrawEllipse(qt.NewQRectF4(px-7*sc, py-7*sc, 14*sc, 14*sc))
 
 				// Bright white core
 				core := qt.NewQColor()
 				core.SetRgb2(255, 255, 255, 255)
 				p.SetBrush(qt.NewQBrush3(core))
-				p.DrawEllipse(qt.NewQRectF4(px-3.5, py-3.5, 7, 7))
+				p.DrawEllipse(qt.NewQRectF4(px-3.5*sc, py-3.5*sc, 7*sc, 7*sc))
 
 				// Soft outer glow
-				glow := qt.NewQColor()
-				glow.SetRgb2(255, 60, 30, 80)
-				p.SetBrush(qt.NewQBrush3(glow))
-				p.DrawEllipse(qt.NewQRectF4(px-11, py-11, 22, 22))
+				p.SetBrush(qt.NewQBrush3(rgba(g.theme.comet, 0, 80)))
+				p.DrawEllipse(qt.NewQRectF4(px-11*sc, py-11*sc, 22*sc, 22*sc))
 
 				p.Restore()
 			}
@@ -707,12 +1309,23 @@ func (g *TracerMap) paint() {
 
 	// --- Hover tooltip (draw last, NO CLIP, so it's always on top) ---
 	if hovered != nil {
-		lbl := fmt.Sprintf("hop %d  %s \n%.1f ms", hovered.Hop, hovered.Addr, hovered.RTTms)
+		rttLine := formatFloat(hovered.RTTms, 1) + " ms (+" + formatFloat(hoveredDelta, 1) + " ms this hop)"
+		lbl := fmt.Sprintf("hop %d  %s \n%s", hovered.Hop, hovered.Addr, rttLine)
 		if hovered.RTTms < 0 {
 			lbl = fmt.Sprintf("hop %d  %s\n timeout", hovered.Hop, hovered.Addr)
 		}
-		bw, bh := 200.0, 44.0
-		bx, by := hoveredX+10, hoveredY-22
+		// box sized from the font so it scales with DPI
+		textW := maxf(fm.Width(fmt.Sprintf("hop %d  %s ", hovered.Hop, hovered.Addr)), fm.Width(rttLine))
+		lines := 2
+		if sd, ok := hovered.StdDev(); ok {
+			jit := fmt.Sprintf("jitter ±%s ms over %d replies", formatFloat(sd, 1), hovered.n)
+			lbl += "\n" + jit
+			textW = maxf(textW, fm.Width(jit))
+			lines++
+		}
+		bw := maxf(200*sc, textW+12)
+		bh := float64(lines)*fm.Height() + 12
+		bx, by := hoveredX+10, hoveredY-bh/2
 		if bx+bw > right {
 			bx = right - bw
 		}
@@ -720,9 +1333,9 @@ func (g *TracerMap) paint() {
 			bx = left
 		}
 		if by < top {
-			by = hoveredY + 12
+			by = hoveredY + 12*sc
 		}
-		p.FillRect4(qt.NewQRectF4(bx, by, bw, bh), qcolor(0, 0, 0, 170))
+		p.FillRect4(qt.NewQRectF4(bx, by, bw, bh), qcolor(0, 0, 0, contrastAlpha(170)))
 		// tooltip text color
 		p.SetPen(qcolor(255, 255, 255, 220))
 		p.DrawStaticText2(qt.NewQPoint2(int(bx+6), int(by+6)), qt.NewQStaticText2(lbl))
@@ -734,22 +1347,117 @@ func (g *TracerMap) hitTestHop(mx, my float64) int {
 		return -1
 	}
 	fm := qt.NewQFontMetricsF(g.Font())
-	left := g.margin + fm.Width("1000 ms") + 10 + fm.Height() + 6
-	right := float64(g.Width()) - g.margin
-	bottom := float64(g.Height()) - g.margin - (fm.Height() + 10)
-	top := g.margin
-
-	for _, h := range g.hops {
-		x := left + (right-left)*float64(h.Hop-1)/float64(g.span-1)
+	sc := dpiScale(g.QPaintDevice)
+	margin := g.margin * sc
+	left := margin + fm.Width("1000 ms") + 10 + fm.Height() + 6
+	right := float64(g.Width()) - margin
+	bottom := float64(g.Height()) - margin - (fm.Height() + 10)
+	top := margin
+
+	vals := g.plotValues()
+	for i, h := range g.hops {
+		x := g.hopX(h.Hop, left, right)
 		var y float64
 		if h.RTTms < 0 {
 			y = bottom - 2
 		} else {
-			y = top + (bottom-top)*(1-h.RTTms/g.yMax)
+			y = top + (bottom-top)*(1-vals[i]/g.yMax)
 		}
-		if math.Hypot(mx-x, my-y) <= 8 {
+		if math.Hypot(mx-x, my-y) <= 8*sc {
 			return h.Hop
 		}
 	}
 	return -1
 }
+
+// traceHistoryCap is how many cycles of RTTs a hop's sparkline keeps.
+const traceHistoryCap = 60
+
+// HopSparkline is the small per-hop RTT history shown in the traceroute table
+// while repeating. Oldest cycle left; timeouts are red ticks.
+type HopSparkline struct {
+	qt.QWidget
+
+	ring *Ring
+	buf  []Sample
+}
+
+func NewHopSparkline() *HopSparkline {
+	s := &HopSparkline{ring: monitor.NewRing(traceHistoryCap)}
+	s.QWidget = *qt.NewQWidget(nil)
+	sc := dpiScale(s.QPaintDevice)
+	s.SetMinimumSize2(int(120*sc), int(14*sc))
+	s.SetToolTip(fmt.Sprintf("RTT over the last %d runs", traceHistoryCap))
+	s.OnPaintEvent(func(super func(*qt.QPaintEvent), e *qt.QPaintEvent) { s.paint() })
+	return s
+}
+
+// Add records one run's result for the hop (rttMs < 0 == timeout).
+func (s *HopSparkline) Add(rttMs float64) {
+	st := SampleOK
+	if rttMs < 0 {
+		st = SampleLoss
+	}
+	s.ring.Push(Sample{T: time.Now(), MS: rttMs, State: st})
+	s.Update()
+}
+
+func (s *HopSparkline) paint() {
+	s.buf = s.ring.Snapshot(s.buf)
+	w, h := float64(s.Width()), float64(s.Height())
+	if len(s.buf) == 0 || w <= 4 || h <= 4 {
+		return
+	}
+	p := qt.NewQPainter()
+	if !p.Begin(s.QPaintDevice) {
+		return
+	}
+	defer p.End()
+	p.SetRenderHint2(qt.QPainter__Antialiasing, true)
+
+	// scaled to this hop's own worst RTT, so jitter shows even on far hops
+	maxMS := 0.0
+	for _, smp := range s.buf {
+		maxMS = maxf(maxMS, smp.MS)
+	}
+	if maxMS <= 0 {
+		maxMS = 1
+	}
+	const pad = 2.0
+	step := (w - 2*pad) / float64(traceHistoryCap-1)
+	x0 := w - pad - step*float64(len(s.buf)-1) // newest sits at the right edge
+	yOf := func(ms float64) float64 { return h - pad - (h-2*pad)*ms/maxMS }
+
+	linePen := qt.NewQPen3(seriesColor(0))
+	linePen.SetCosmetic(true)
+	linePen.SetWidthF(lineWidth(1.2))
+	lossPen := qt.NewQPen3(stateColor(palette.bad, 255))
+	lossPen.SetCosmetic(true)
+
+	var path *qt.QPainterPath
+	flush := func() {
+		if path != nil {
+			p.SetPenWithPen(linePen)
+			p.DrawPath(path)
+			path = nil
+		}
+	}
+	for i, smp := range s.buf {
+		x := x0 + step*float64(i)
+		if smp.MS < 0 {
+			flush()
+			tk := qt.NewQPainterPath2(qt.NewQPointF3(x, pad))
+			tk.LineTo(qt.NewQPointF3(x, h-pad))
+			p.SetPenWithPen(lossPen)
+			p.DrawPath(tk)
+			continue
+		}
+		if path == nil {
+			path = qt.NewQPainterPath2(qt.NewQPointF3(x, yOf(smp.MS)))
+			path.LineTo(qt.NewQPointF3(x+0.5, yOf(smp.MS))) // lone points stay visible
+		} else {
+			path.LineTo(qt.NewQPointF3(x, yOf(smp.MS)))
+		}
+	}
+	flush()
+}
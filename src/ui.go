@@ -21,14 +21,26 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/e1z0/speedping/internal/dns"
+	"github.com/e1z0/speedping/internal/hostlist"
 	"github.com/e1z0/speedping/internal/iperf"
+	"github.com/e1z0/speedping/internal/monitor"
+	"github.com/e1z0/speedping/internal/pubip"
 	"github.com/mappu/miqt/qt"
+	"github.com/mappu/miqt/qt/mainthread"
 )
 
 // general ui unit
@@ -36,29 +48,128 @@ import (
 type UI struct {
 	main   *qt.QMainWindow
 	graph  *GraphWidget
+	heat   *HeatmapWidget // graph.heatmap, see heatmap.go
+	matrix *PingMatrix    // the Matrix tab, see matrix.go
 	model  *AppModel
 	cancel context.CancelFunc
 
 	backend ProbingBackend
 	running bool
+	// runCtx is the running session's context; each host probes under a
+	// child of it, cancelled alone when the host is paused (see pause.go).
+	// hostRuns outlives sessions so a restarted host can wait for its last
+	// prober (see hostrun.go).
+	runCtx   context.Context
+	hostRuns map[*Host]*hostRun
+	customOn bool // ping.custom_probes, as of the last start
 
 	// widgets we need to toggle
 	btnStart *qt.QPushButton
 	btnStop  *qt.QPushButton
+	btnOnce  *qt.QPushButton // one probe per host, independent of Start/Stop
+	btnBurst *qt.QPushButton // bounded back-to-back burst, see burst.go
+	btnMTU   *qt.QPushButton // path MTU search, see mtu.go
 
 	hostName *qt.QLineEdit
 	hostAddr *qt.QLineEdit
 	btnAdd   *qt.QPushButton
-	btnRem   *qt.QPushButton
-	hostList *qt.QListWidget
+	chkBoth  *qt.QCheckBox // add hostnames as separate IPv4 and IPv6 series
+	btnRes   *qt.QPushButton
+	resStop  context.CancelFunc // non-nil while a resolve preview runs
+
+	ptrCache  map[string]string // reverse DNS names by address, see rdns.go
+	ptrBusy   map[string]bool   // addresses being looked up
+	btnRem    *qt.QPushButton
+	btnExpSel *qt.QPushButton // CSV of the selected hosts' samples
+	hostList  *qt.QListWidget
 
 	intSlider *qt.QSlider
 	intLabel  *qt.QLabel
+	rateLabel *qt.QLabel    // "N hosts × interval = M packets/sec"
+	srcPick   *sourcePicker // ping.source, see source.go
+
+	chkLog       *qt.QCheckBox
+	chkLoss      *qt.QCheckBox
+	chkWorst     *qt.QCheckBox
+	chkAdaptive  *qt.QCheckBox
+	chkStrip     *qt.QCheckBox
+	chkHeat      *qt.QCheckBox
+	chkThrough   *qt.QCheckBox // graph.connect_loss
+	flapSpin     *qt.QSpinBox
+	stopMin      *qt.QSpinBox // stop conditions, see armStop
+	stopSamples  *qt.QSpinBox
+	stopLoss     *qt.QSpinBox
+	stopWatch    *monitor.StopWatch // nil unless a started session has one
+	chkSummary   *qt.QCheckBox      // ping.summary_on_stop, see summary.go
+	sessionStart time.Time          // when Start was clicked, zero once summarised
+	chkOverlay   *qt.QCheckBox      // toggles the session overlay once one is loaded
+	unsubStop    func()
+	sampleLog    *SampleLogger // nil unless ping.log_samples
+	unsubSample  func()
+
+	// gateway-vs-internet diagnosis (see diagnose.go)
+	btnDiag    *qt.QPushButton
+	diagBanner *qt.QLabel
+	diagTimer  *qt.QTimer
+	diagGW     *Host
+	diagNet    *Host
+
+	runLabel     *qt.QLabel // "Running for … since …" next to Start/Stop
+	runningSince time.Time  // when pinging last started, zero while stopped
+
+	health      *qt.QLabel // overall health badge above the ping graph
+	healthTimer *qt.QTimer // also drives alert evaluation
+
+	pubIPLabel *qt.QLabel
+	pubIPBusy  bool
+
+	metrics     *MetricsServer // nil unless metrics.enabled
+	api         *APIServer     // nil unless api.enabled
+	alerter     *Alerter
+	exportTimer *qt.QTimer // nil unless auto_export.enabled
+	runs        childRuns  // iperf3/traceroute processes to reap on close
+
+	// everything that repaints on a timer (see applyFrameRate)
+	animated []interface{ SetFrameRate(fps int) }
+	fpsCombo *qt.QComboBox
+	chkSaver *qt.QCheckBox
+	chkComma *qt.QCheckBox // display.decimal_comma
+	palCombo *qt.QComboBox // display.palette
+	chkHiCon *qt.QCheckBox // display.high_contrast
+
+	// mini mode (see minimode.go)
+	tabs      *qt.QTabWidget
+	pingTop   *qt.QWidget // host list and controls above the ping graph
+	btnMini   *qt.QPushButton
+	mini      bool
+	fullGeom  WindowConfig // saved while mini, restored on the way back
+	miniGeom  WindowConfig
+	miniNext  WindowConfig // geometry miniTimer applies
+	miniTimer *qt.QTimer
+
+	// detached graph (see detach.go)
+	btnDetach *qt.QPushButton
+	detached  bool
+	graphWin  *qt.QMainWindow // created on the first detach
+	graphBox  *qt.QVBoxLayout // the graph's slot in the ping tab
+	graphNote *qt.QWidget     // shown in that slot while detached
+	graphGeom WindowConfig
 }
 
+// frameRates offered in the status bar; saverFrameRate is used while the
+// window is in the background with CPU saver on.
+var frameRates = []int{5, 15, 30, 60}
+
+const saverFrameRate = 2
+
 func NewUI(model *AppModel) *UI {
 	ui := &UI{model: model}
 	cfg := ui.model.Config()
+	if cfg != nil {
+		// before any graph builds its pens
+		setPalette(cfg.View.Palette)
+		highContrast = cfg.View.HighContrast
+	}
 
 	ui.main = qt.NewQMainWindow(nil)
 	ui.main.SetWindowTitle("SpeedPing")
@@ -66,6 +177,7 @@ func NewUI(model *AppModel) *UI {
 
 	// ---- TABS ----
 	tabs := qt.NewQTabWidget(nil)
+	ui.tabs = tabs
 
 	// ---------- PING TAB ----------
 	pingPage := qt.NewQWidget(nil)
@@ -83,20 +195,48 @@ func NewUI(model *AppModel) *UI {
 
 	ui.hostList = qt.NewQListWidget(nil)
 	ui.hostList.SetUniformItemSizes(true)
-	ui.hostList.SetMinimumWidth(200)
-	ui.hostList.SetMaximumWidth(240)
-	rowH := ui.hostList.FontMetrics().Height() + 6
-	ui.hostList.SetFixedHeight(rowH*5 + 12)
+	whenScaled(func() {
+		ui.hostList.SetMinimumWidth(scaledPx(200))
+		ui.hostList.SetMaximumWidth(scaledPx(240))
+		rowH := ui.hostList.FontMetrics().Height() + 6
+		ui.hostList.SetFixedHeight(rowH*5 + 12)
+	})
 
 	for _, h := range model.Hosts() {
-		ui.hostList.AddItem(fmt.Sprintf("%s (%s)", h.Name, h.Addr))
+		ui.appendHostItem(h)
 	}
 
+	ui.hostList.SetSelectionMode(qt.QAbstractItemView__ExtendedSelection)
+
 	ui.btnRem = qt.NewQPushButton(nil)
 	ui.btnRem.SetText("Remove selected")
+	ui.btnRem.SetToolTip("Ctrl- or Shift-click to select several hosts")
+	btnRemAll := qt.NewQPushButton(nil)
+	btnRemAll.SetText("Remove all")
+	ui.btnExpSel = qt.NewQPushButton(nil)
+	ui.btnExpSel.SetText("Export selected…")
+	ui.btnExpSel.SetToolTip("Save the samples of the selected hosts as CSV")
+	ui.btnExpSel.SetEnabled(false)
+	ui.btnExpSel.OnClicked(func() { ui.exportSelectedCSV() })
 
 	leftCol.AddWidget(ui.hostList.QWidget)
-	leftCol.AddWidget(ui.btnRem.QWidget)
+	rowRem := qt.NewQHBoxLayout(nil)
+	rowRem.AddWidget(ui.btnRem.QWidget)
+	rowRem.AddWidget(btnRemAll.QWidget)
+	rowRem.AddWidget(ui.btnExpSel.QWidget)
+	leftCol.AddLayout(rowRem.QLayout)
+	btnRemAll.OnClicked(func() {
+		n := ui.model.Count()
+		if n == 0 || qt.QMessageBox_Question(ui.main.QWidget, "Remove all",
+			fmt.Sprintf("Remove all %d hosts?", n)) != qt.QMessageBox__Yes {
+			return
+		}
+		rows := make([]int, n)
+		for i := range rows {
+			rows[i] = i
+		}
+		ui.removeHosts(rows)
+	})
 
 	// RIGHT: controls stacked vertically
 	rightPane := qt.NewQWidget(nil)
@@ -108,22 +248,63 @@ func NewUI(model *AppModel) *UI {
 	ui.hostName = qt.NewQLineEdit(nil)
 	ui.hostName.SetPlaceholderText("Display name (optional)")
 	ui.hostAddr = qt.NewQLineEdit(nil)
-	ui.hostAddr.SetPlaceholderText("Host/IP (e.g., 1.1.1.1)")
+	ui.hostAddr.SetPlaceholderText("Host/IP (e.g., 1.1.1.1; several: comma/space separated)")
+	presets := newPresetCombo(func(name, addr string) {
+		ui.hostName.SetText(name)
+		ui.hostAddr.SetText(addr)
+	})
 
 	ui.btnAdd = qt.NewQPushButton(nil)
 	ui.btnAdd.SetText("Add host")
+	ui.btnRes = qt.NewQPushButton(nil)
+	ui.btnRes.SetText("Resolve")
+	ui.btnRes.SetToolTip("Look the address up and show what it resolves to, without adding it")
+	ui.chkBoth = qt.NewQCheckBox4("v4+v6", nil)
+	ui.chkBoth.SetToolTip("Resolve host names and monitor their IPv4 and IPv6 addresses as two series")
 
 	ui.btnStart = qt.NewQPushButton(nil)
 	ui.btnStart.SetText("Start")
 	ui.btnStop = qt.NewQPushButton(nil)
 	ui.btnStop.SetText("Stop")
+	ui.btnOnce = qt.NewQPushButton(nil)
+	ui.btnOnce.SetText("Ping once")
+	ui.btnOnce.SetToolTip("Send a single ping to every host and report who answers")
+	ui.btnBurst = qt.NewQPushButton(nil)
+	ui.btnBurst.SetText("Burst…")
+	ui.btnBurst.SetToolTip("Send a short burst of pings to one host as fast as possible and report min/avg/max/loss")
+	ui.btnMTU = qt.NewQPushButton(nil)
+	ui.btnMTU.SetText("MTU…")
+	ui.btnMTU.SetToolTip("Find the largest ping that reaches one host without being fragmented (the path MTU)")
+	ui.btnDiag = qt.NewQPushButton(nil)
+	ui.btnDiag.SetText("Diagnose")
+	ui.btnDiag.SetToolTip("Ping your gateway and a public target side by side to tell local from upstream problems")
 
 	rowAdd.AddWidget(ui.hostName.QWidget)
 	rowAdd.AddWidget(ui.hostAddr.QWidget)
+	rowAdd.AddWidget(presets.QWidget)
+	rowAdd.AddWidget(ui.btnRes.QWidget)
+	rowAdd.AddWidget(ui.chkBoth.QWidget)
 	rowAdd.AddWidget(ui.btnAdd.QWidget)
 	rowAdd.AddStretch()
 	rowAdd.AddWidget(ui.btnStart.QWidget)
 	rowAdd.AddWidget(ui.btnStop.QWidget)
+	ui.runLabel = qt.NewQLabel6("", nil, 0)
+	ui.runLabel.SetToolTip("How long the samples in the graph and stats have been collected for")
+	rowAdd.AddWidget(ui.runLabel.QWidget)
+	rowAdd.AddWidget(ui.btnOnce.QWidget)
+	rowAdd.AddWidget(ui.btnBurst.QWidget)
+	rowAdd.AddWidget(ui.btnMTU.QWidget)
+	rowAdd.AddWidget(ui.btnDiag.QWidget)
+	btnCheck := qt.NewQPushButton(nil)
+	btnCheck.SetText("Endpoint check…")
+	btnCheck.SetToolTip("Ping a root DNS server, public resolvers and CDNs for a few seconds to tell routing, DNS and single-site problems apart")
+	btnCheck.OnClicked(func() { ui.runConnCheck() })
+	rowAdd.AddWidget(btnCheck.QWidget)
+	btnWizard := qt.NewQPushButton(nil)
+	btnWizard.SetText("Check connection…")
+	btnWizard.SetToolTip("Guided check of your router, DNS and a website, with a plain-language verdict")
+	btnWizard.OnClicked(func() { ui.runWizard() })
+	rowAdd.AddWidget(btnWizard.QWidget)
 	rightCol.AddLayout(rowAdd.QLayout)
 
 	// Row: Interval slider
@@ -146,19 +327,196 @@ func NewUI(model *AppModel) *UI {
 	rowInt.AddWidget(lbl.QWidget)
 	rowInt.AddWidget(ui.intSlider.QWidget)
 	rowInt.AddWidget(ui.intLabel.QWidget)
+	ui.rateLabel = qt.NewQLabel6("", nil, 0)
+	ui.rateLabel.SetToolTip("Echo requests SpeedPing sends while pinging")
+	rowInt.AddWidget(ui.rateLabel.QWidget)
+	src := ""
+	if cfg != nil {
+		src = cfg.Ping.Source
+	}
+	rowInt.AddWidget(qt.NewQLabel6("Source:", nil, 0).QWidget)
+	ui.srcPick = newSourcePicker(src, "Local address (and so interface) to send pings from")
+	ui.srcPick.OnActivated(func(int) {
+		if c := ui.model.Config(); c != nil {
+			c.Ping.Source = ui.pingSource()
+			ui.model.SaveConfigAsync()
+		}
+		if ui.running {
+			ui.restartPinging()
+		}
+	})
+	rowInt.AddWidget(ui.srcPick.QWidget)
 	rightCol.AddLayout(rowInt.QLayout)
 
+	// Row: continuous sample log
+	ui.chkLog = qt.NewQCheckBox4("Log every sample to logs/"+sampleLogName, nil)
+	if cfg != nil && cfg.Ping.LogSamples {
+		ui.chkLog.SetChecked(true)
+		ui.startSampleLog()
+	}
+	gc := defaultConfig().Graph
+	if cfg != nil {
+		gc = cfg.Graph
+	}
+	ui.chkLoss = qt.NewQCheckBox4("Show loss %", nil)
+	ui.chkLoss.SetChecked(gc.ShowLoss)
+	ui.chkWorst = qt.NewQCheckBox4("Mark worst", nil)
+	ui.chkWorst.SetToolTip("Mark the slowest reply and the longest loss streak visible in the graph")
+	ui.chkWorst.SetChecked(gc.ShowWorst)
+	ui.chkStrip = qt.NewQCheckBox4("Loss strip", nil)
+	ui.chkStrip.SetToolTip("Show reply/loss as a red/green strip under the graph instead of ticks at the top")
+	ui.chkStrip.SetChecked(gc.LossStrip)
+	ui.chkHeat = qt.NewQCheckBox4("Heatmap", nil)
+	ui.chkHeat.SetToolTip("Show every host's recent history under the graph as a strip colored by latency")
+	ui.chkHeat.SetChecked(gc.Heatmap)
+	ui.chkThrough = qt.NewQCheckBox4("Connect through loss", nil)
+	ui.chkThrough.SetToolTip("Keep the line continuous: lost probes pull it down to the time axis (or the value set in Preferences) instead of leaving a gap")
+	ui.chkThrough.SetChecked(gc.ConnectLoss)
+	ui.chkAdaptive = qt.NewQCheckBox4("Adaptive timeout", nil)
+	ui.chkAdaptive.SetToolTip("Derive each host's loss timeout from its own RTT (6× median, min 100 ms) instead of the interval")
+	ui.chkAdaptive.SetChecked(cfg != nil && cfg.Ping.AdaptiveTimeout)
+	ui.flapSpin = qt.NewQSpinBox(nil)
+	ui.flapSpin.SetRange(0, 100)
+	ui.flapSpin.SetSpecialValueText("off")
+	ui.flapSpin.SetSuffix(" changes")
+	ui.flapSpin.SetToolTip("Mark a host as flapping when it switches between reply and loss this many times within the graph window")
+	ui.flapSpin.SetValue(gc.FlapThreshold)
+
+	rowOpts := qt.NewQHBoxLayout(nil)
+	rowOpts.AddWidget(ui.chkLog.QWidget)
+	rowOpts.AddWidget(ui.chkLoss.QWidget)
+	rowOpts.AddWidget(ui.chkWorst.QWidget)
+	rowOpts.AddWidget(ui.chkStrip.QWidget)
+	rowOpts.AddWidget(ui.chkHeat.QWidget)
+	rowOpts.AddWidget(ui.chkThrough.QWidget)
+	rowOpts.AddWidget(ui.chkAdaptive.QWidget)
+	rowOpts.AddWidget(qt.NewQLabel6("Flapping at:", nil, 0).QWidget)
+	rowOpts.AddWidget(ui.flapSpin.QWidget)
+	rowOpts.AddStretch()
+	rightCol.AddLayout(rowOpts.QLayout)
+
+	// Row: stop automatically (applies from the next Start)
+	spin := func(max int, suffix, tip string, val int) *qt.QSpinBox {
+		sb := qt.NewQSpinBox(nil)
+		sb.SetRange(0, max)
+		sb.SetSpecialValueText("off")
+		sb.SetSuffix(suffix)
+		sb.SetToolTip(tip)
+		sb.SetValue(val)
+		return sb
+	}
+	var pc PingConfig
+	if cfg != nil {
+		pc = cfg.Ping
+	}
+	ui.stopMin = spin(24*60, " min", "Stop pinging this long after Start", pc.StopMinutes)
+	ui.stopSamples = spin(1000000, " samples", "Stop once a host has this many samples", pc.StopSamples)
+	ui.stopLoss = spin(1000, " lost in a row", "Stop when any host loses this many pings in a row", pc.StopLossStreak)
+	rowStop := qt.NewQHBoxLayout(nil)
+	rowStop.AddWidget(qt.NewQLabel6("Stop after:", nil, 0).QWidget)
+	rowStop.AddWidget(ui.stopMin.QWidget)
+	rowStop.AddWidget(ui.stopSamples.QWidget)
+	rowStop.AddWidget(ui.stopLoss.QWidget)
+	ui.chkSummary = qt.NewQCheckBox4("Summary on stop", nil)
+	ui.chkSummary.SetToolTip("Show each host's results when a started session ends")
+	ui.chkSummary.SetChecked(pc.SummaryOnStop)
+	rowStop.AddWidget(ui.chkSummary.QWidget)
+	chkAuto := qt.NewQCheckBox4("Start on launch", nil)
+	chkAuto.SetToolTip("Begin pinging as soon as SpeedPing opens, e.g. on an unattended monitoring screen")
+	chkAuto.SetChecked(pc.AutoStart)
+	chkAuto.OnToggled(func(on bool) {
+		if c := ui.model.Config(); c != nil {
+			c.Ping.AutoStart = on
+			ui.model.SaveConfigAsync()
+		}
+	})
+	rowStop.AddWidget(chkAuto.QWidget)
+	rowStop.AddStretch()
+	rightCol.AddLayout(rowStop.QLayout)
+
+	// Row: named sessions (hosts + graph state, optionally history)
+	rowSess := qt.NewQHBoxLayout(nil)
+	btnSaveSess := qt.NewQPushButton(nil)
+	btnSaveSess.SetText("Save session…")
+	btnOpenSess := qt.NewQPushButton(nil)
+	btnOpenSess.SetText("Open session…")
+	rowSess.AddWidget(btnSaveSess.QWidget)
+	btnOverlay := qt.NewQPushButton(nil)
+	btnOverlay.SetText("Overlay session…")
+	btnOverlay.SetToolTip("Draw a saved session's history faded behind the live graph, e.g. to compare before/after")
+	ui.chkOverlay = qt.NewQCheckBox4("Show overlay", nil)
+	ui.chkOverlay.SetEnabled(false)
+	rowSess.AddWidget(btnOpenSess.QWidget)
+	btnImport := qt.NewQPushButton(nil)
+	btnImport.SetText("Import hosts…")
+	btnImport.SetToolTip("Add hosts from a text file: \"IP name\" lines as in /etc/hosts, or \"name,IP\"")
+	rowSess.AddWidget(btnImport.QWidget)
+	rowSess.AddWidget(btnOverlay.QWidget)
+	rowSess.AddWidget(ui.chkOverlay.QWidget)
+	btnMark := qt.NewQPushButton(nil)
+	btnMark.SetText("Add marker…")
+	btnMark.SetToolTip("Draw a labelled line on the graph at the current time (" + markerShortcut + ")")
+	btnClearMarks := qt.NewQPushButton(nil)
+	btnClearMarks.SetText("Clear markers")
+	rowSess.AddWidget(btnMark.QWidget)
+	rowSess.AddWidget(btnClearMarks.QWidget)
+	rowSess.AddStretch()
+	rightCol.AddLayout(rowSess.QLayout)
+	btnSaveSess.OnClicked(func() { ui.saveSession() })
+	btnOpenSess.OnClicked(func() { ui.openSession() })
+	btnImport.OnClicked(func() { ui.importHosts() })
+	btnOverlay.OnClicked(func() { ui.overlaySession() })
+	btnMark.OnClicked(func() { ui.addMarker() })
+	btnClearMarks.OnClicked(func() { ui.graph.SetMarkers(nil) })
+
 	// Add TopRow pieces
 	topRow.AddWidget(leftPane)
 	topRow.AddWidget2(rightPane, 1)
 
-	// Put TopRow into root (no stretch, it keeps its natural height)
-	pingRoot.AddLayout(topRow.QLayout)
+	// Put TopRow into root (no stretch, it keeps its natural height);
+	// it sits in its own widget so mini mode can hide it
+	ui.pingTop = qt.NewQWidget(nil)
+	topRow.SetContentsMargins(0, 0, 0, 0)
+	ui.pingTop.SetLayout(topRow.QLayout)
+	pingRoot.AddWidget(ui.pingTop)
+
+	// Overall health badge, refreshed once a second
+	ui.health = qt.NewQLabel6("", nil, 0)
+	ui.health.SetToolTip("0–100 from loss (−5 per %), average RTT above 50 ms and jitter above 10 ms across all hosts")
+	scaledFont(ui.health.QWidget, func(f *qt.QFont) {
+		f.SetBold(true)
+		f.SetPointSizeF(f.PointSizeF() * 1.4)
+	})
+	pingRoot.AddWidget(ui.health.QWidget)
+	ui.refreshHealth()
+	ui.healthTimer = qt.NewQTimer()
+	ui.alerter = NewAlerter()
+	ui.healthTimer.OnTimeout(func() {
+		ui.refreshHealth()
+		ui.refreshRunLabel()
+		if c := ui.model.Config(); c != nil {
+			ui.alerter.Evaluate(ui.model, c.Alert, time.Now())
+		}
+		ui.checkStop()
+	})
+	ui.healthTimer.Start(1000)
+
+	// Diagnosis banner (hidden until "Diagnose" is used)
+	ui.diagBanner = qt.NewQLabel6("", nil, 0)
+	ui.diagBanner.SetWordWrap(true)
+	ui.diagBanner.Hide()
+	pingRoot.AddWidget(ui.diagBanner.QWidget)
 
 	// Bottom: Graph expands
 	ui.graph = NewGraphWidget(model)
+	ui.graph.ApplyConfig(gc)
 	ui.graph.StartTicker()
-	pingRoot.AddWidget2(&ui.graph.QWidget, 1) // stretch=1 → grows to fill remaining space
+	ui.graph.SetMenuExtra(ui.graphMenu)
+	pingRoot.AddWidget2(ui.graphPane(), 1) // stretch=1 → grows to fill remaining space
+	ui.heat = NewHeatmapWidget(model)
+	ui.heat.SetVisible(gc.Heatmap)
+	ui.heat.StartTicker()
+	pingRoot.AddWidget(&ui.heat.QWidget)
 
 	// ---------- SPEED TEST TAB (placeholder) ----------
 	speedPage := qt.NewQWidget(nil)
@@ -172,6 +530,28 @@ func NewUI(model *AppModel) *UI {
 		center.SetAlignment(qt.AlignCenter)
 		speedRoot.AddStretch()
 		speedRoot.AddWidget(center.QWidget)
+		if cfg != nil && cfg.Speed.Download[iperf.Platform()].URL != "" {
+			btnGet := qt.NewQPushButton(nil)
+			btnGet.SetText("Download iperf3")
+			btnGet.SetToolTip(cfg.Speed.Download[iperf.Platform()].URL)
+			btnGet.OnClicked(func() {
+				btnGet.SetEnabled(false)
+				center.SetText("Downloading iperf3…")
+				sources := cfg.Speed.Download
+				go func() {
+					bin, err := iperf.EnsureBinary(appPath()+"/iperf", sources)
+					mainthread.Wait(func() {
+						if err != nil {
+							center.SetText("iperf3 download failed (see log).\nPlace it in ./iperf and restart.")
+							btnGet.SetEnabled(true)
+							return
+						}
+						center.SetText("iperf3 installed as " + bin + ".\nRestart SpeedPing to use it.")
+					})
+				}()
+			})
+			speedRoot.AddWidget3(btnGet.QWidget, 0, qt.AlignHCenter)
+		}
 		speedRoot.AddStretch()
 	} else {
 		// Controls row(s)
@@ -187,6 +567,18 @@ func NewUI(model *AppModel) *UI {
 		parr := qt.NewQLineEdit(nil)
 		parr.SetText("1") // -P streams
 		rev := qt.NewQCheckBox4("-R Reverse (download)", nil)
+		avgLine := qt.NewQCheckBox4("Show average line", nil)
+		smooth := qt.NewQCheckBox4("Smooth line", nil)
+		bigNum := qt.NewQCheckBox4("Big readout", nil)
+		bigNum.SetToolTip("Show the current rate and the peak in large numbers above the graph")
+		tcpInfo := qt.NewQCheckBox4("TCP RTT/cwnd", nil)
+		tcpInfo.SetToolTip("Read the sender's TCP round-trip time and congestion window (iperf3 3.17 or newer, --json-stream).\nNot reported for UDP, with -R, or by some platforms.")
+		unitCombo := qt.NewQComboBox(nil)
+		unitCombo.SetToolTip("Unit for the graph, tooltip and readouts")
+		for _, u := range rateUnits {
+			unitCombo.AddItem(u.name)
+		}
+		unitCombo.SetCurrentText("Mbps")
 		//bidi := qt.NewQCheckBox4("--bidir (simultaneous)", nil) // we disable it for now, because it needs more work to make it working
 
 		row1.AddWidget(qt.NewQLabel6("Server:", nil, 0).QWidget)
@@ -202,6 +594,19 @@ func NewUI(model *AppModel) *UI {
 		row2.AddWidget(qt.NewQLabel6("Parallel -P:", nil, 0).QWidget)
 		row2.AddWidget(parr.QWidget)
 		row2.AddWidget(rev.QWidget)
+		row2.AddWidget(avgLine.QWidget)
+		row2.AddWidget(smooth.QWidget)
+		row2.AddWidget(bigNum.QWidget)
+		row2.AddWidget(tcpInfo.QWidget)
+		bind := ""
+		if cfg != nil {
+			bind = cfg.Speed.Bind
+		}
+		bindPick := newSourcePicker(bind, "Local address (and so interface) to run the test from (iperf3 -B)")
+		row2.AddWidget(qt.NewQLabel6("Bind:", nil, 0).QWidget)
+		row2.AddWidget(bindPick.QWidget)
+		row2.AddWidget(qt.NewQLabel6("Unit:", nil, 0).QWidget)
+		row2.AddWidget(unitCombo.QWidget)
 		//row2.AddWidget(bidi.QWidget)
 		row2.AddStretch()
 
@@ -212,6 +617,11 @@ func NewUI(model *AppModel) *UI {
 			intv.SetText(fmt.Sprint(cfg.Speed.IntervalSec))
 			parr.SetText(fmt.Sprint(cfg.Speed.Parallel))
 			rev.SetChecked(cfg.Speed.Reverse)
+			avgLine.SetChecked(cfg.Speed.ShowAverage)
+			smooth.SetChecked(cfg.Speed.Smooth)
+			bigNum.SetChecked(cfg.Speed.BigReadout)
+			tcpInfo.SetChecked(cfg.Speed.TCPInfo)
+			unitCombo.SetCurrentText(rateUnitNamed(cfg.Speed.Unit).name)
 		}
 
 		// Buttons + status
@@ -221,28 +631,77 @@ func NewUI(model *AppModel) *UI {
 		btnStop := qt.NewQPushButton(nil)
 		btnStop.SetText("Stop")
 		btnStop.SetEnabled(false)
+		btnCopy, btnTerm := commandButtons()
 		status := qt.NewQLabel6("Idle.", nil, 0)
 		lastMbps := qt.NewQLabel6("0.0 Mbps", nil, 0)
+		avgMbps := qt.NewQLabel6("–", nil, 0)
+		scaledFont(avgMbps.QWidget, func(f *qt.QFont) { f.SetBold(true) })
+		tcpStats := qt.NewQLabel6("", nil, 0) // RTT/cwnd, hidden unless reported
+		tcpStats.SetVisible(false)
 
 		row3.AddWidget(btnStart.QWidget)
 		row3.AddWidget(btnStop.QWidget)
+		row3.AddWidget(btnCopy.QWidget)
+		row3.AddWidget(btnTerm.QWidget)
+		btnCSV := qt.NewQPushButton(nil)
+		btnCSV.SetText("Export CSV…")
+		btnCSV.SetToolTip("Save the throughput samples in the graph as CSV (time, Mbps)")
+		row3.AddWidget(btnCSV.QWidget)
 		row3.AddWidget(qt.NewQLabel6("Status:", nil, 0).QWidget)
 		row3.AddWidget(status.QWidget)
 		row3.AddStretch()
 		row3.AddWidget(qt.NewQLabel6("Current:", nil, 0).QWidget)
 		row3.AddWidget(lastMbps.QWidget)
+		row3.AddWidget(qt.NewQLabel6("Average:", nil, 0).QWidget)
+		row3.AddWidget(avgMbps.QWidget)
+		row3.AddWidget(tcpStats.QWidget)
 
 		// Graph at bottom
 		spGraph := NewSpeedGraphWidget()
+		spGraph.SetShowAverage(avgLine.IsChecked())
+		spGraph.SetSmooth(smooth.IsChecked())
+		spGraph.SetUnit(unitCombo.CurrentText())
+		readout := NewRateReadout()
+		readout.SetUnit(unitCombo.CurrentText())
+		readout.SetVisible(bigNum.IsChecked())
 		spGraph.StartTicker()
+		ui.animated = append(ui.animated, spGraph)
+		btnCSV.OnClicked(func() {
+			var b bytes.Buffer
+			if err := iperf.WriteCSV(&b, spGraph.Samples()); err != nil {
+				status.SetText("Nothing to export: " + err.Error() + ".")
+				return
+			}
+			path := ui.saveCSVAs("Export speed test", "speedtest", func(path string) error {
+				return os.WriteFile(path, b.Bytes(), 0o644)
+			})
+			if path != "" {
+				status.SetText("Exported to " + path)
+			}
+		})
 
 		speedRoot.AddLayout(row1.QLayout)
 		speedRoot.AddLayout(row2.QLayout)
 		speedRoot.AddLayout(row3.QLayout)
-		speedRoot.AddWidget2(&spGraph.QWidget, 1)
+		speedRoot.AddWidget2(&readout.QWidget, 1)
+		speedRoot.AddWidget2(&spGraph.QWidget, 3)
+
+		// readouts, kept in Mbps so a unit change can redo them
+		curRate, avgRate := 0.0, 0.0 // avgRate 0 = no average yet
+		showRates := func() {
+			u := rateUnitNamed(unitCombo.CurrentText())
+			lastMbps.SetText(u.format(curRate))
+			if avgRate > 0 {
+				avgMbps.SetText(u.format(avgRate))
+			} else {
+				avgMbps.SetText("–")
+			}
+		}
+		showRates()
 
 		// Runtime wiring
 		var cancel context.CancelFunc
+		var onChangeSpeed func()
 		running := false
 		setRunning := func(on bool) {
 			running = on
@@ -250,15 +709,8 @@ func NewUI(model *AppModel) *UI {
 			btnStop.SetEnabled(on)
 		}
 
-		btnStart.OnClicked(func() {
-			if running {
-				return
-			}
-			if strings.TrimSpace(host.Text()) == "" {
-				status.SetText("Please enter server/IP.")
-				return
-			}
-			cfg := iperf.Config{
+		runConfig := func() iperf.Config {
+			return iperf.Config{
 				BinDir:      appPath() + "/iperf",
 				Host:        strings.TrimSpace(host.Text()),
 				Port:        atoiDefault(port.Text(), 5201),
@@ -267,7 +719,29 @@ func NewUI(model *AppModel) *UI {
 				IntervalSec: atoiDefault(intv.Text(), 1),
 				Reverse:     rev.IsChecked(),
 				//Bidirectional: bidi.IsChecked(),
-				Format: "m", // Mbps as in our iperf package
+				Format:   "m", // Mbps as in our iperf package
+				JSON:     tcpInfo.IsChecked(),
+				BindAddr: bindPick.Addr(),
+			}
+		}
+
+		btnStart.OnClicked(func() {
+			if running {
+				return
+			}
+			if strings.TrimSpace(host.Text()) == "" {
+				status.SetText("Please enter server/IP.")
+				return
+			}
+			if bindPick.Check() != nil {
+				status.SetText("Bind address " + bindPick.Addr() + " is not on any active interface.")
+				return
+			}
+			cfg := runConfig()
+			note := cfg.ClampInterval()
+			if note != "" {
+				intv.SetText(fmt.Sprint(cfg.IntervalSec))
+				onChangeSpeed()
 			}
 			ctx, cn := context.WithCancel(context.Background())
 			cancel = cn
@@ -277,9 +751,19 @@ func NewUI(model *AppModel) *UI {
 				status.SetText(fmt.Sprintf("Start error: %v", err))
 				return
 			}
+			exited := ui.runs.Start(cn)
 			setRunning(true)
-			status.SetText("Running…")
-			lastMbps.SetText("0.0 Mbps")
+			if note != "" {
+				status.SetText("Running… (" + note + ")")
+			} else {
+				status.SetText("Running…")
+			}
+			status.SetToolTip("") // stderr of a previous failed run
+			curRate, avgRate = 0, 0
+			showRates()
+			tcpStats.SetVisible(false)
+			spGraph.SetAverage(0)
+			readout.Reset()
 
 			// Consume intervals and update graph
 			go func() {
@@ -287,17 +771,45 @@ func NewUI(model *AppModel) *UI {
 					// iv.Bitrate is like "607 Mbits/sec"
 					mbps := parseMbps(iv.Bitrate)
 					spGraph.AppendMbps(mbps)
-					lastMbps.SetText(fmt.Sprintf("%.1f Mbps", mbps))
+					mainthread.Wait(func() {
+						curRate = mbps
+						readout.Set(mbps)
+						showRates()
+						if iv.RTTms > 0 || iv.CWND > 0 {
+							tcpStats.SetText(tcpStatsText(iv, false))
+							tcpStats.SetVisible(true)
+						}
+					})
 				}
 			}()
 			go func() {
 				r := <-done
-				if r.ExitErr != nil {
-					status.SetText(fmt.Sprintf("Finished with error: %v", r.ExitErr))
-				} else {
-					status.SetText("Finished.")
+				exited() // iperf3 is gone; the UI update below may wait for the close handler
+				// receiver side is the authoritative end-of-test average
+				sum := r.Receiver
+				if sum == nil {
+					sum = r.Sender
 				}
-				setRunning(false)
+				mainthread.Wait(func() {
+					if err := r.Err(); err != nil {
+						status.SetText(fmt.Sprintf("Finished with error: %v", err))
+						if len(r.Stderr) > 0 {
+							status.SetToolTip(strings.Join(r.Stderr, "\n"))
+						}
+					} else {
+						status.SetText("Finished.")
+					}
+					if sum != nil {
+						avgRate = parseMbps(sum.Bitrate)
+						showRates()
+						spGraph.SetAverage(avgRate)
+					}
+					if s := r.Sender; s != nil && (s.RTTms > 0 || s.CWND > 0) {
+						tcpStats.SetText(tcpStatsText(*s, true))
+						tcpStats.SetVisible(true)
+					}
+					setRunning(false)
+				})
 			}()
 		})
 
@@ -307,12 +819,14 @@ func NewUI(model *AppModel) *UI {
 				cancel = nil
 			}
 		})
-		onChangeSpeed := func() {
-			c := ui.model.Config()
-			if c == nil {
-				c = defaultConfig()
-				ui.model.LoadFromConfig(c)
+		wireCommandButtons(btnCopy, btnTerm, status, func() (string, []string, error) {
+			if strings.TrimSpace(host.Text()) == "" {
+				return "", nil, errors.New("please enter server/IP")
 			}
+			return iperf.Command(runConfig())
+		})
+		onChangeSpeed = func() {
+			c := ui.model.EnsureConfig()
 
 			// Speed
 			c.Speed.Server = strings.TrimSpace(host.Text())
@@ -321,6 +835,12 @@ func NewUI(model *AppModel) *UI {
 			c.Speed.IntervalSec = atoiDefault(intv.Text(), 1)
 			c.Speed.Parallel = atoiDefault(parr.Text(), 1)
 			c.Speed.Reverse = rev.IsChecked()
+			c.Speed.ShowAverage = avgLine.IsChecked()
+			c.Speed.Smooth = smooth.IsChecked()
+			c.Speed.BigReadout = bigNum.IsChecked()
+			c.Speed.TCPInfo = tcpInfo.IsChecked()
+			c.Speed.Bind = bindPick.Addr()
+			c.Speed.Unit = unitCombo.CurrentText()
 
 			ui.model.SaveConfigAsync()
 		}
@@ -331,127 +851,945 @@ func NewUI(model *AppModel) *UI {
 		intv.OnEditingFinished(onChangeSpeed)
 		parr.OnEditingFinished(onChangeSpeed)
 		rev.OnToggled(func(checked bool) { onChangeSpeed() })
+		tcpInfo.OnToggled(func(checked bool) { onChangeSpeed() })
+		bindPick.OnActivated(func(int) { onChangeSpeed() })
+		avgLine.OnToggled(func(checked bool) {
+			spGraph.SetShowAverage(checked)
+			onChangeSpeed()
+		})
+		bigNum.OnToggled(func(checked bool) {
+			readout.SetVisible(checked)
+			onChangeSpeed()
+		})
+		smooth.OnToggled(func(checked bool) {
+			spGraph.SetSmooth(checked)
+			onChangeSpeed()
+		})
+		unitCombo.OnCurrentTextChanged(func(name string) {
+			spGraph.SetUnit(name)
+			readout.SetUnit(name)
+			showRates()
+			onChangeSpeed()
+		})
 	}
 
 	// Add tabs
 	tabs.AddTab(pingPage, "Ping")
+	ui.matrix = NewPingMatrix(model, func(h *Host) {
+		for i, x := range ui.model.Hosts() {
+			if x == h {
+				ui.hostList.SetCurrentRow(i)
+			}
+		}
+		ui.tabs.SetCurrentIndex(0)
+		ui.graph.FocusHost(h)
+	})
+	ui.matrix.StartTicker()
+	tabs.AddTab(ui.matrix.QWidget, "Matrix")
 	tabs.AddTab(speedPage, "Speed test")
-	tabs.AddTab(buildTracerouteTab(ui.model), "Traceroute")
+	tracePage, tmap := buildTracerouteTab(ui.model, &ui.runs)
+	ui.animated = append(ui.animated, ui.graph, tmap)
+	tabs.AddTab(tracePage, "Traceroute")
 	aboutPage := NewAboutPage(ui.model)
 	tabs.AddTab(aboutPage, "About")
 
 	ui.main.SetCentralWidget(tabs.QWidget)
 
+	// ---- status bar: frame rate / CPU saver ----
+	ui.fpsCombo = qt.NewQComboBox(nil)
+	ui.fpsCombo.SetToolTip("Graph repaint rate; lower uses less CPU")
+	fps := defaultConfig().View.FrameRate
+	if cfg != nil && cfg.View.FrameRate > 0 {
+		fps = cfg.View.FrameRate
+	}
+	best := 0 // closest offered rate to the configured one
+	for i, r := range frameRates {
+		ui.fpsCombo.AddItem(fmt.Sprintf("%d fps", r))
+		if absInt(r-fps) < absInt(frameRates[best]-fps) {
+			best = i
+		}
+	}
+	ui.fpsCombo.SetCurrentIndex(best)
+	ui.chkSaver = qt.NewQCheckBox4("CPU saver", nil)
+	ui.chkSaver.SetToolTip(fmt.Sprintf("Repaint at %d fps while the window is in the background", saverFrameRate))
+	ui.chkSaver.SetChecked(cfg != nil && cfg.View.CPUSaver)
+	ui.chkComma = qt.NewQCheckBox4("Decimal comma", nil)
+	ui.chkComma.SetToolTip("Show numbers as 12,5 instead of 12.5")
+	ui.chkComma.SetChecked(cfg != nil && cfg.View.DecimalComma)
+	decimalComma.Store(ui.chkComma.IsChecked())
+	if cfg != nil {
+		valueDecimals.Store(int32(min(cfg.View.Decimals, 2)))
+		tipMillis.Store(cfg.View.TipMillis)
+	}
+	ui.main.StatusBar().AddWidget(ui.fpsCombo.QWidget)
+	ui.main.StatusBar().AddWidget(ui.chkSaver.QWidget)
+	ui.main.StatusBar().AddWidget(ui.chkComma.QWidget)
+	ui.palCombo = qt.NewQComboBox(nil)
+	ui.palCombo.SetToolTip("Graph colors; the colorblind-safe set stays distinguishable with red-green color blindness")
+	for i, p := range paletteNames {
+		ui.palCombo.AddItem(p[1])
+		if cfg != nil && cfg.View.Palette == p[0] {
+			ui.palCombo.SetCurrentIndex(i)
+		}
+	}
+	ui.chkHiCon = qt.NewQCheckBox4("High contrast", nil)
+	ui.chkHiCon.SetToolTip("Thicker graph lines and stronger grid lines")
+	ui.chkHiCon.SetChecked(highContrast)
+	ui.main.StatusBar().AddWidget(ui.palCombo.QWidget)
+	ui.main.StatusBar().AddWidget(ui.chkHiCon.QWidget)
+	ui.palCombo.OnCurrentIndexChanged(func(int) { ui.applyPalette() })
+	ui.chkHiCon.OnToggled(func(bool) { ui.applyPalette() })
+	ui.fpsCombo.OnCurrentIndexChanged(func(int) { ui.onDisplayChanged() })
+	ui.chkSaver.OnToggled(func(bool) { ui.onDisplayChanged() })
+	ui.chkComma.OnToggled(func(on bool) {
+		decimalComma.Store(on)
+		ui.updateRate()
+		ui.onDisplayChanged()
+	})
+	ui.main.OnChangeEvent(func(super func(*qt.QEvent), e *qt.QEvent) {
+		super(e)
+		if e.Type() == qt.QEvent__ActivationChange {
+			ui.applyFrameRate()
+		}
+	})
+	ui.applyFrameRate()
+
+	// ---- status bar: public IP (looked up once per session, refresh on demand) ----
+	ui.pubIPLabel = qt.NewQLabel6("Public IP: …", nil, 0)
+	ui.pubIPLabel.SetTextInteractionFlags(qt.TextSelectableByMouse)
+	btnIP := qt.NewQPushButton(nil)
+	btnIP.SetText("Refresh")
+	btnIP.SetFlat(true)
+	ui.main.StatusBar().AddPermanentWidget(ui.pubIPLabel.QWidget)
+	ui.main.StatusBar().AddPermanentWidget(btnIP.QWidget)
+	btnIP.OnClicked(func() { ui.refreshPublicIP() })
+	ui.refreshPublicIP()
+
+	// ---- mini mode: graph only, toggled here, by shortcut or from the graph menu ----
+	ui.btnMini = qt.NewQPushButton(nil)
+	ui.btnMini.SetText("Mini mode")
+	ui.btnMini.SetCheckable(true)
+	ui.btnMini.SetFlat(true)
+	ui.btnMini.SetToolTip("Shrink the window to just the ping graph (" + miniShortcut + ")")
+	btnCopyGraph := qt.NewQPushButton(nil)
+	btnCopyGraph.SetText("Copy graph")
+	btnCopyGraph.SetToolTip("Copy the ping graph as an image, e.g. to paste into a chat (" + copyGraphShortcut + " on the graph)")
+	btnCopyGraph.OnClicked(func() {
+		ui.graph.CopyImage()
+		ui.main.StatusBar().ShowMessage2("Graph copied to the clipboard", 3000)
+	})
+	btnPrefs := qt.NewQPushButton(nil)
+	btnPrefs.SetText("Preferences…")
+	btnPrefs.SetFlat(true)
+	btnPrefs.SetToolTip("Thresholds, alerts, scheduled export and network services (" + prefsShortcut + ")")
+	btnPrefs.OnClicked(func() { ui.showPreferences() })
+	ui.main.StatusBar().AddPermanentWidget(btnPrefs.QWidget)
+	ui.main.StatusBar().AddPermanentWidget(btnCopyGraph.QWidget)
+	ui.main.StatusBar().AddPermanentWidget(ui.btnMini.QWidget)
+	ui.btnMini.OnClicked(func() { ui.setMiniMode(!ui.mini) })
+	ui.btnDetach = qt.NewQPushButton(nil)
+	ui.btnDetach.SetText("Detach graph")
+	ui.btnDetach.SetCheckable(true)
+	ui.btnDetach.SetFlat(true)
+	ui.btnDetach.SetToolTip("Move the ping graph into a window of its own, e.g. for a second monitor")
+	ui.btnDetach.OnClicked(func() { ui.setDetached(!ui.detached) })
+	ui.main.StatusBar().AddPermanentWidget(ui.btnDetach.QWidget)
+	if cfg != nil {
+		ui.miniGeom = cfg.MiniWindow
+		ui.graphGeom = cfg.GraphWindow
+	}
+	miniKey := qt.NewQShortcut2(qt.NewQKeySequence2(miniShortcut), ui.main.QWidget)
+	miniKey.OnActivated(func() { ui.setMiniMode(!ui.mini) })
+	markKey := qt.NewQShortcut2(qt.NewQKeySequence2(markerShortcut), ui.main.QWidget)
+	markKey.OnActivated(func() { ui.addMarker() })
+	prefsKey := qt.NewQShortcut2(qt.NewQKeySequence2(prefsShortcut), ui.main.QWidget)
+	prefsKey.OnActivated(func() { ui.showPreferences() })
+
 	// --- logic wiring
 
 	// on change ping settings, save them
 	onChange := func() {
-		c := ui.model.Config()
-		if c == nil {
-			c = defaultConfig()
-			ui.model.LoadFromConfig(c)
-		}
+		ui.model.EnsureConfig()
 		// Ping interval slider
 		model.SetPingIntervalMs(ui.intSlider.Value())
 		ui.model.SaveConfigAsync()
 	}
 
 	ui.btnAdd.OnClicked(func() {
-		name := ui.hostName.Text()
-		addr := ui.hostAddr.Text()
-		if addr == "" {
-			return
+		name := strings.TrimSpace(ui.hostName.Text())
+		addrs := parseHostList(ui.hostAddr.Text())
+		added := 0
+		var bad, problems []string
+		var dual [][2]string // name, hostname pairs to fork into v4/v6
+		ui.model.BeginBatch()
+		defer ui.model.EndBatch()
+		for _, addr := range addrs {
+			if err := hostlist.Validate(addr); err != nil {
+				bad = append(bad, addr)
+				problems = append(problems, err.Error())
+				continue
+			}
+			if ui.model.FindHost(addr) != nil {
+				continue // already monitored
+			}
+			n := name
+			switch {
+			case n == "":
+				n = addr
+			case len(addrs) > 1:
+				n = fmt.Sprintf("%s %d", name, added+1)
+			}
+			if _, cmd := monitor.CommandLine(addr); ui.chkBoth.IsChecked() && !cmd && net.ParseIP(addr) == nil {
+				dual = append(dual, [2]string{n, addr}) // resolved below
+				added++
+				continue
+			}
+			ui.addHost(n, addr)
+			added++
+		}
+		if len(dual) > 0 {
+			ui.addDualStack(dual)
 		}
-		if name == "" {
-			name = addr
+		if len(bad) > 0 {
+			// keep only the rejected entries so they can be fixed in place
+			ui.hostAddr.SetText(strings.Join(bad, ", "))
+			ui.markHostAddrInvalid(strings.Join(problems, "\n"))
+		}
+		if added == 0 {
+			return
 		}
-		model.AddHost(name, addr, DefaultRingCap)
-		ui.hostList.AddItem(fmt.Sprintf("%s (%s)", name, addr))
 		ui.hostName.SetText("")
-		ui.hostAddr.SetText("")
-		ui.updateButtons()
-		// persist
-		c := ui.model.Config()
-		// rebuild hosts slice from model to keep it single source of truth
-		c.Ping.Hosts = nil
-		for _, h := range ui.model.Hosts() {
-			c.Ping.Hosts = append(c.Ping.Hosts, HostConfig{Name: h.Name, Addr: h.Addr, Enabled: true})
+		if len(bad) == 0 {
+			ui.hostAddr.SetText("")
 		}
-		ui.model.SaveConfigAsync()
+		ui.hostAddr.SetFocus() // ready for the next one
+		ui.updateButtons()
 
 		if ui.running {
 			ui.restartPinging()
 		}
 	})
 
+	ui.hostAddr.OnTextEdited(func(string) { ui.markHostAddrInvalid("") })
+	// Enter in either field adds (Click is a no-op while the button is disabled)
+	ui.hostAddr.OnReturnPressed(func() { ui.btnAdd.Click() })
+	ui.hostName.OnReturnPressed(func() { ui.btnAdd.Click() })
+
+	ui.btnRes.OnClicked(func() { ui.resolvePreview() })
+
 	ui.btnRem.OnClicked(func() {
-		row := ui.hostList.CurrentRow()
-		if row < 0 {
-			return
+		var rows []int
+		for _, it := range ui.hostList.SelectedItems() {
+			rows = append(rows, ui.hostList.Row(it))
 		}
-		if ok := model.RemoveHostAt(row); ok {
-			_ = ui.hostList.TakeItem(row) // remove from list
-			ui.updateButtons()
-			c := ui.model.Config()
-			c.Ping.Hosts = nil
-			for _, h := range ui.model.Hosts() {
-				c.Ping.Hosts = append(c.Ping.Hosts, HostConfig{Name: h.Name, Addr: h.Addr, Enabled: true})
-			}
+		ui.removeHosts(rows)
+	})
+
+	ui.btnStart.OnClicked(func() { ui.startSession() })
+	ui.btnStop.OnClicked(func() {
+		ui.disarmStop()
+		ui.StopPinging()
+		ui.showSessionSummary("")
+	})
+	ui.btnDiag.OnClicked(func() { ui.runDiagnose() })
+	ui.btnOnce.OnClicked(func() { ui.pingOnce() })
+	ui.btnBurst.OnClicked(func() { ui.burstTest() })
+	ui.btnMTU.OnClicked(func() { ui.mtuProbe() })
+	ui.chkOverlay.OnToggled(func(on bool) { ui.graph.SetOverlayVisible(on) })
+
+	ui.chkLoss.OnToggled(func(on bool) {
+		ui.graph.SetShowLoss(on)
+		if c := ui.model.Config(); c != nil {
+			c.Graph.ShowLoss = on
 			ui.model.SaveConfigAsync()
+		}
+	})
 
-			if ui.running {
-				ui.restartPinging()
-			}
+	ui.chkWorst.OnToggled(func(on bool) {
+		ui.graph.SetShowWorst(on)
+		if c := ui.model.Config(); c != nil {
+			c.Graph.ShowWorst = on
+			ui.model.SaveConfigAsync()
+		}
+	})
+
+	ui.chkHeat.OnToggled(func(on bool) {
+		ui.heat.SetVisible(on)
+		if c := ui.model.Config(); c != nil {
+			c.Graph.Heatmap = on
+			ui.model.SaveConfigAsync()
+		}
+	})
+
+	ui.chkThrough.OnToggled(func(on bool) {
+		lossMs := 0.0
+		if c := ui.model.Config(); c != nil {
+			c.Graph.ConnectLoss = on
+			lossMs = c.Graph.LossMs
+			ui.model.SaveConfigAsync()
+		}
+		ui.graph.SetConnectLoss(on, lossMs)
+	})
+
+	ui.chkStrip.OnToggled(func(on bool) {
+		if c := ui.model.Config(); c != nil {
+			c.Graph.LossStrip = on
+			ui.model.SaveConfigAsync()
+		}
+		ui.applyLossStrip()
+	})
+
+	saveStop := func(int) {
+		if c := ui.model.Config(); c != nil {
+			c.Ping.StopMinutes = ui.stopMin.Value()
+			c.Ping.StopSamples = ui.stopSamples.Value()
+			c.Ping.StopLossStreak = ui.stopLoss.Value()
+			ui.model.SaveConfigAsync()
+		}
+	}
+	ui.stopMin.OnValueChanged(saveStop)
+	ui.stopSamples.OnValueChanged(saveStop)
+	ui.stopLoss.OnValueChanged(saveStop)
+	ui.chkSummary.OnToggled(func(on bool) {
+		if c := ui.model.Config(); c != nil {
+			c.Ping.SummaryOnStop = on
+			ui.model.SaveConfigAsync()
+		}
+	})
+
+	ui.flapSpin.OnValueChanged(func(n int) {
+		ui.graph.SetFlapThreshold(n)
+		if c := ui.model.Config(); c != nil {
+			c.Graph.FlapThreshold = n
+			ui.model.SaveConfigAsync()
+		}
+	})
+
+	ui.chkAdaptive.OnToggled(func(on bool) {
+		if c := ui.model.Config(); c != nil {
+			c.Ping.AdaptiveTimeout = on
+			ui.model.SaveConfigAsync()
+		}
+		if ui.running {
+			ui.restartPinging()
+		}
+	})
+
+	ui.chkLog.OnToggled(func(on bool) {
+		if on {
+			ui.startSampleLog()
+		} else {
+			ui.stopSampleLog()
 		}
+		if c := ui.model.Config(); c != nil {
+			c.Ping.LogSamples = on
+			ui.model.SaveConfigAsync()
+		}
+	})
+
+	ui.hostList.OnItemDoubleClicked(func(it *qt.QListWidgetItem) {
+		ui.editHost(ui.hostList.Row(it))
 	})
 
-	ui.btnStart.OnClicked(func() { ui.StartPinging() })
-	ui.btnStop.OnClicked(func() { ui.StopPinging() })
+	ui.hostList.SetContextMenuPolicy(qt.CustomContextMenu)
+	ui.hostList.OnCustomContextMenuRequested(func(pos *qt.QPoint) { ui.hostListMenu(pos) })
 
 	// Hook selection change once (outside updateButtons) so Remove toggles:
-	ui.hostList.OnCurrentRowChanged(func(row int) {
-		// Remove is allowed only when something is selected
-		ui.btnRem.SetEnabled(row >= 0)
+	ui.hostList.OnItemSelectionChanged(func() {
+		// Remove and export are allowed only when something is selected
+		sel := len(ui.hostList.SelectedItems()) > 0
+		ui.btnRem.SetEnabled(sel)
+		ui.btnExpSel.SetEnabled(sel)
 	})
 
 	ui.intSlider.OnValueChanged(func(v int) {
 		ui.intLabel.SetText(fmt.Sprintf("%d ms", v))
 		onChange()
+		ui.updateRate()
 		if ui.running {
 			ui.restartPinging()
 		}
 	})
 
+	if cfg != nil {
+		ui.startAutoExport(cfg.Export)
+	}
+
+	if cfg != nil {
+		_ = ui.startMetrics(cfg.Metrics)
+		_ = ui.startAPI(cfg.API)
+	}
+
 	ui.updateButtons()
+	ui.hostAddr.SetFocus()
 	return ui
 }
 
 func (ui *UI) Show() { ui.main.Show() }
 
-func (ui *UI) StartPinging() {
+// startMetrics (re)starts the Prometheus endpoint from c, closing the one
+// running before; a no-op beyond that unless enabled.
+func (ui *UI) startMetrics(c MetricsConfig) error {
+	ui.metrics.Close()
+	ui.metrics = nil
+	if !c.Enabled {
+		return nil
+	}
+	ms, err := StartMetricsServer(ui.model, c.Listen)
+	if err != nil {
+		log.Printf("Unable to start metrics server on %s: %s\n", c.Listen, err)
+	}
+	ui.metrics = ms
+	return err
+}
+
+// startAPI is startMetrics for the JSON API.
+func (ui *UI) startAPI(c APIConfig) error {
+	ui.api.Close()
+	ui.api = nil
+	if !c.Enabled {
+		return nil
+	}
+	a, err := StartAPIServer(ui.model, c.Listen)
+	if err != nil {
+		log.Printf("Unable to start JSON API on %s: %s\n", c.Listen, err)
+	}
+	ui.api = a
+	return err
+}
+
+// frameRate is the rate picked in the status bar.
+func (ui *UI) frameRate() int {
+	if i := ui.fpsCombo.CurrentIndex(); i >= 0 && i < len(frameRates) {
+		return frameRates[i]
+	}
+	return defaultConfig().View.FrameRate
+}
+
+// applyFrameRate sets every animated widget to the chosen rate, or the
+// saver rate while the window is in the background.
+func (ui *UI) applyFrameRate() {
+	fps := ui.frameRate()
+	if ui.chkSaver.IsChecked() && !ui.windowActive() {
+		fps = saverFrameRate
+	}
+	for _, w := range ui.animated {
+		w.SetFrameRate(fps)
+	}
+}
+
+func (ui *UI) onDisplayChanged() {
+	ui.applyFrameRate()
+	if c := ui.model.Config(); c != nil {
+		c.View.FrameRate = ui.frameRate()
+		c.View.CPUSaver = ui.chkSaver.IsChecked()
+		c.View.DecimalComma = ui.chkComma.IsChecked()
+		c.View.Palette = paletteNames[max(ui.palCombo.CurrentIndex(), 0)][0]
+		c.View.HighContrast = ui.chkHiCon.IsChecked()
+		ui.model.SaveConfigAsync()
+	}
+}
+
+// applyPalette switches graph colors and contrast to the status bar choice.
+// The ping graph caches its pens; the other widgets pick it up on repaint.
+func (ui *UI) applyPalette() {
+	setPalette(paletteNames[max(ui.palCombo.CurrentIndex(), 0)][0])
+	highContrast = ui.chkHiCon.IsChecked()
+	ui.graph.ApplyPalette()
+	ui.matrix.ApplyPalette()
+	ui.onDisplayChanged()
+}
+
+// applyLossStrip pushes the loss strip toggle and configured height to the graph.
+func (ui *UI) applyLossStrip() {
+	if !ui.chkStrip.IsChecked() {
+		ui.graph.SetLossStrip(0)
+		return
+	}
+	px := defaultConfig().Graph.LossStripPx
+	if c := ui.model.Config(); c != nil && c.Graph.LossStripPx > 0 {
+		px = c.Graph.LossStripPx
+	}
+	ui.graph.SetLossStrip(px)
+}
+
+// refreshRunLabel shows how long pinging has been running, e.g.
+// "Running for 00:12:34 since 14:02"; empty while stopped.
+func (ui *UI) refreshRunLabel() {
+	if ui.runningSince.IsZero() {
+		ui.runLabel.SetText("")
+		return
+	}
+	d := time.Since(ui.runningSince) / time.Second
+	ui.runLabel.SetText(fmt.Sprintf("Running for %02d:%02d:%02d since %s",
+		d/3600, d/60%60, d%60, ui.runningSince.Format("15:04")))
+}
+
+// refreshHealth recomputes the health badge from the current reports.
+func (ui *UI) refreshHealth() {
+	score := monitor.HealthScore(ui.model.Report())
+	if score < 0 {
+		ui.health.SetText("Network health: –")
+		ui.health.SetStyleSheet("")
+		return
+	}
+	col := "#2a2" // green
+	switch {
+	case score < 50:
+		col = "#d33"
+	case score < 80:
+		col = "#e90"
+	}
+	ui.health.SetText(fmt.Sprintf("Network health: %d / 100", score))
+	ui.health.SetStyleSheet("QLabel { color: " + col + "; }")
+}
+
+// resolvePreview looks up the first address in the add field and shows the
+// result in the status bar. Clicking again while it runs cancels it.
+func (ui *UI) resolvePreview() {
+	if ui.resStop != nil {
+		ui.resStop()
+		return
+	}
+	addrs := parseHostList(ui.hostAddr.Text())
+	if len(addrs) == 0 {
+		return
+	}
+	host := addrs[0]
+	ctx, cancel := context.WithTimeout(context.Background(), dns.DefaultTimeout)
+	ui.resStop = cancel
+	ui.btnRes.SetText("Cancel")
+	ui.main.StatusBar().ShowMessage("Resolving " + host + "…")
+	go func() {
+		ips, err := dns.Lookup(ctx, host)
+		cancel()
+		mainthread.Wait(func() {
+			ui.resStop = nil
+			ui.btnRes.SetText("Resolve")
+			switch {
+			case errors.Is(err, context.Canceled):
+				ui.main.StatusBar().ClearMessage()
+			case err != nil:
+				ui.main.StatusBar().ShowMessage2(host+": "+err.Error(), 10000)
+			default:
+				ui.main.StatusBar().ShowMessage2(host+" → "+strings.Join(ips, ", "), 15000)
+			}
+		})
+	}()
+}
+
+// pingOnce sends one probe to every host and reports the results in the list
+// tooltips and the status bar. The continuous session, if any, keeps running.
+func (ui *UI) pingOnce() {
+	hosts := ui.model.Hosts()
+	if len(hosts) == 0 {
+		return
+	}
+	addrs := make([]string, len(hosts))
+	sizes := make([]int, len(hosts))
+	for i, h := range hosts {
+		addrs[i], sizes[i] = h.Addr, h.PacketSize
+	}
+	ui.btnOnce.SetEnabled(false)
+	ui.main.StatusBar().ShowMessage(fmt.Sprintf("Pinging %d hosts once…", len(hosts)))
+	pb := ProbingBackend{MaxRTT: time.Second, Source: ui.pingSource()}
+	go func() {
+		res := pb.ProbeAll(context.Background(), addrs, sizes)
+		mainthread.Wait(func() {
+			ui.btnOnce.SetEnabled(true)
+			up := 0
+			parts := make([]string, 0, len(res))
+			for i, r := range res {
+				var s string
+				switch {
+				case r.Err != nil:
+					s = "error: " + r.Err.Error()
+				case r.Up:
+					up++
+					s = formatFloat(float64(r.RTT.Microseconds())/1000, 1) + " ms"
+				default:
+					s = "down"
+				}
+				parts = append(parts, hosts[i].Name+" "+s)
+				// the host may have been removed meanwhile
+				if row := hosts[i].ColorI; ui.model.HostAt(row) == hosts[i] {
+					ui.syncHostItem(row, hosts[i])
+					if it := ui.hostList.Item(row); it != nil {
+						it.SetToolTip(it.ToolTip() + "\nPing once: " + s)
+					}
+				}
+			}
+			msg := fmt.Sprintf("Ping once: %d/%d up — %s", up, len(res), strings.Join(parts, ", "))
+			ui.main.StatusBar().ShowMessage2(msg, 30000)
+		})
+	}()
+}
+
+// addDualStack resolves each (name, hostname) pair in the background and adds
+// one host per address family found: "name (v4)" and/or "name (v6)".
+func (ui *UI) addDualStack(reqs [][2]string) {
+	go func() {
+		type fam struct{ name, addr string }
+		var found []fam
+		var failed []string
+		for _, r := range reqs {
+			v4, v6, err := dns.Families(context.Background(), r[1])
+			if err != nil {
+				log.Printf("Resolving %s failed: %s\n", r[1], err)
+				failed = append(failed, r[1])
+				continue
+			}
+			if v4 != "" {
+				found = append(found, fam{r[0] + " (v4)", v4})
+			}
+			if v6 != "" {
+				found = append(found, fam{r[0] + " (v6)", v6})
+			}
+		}
+		mainthread.Wait(func() {
+			ui.model.BeginBatch()
+			defer ui.model.EndBatch()
+			for _, f := range found {
+				if ui.model.FindHost(f.addr) == nil {
+					ui.addHost(f.name, f.addr)
+				}
+			}
+			if len(failed) > 0 {
+				ui.hostAddr.SetText(strings.Join(failed, ", "))
+				ui.markHostAddrInvalid("Could not resolve: " + strings.Join(failed, ", "))
+			}
+			ui.updateButtons()
+			if ui.running {
+				ui.restartPinging()
+			}
+		})
+	}()
+}
+
+// markHostAddrInvalid outlines the address field in red and pops up msg;
+// an empty msg clears the marking.
+func (ui *UI) markHostAddrInvalid(msg string) {
+	if msg == "" {
+		ui.hostAddr.SetStyleSheet("")
+		ui.hostAddr.SetToolTip("")
+		return
+	}
+	ui.hostAddr.SetStyleSheet("QLineEdit { border: 1px solid #d33; }")
+	ui.hostAddr.SetToolTip(msg)
+	pos := ui.hostAddr.MapToGlobal(qt.NewQPoint2(0, ui.hostAddr.Height()))
+	qt.QToolTip_ShowText(pos, msg)
+}
+
+// addHost appends a host to the model and the list widget and persists it.
+// Callers restart pinging themselves if needed.
+func (ui *UI) addHost(name, addr string) *Host {
+	h := ui.model.AddHost(name, addr, ui.model.RingCap())
+	ui.appendHostItem(h)
+	ui.persistHosts()
+	return h
+}
+
+// removeHosts drops the hosts at rows from the model and the list, then
+// saves and restarts pinging once for the whole batch.
+func (ui *UI) removeHosts(rows []int) {
+	removed := ui.model.RemoveHosts(rows)
+	if len(removed) == 0 {
+		return
+	}
+	for _, row := range removed { // highest first
+		_ = ui.hostList.TakeItem(row)
+	}
+	ui.updateButtons()
+	ui.persistHosts()
 	if ui.running {
+		ui.restartPinging()
+	}
+}
+
+func (ui *UI) appendHostItem(h *Host) {
+	ui.hostList.AddItem("")
+	ui.syncHostItem(ui.hostList.Count()-1, h)
+}
+
+// syncHost refreshes h's row in the host list.
+func (ui *UI) syncHost(h *Host) {
+	for i, hh := range ui.model.Hosts() {
+		if hh == h {
+			ui.syncHostItem(i, h)
+		}
+	}
+}
+
+// syncHostItem refreshes the list row text/tooltip from h.
+func (ui *UI) syncHostItem(row int, h *Host) {
+	it := ui.hostList.Item(row)
+	if it == nil {
+		return
+	}
+	text := fmt.Sprintf("%s (%s)", h.Name, h.Addr)
+	switch {
+	case h.Paused:
+		it.SetText(text + " — paused")
+	case h.Err != "":
+		it.SetText(text + " — failed")
+	default:
+		it.SetText(text)
+	}
+	// the list is narrow, so the tooltip carries the full text (and the note)
+	tip := text
+	if h.IP != "" {
+		tip += "\nPinging " + h.IP
+	}
+	if h.PTR != "" {
+		tip += "\nReverse DNS: " + h.PTR
+	}
+	if h.Err != "" {
+		tip += "\nPinging " + h.Err
+	}
+	if h.Note != "" {
+		tip += "\n" + h.Note
+	}
+	if h.IntervalMs > 0 || h.PacketSize > 0 {
+		tip += "\nProbing:"
+		if h.IntervalMs > 0 {
+			tip += fmt.Sprintf(" every %d ms", h.IntervalMs)
+		}
+		if h.PacketSize > 0 {
+			tip += fmt.Sprintf(" %d byte payload", h.PacketSize)
+		}
+	}
+	it.SetToolTip(tip)
+}
+
+// editHost shows the edit dialog for the host at row and applies the result.
+func (ui *UI) editHost(row int) {
+	h := ui.model.HostAt(row)
+	if h == nil {
+		return
+	}
+	hc, ok := editHostDialog(ui.main.QWidget, h)
+	if !ok {
+		return
+	}
+	if hc.Name == "" {
+		hc.Name = hc.Addr
+	}
+	probeChanged := hc.Addr != h.Addr || hc.IntervalMs != h.IntervalMs || hc.PacketSize != h.PacketSize
+	ui.model.EditHost(h, hc)
+	ui.syncHostItem(row, h)
+	ui.persistHosts()
+	ui.updateRate()
+	if probeChanged && ui.running {
+		ui.restartPinging()
+	}
+}
+
+// persistHosts rebuilds the config host list from the model (single source of truth) and saves.
+func (ui *UI) persistHosts() {
+	c := ui.model.EnsureConfig()
+	c.Ping.Hosts = ui.model.HostConfigs()
+	ui.model.SaveConfigAsync()
+}
+
+// saveSession asks for a file and whether to include history, then saves.
+func (ui *UI) saveSession() {
+	path := qt.QFileDialog_GetSaveFileName4(ui.main.QWidget, "Save session", sessionsDir(), sessionFilter)
+	if path == "" {
+		return
+	}
+	if !strings.HasSuffix(strings.ToLower(path), ".json") {
+		path += ".json"
+	}
+	history := qt.QMessageBox_Question(ui.main.QWidget, "Save session",
+		"Include the samples collected so far?") == qt.QMessageBox__Yes
+	if err := ui.model.SaveSession(path, ui.graph.TimeSpan(), history, ui.graph.Markers()); err != nil {
+		log.Printf("Unable to save session %s: %s\n", path, err)
+		qt.QMessageBox_Warning(ui.main.QWidget, "Save session", err.Error())
+	}
+}
+
+// openSession stops pinging and restores a saved session; it stays paused.
+func (ui *UI) openSession() {
+	path := qt.QFileDialog_GetOpenFileName4(ui.main.QWidget, "Open session", sessionsDir(), sessionFilter)
+	if path == "" {
+		return
+	}
+	ui.StopPinging()
+	ui.sessionStart = time.Time{} // the loaded samples aren't this session's
+	ui.model.BeginBatch()
+	defer ui.model.EndBatch()
+	sess, err := ui.model.LoadSession(path)
+	if err != nil {
+		log.Printf("Unable to load session %s: %s\n", path, err)
+		qt.QMessageBox_Warning(ui.main.QWidget, "Open session", err.Error())
+		return
+	}
+	ui.hostList.Clear()
+	for _, h := range ui.model.Hosts() {
+		ui.appendHostItem(h)
+	}
+	ui.intSlider.SetValue(ui.model.PingIntervalMs())
+	ui.graph.SetTimeSpan(time.Duration(sess.SpanSec * float64(time.Second)))
+	// show the loaded history where it ended rather than an empty "now"
+	var last time.Time
+	for _, sh := range sess.Hosts {
+		if n := len(sh.History); n > 0 && sh.History[n-1].T.After(last) {
+			last = sh.History[n-1].T
+		}
+	}
+	ui.graph.FreezeAt(last)
+	ui.graph.SetMarkers(sess.Markers)
+	ui.persistHosts()
+	ui.updateButtons()
+	if sess.Dropped > 0 {
+		ui.main.StatusBar().ShowMessage2(fmt.Sprintf("Left out %d custom probe host(s) from the session; add them by hand if you trust them", sess.Dropped), 30000)
+	}
+}
+
+const markerShortcut = "Ctrl+K"
+
+// addMarker asks for a label and annotates the ping graph at the moment
+// the marker was requested.
+func (ui *UI) addMarker() {
+	t := time.Now()
+	label := qt.QInputDialog_GetText(ui.main.QWidget, "Add marker", "Label (e.g. \"rebooted router\"):")
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return // canceled
+	}
+	ui.graph.AddMarker(t, label)
+	ui.main.StatusBar().ShowMessage2("Marker \""+label+"\" added at "+t.Format("15:04:05"), 5000)
+}
+
+// overlaySession loads a saved session's history as a ghost behind the live
+// graph. The current hosts and samples are left alone.
+func (ui *UI) overlaySession() {
+	path := qt.QFileDialog_GetOpenFileName4(ui.main.QWidget, "Overlay session", sessionsDir(), sessionFilter)
+	if path == "" {
+		return
+	}
+	sess, err := ReadSession(path)
+	if err == nil && !sessionHasHistory(sess) {
+		err = errors.New("session was saved without history")
+	}
+	if err != nil {
+		log.Printf("Unable to overlay session %s: %s\n", path, err)
+		qt.QMessageBox_Warning(ui.main.QWidget, "Overlay session", err.Error())
 		return
 	}
+	label := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + ", " + sess.Saved.Format("2006-01-02 15:04")
+	ui.graph.SetOverlay(label, sess.Hosts)
+	ui.chkOverlay.SetEnabled(true)
+	ui.chkOverlay.SetChecked(true)
+}
+
+func sessionHasHistory(sess *Session) bool {
+	for _, sh := range sess.Hosts {
+		if len(sh.History) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// pingMaxRTT is a practical loss timeout for interval: 2× interval, but not
+// less than 300 ms (helps on Wi-Fi).
+func pingMaxRTT(interval time.Duration) time.Duration {
+	return maxDur(2*interval, 300*time.Millisecond)
+}
+
+// hostResolved records the address pinged for h; a move to another one
+// (see ProbingBackend.Reresolve) is logged and marked on the graph.
+func (ui *UI) hostResolved(h *Host, old, cur string) {
+	if cur == h.Addr {
+		cur = "" // an IP literal, nothing to show
+	}
+	h.IP = cur
+	ui.syncHost(h)
+	ui.lookupPTR(h)
+	if old == "" || cur == "" {
+		return
+	}
+	log.Printf("%s (%s) now resolves to %s instead of %s\n", h.Name, h.Addr, cur, old)
+	ui.graph.AddMarker(time.Now(), fmt.Sprintf("%s → %s", h.Name, cur))
+}
+
+// backendFor applies h's interval and packet size overrides to pb.
+func backendFor(pb ProbingBackend, h *Host) ProbingBackend {
+	if h.IntervalMs > 0 {
+		pb.Interval = time.Duration(h.IntervalMs) * time.Millisecond
+		pb.MaxRTT = pingMaxRTT(pb.Interval)
+	}
+	pb.Size = h.PacketSize
+	return pb
+}
+
+// startSession is the Start button: pings every host, with the stop
+// conditions armed and a summary at the end.
+func (ui *UI) startSession() {
+	ui.graph.AnchorOverlay(time.Now())
+	ui.armStop()
+	ui.sessionStart = time.Now()
+	ui.StartPinging()
+}
+
+// AutoStart starts a session at launch when ping.auto_start is set and
+// there is anything to ping.
+func (ui *UI) AutoStart() {
+	c := ui.model.Config()
+	if c == nil || !c.Ping.AutoStart || ui.model.Count() == 0 {
+		return
+	}
+	log.Printf("Auto-starting pinging of %d host(s)\n", ui.model.Count())
+	ui.startSession()
+}
+
+func (ui *UI) StartPinging() {
+	if ui.running || !ui.checkPingSource() {
+		return
+	}
+	ui.graph.FreezeAt(time.Time{})
+	interval := time.Duration(ui.intSlider.Value()) * time.Millisecond
 	ui.backend = ProbingBackend{
 		Privileged: false,
-		Interval:   time.Duration(ui.intSlider.Value()) * time.Millisecond,
-		// A practical MaxRTT: 2× interval, but not less than 300 ms (helps on Wi-Fi)
-		MaxRTT:    maxDur(2*time.Duration(ui.intSlider.Value())*time.Millisecond, 300*time.Millisecond),
-		GraceLate: 100 * time.Millisecond,
+		Interval:   interval,
+		MaxRTT:     pingMaxRTT(interval),
+		GraceLate:  100 * time.Millisecond,
+		Adaptive:   ui.chkAdaptive.IsChecked(),
+		Source:     ui.pingSource(),
+	}
+	ui.customOn = false
+	if c := ui.model.Config(); c != nil {
+		ui.backend.Reresolve = time.Duration(c.Ping.ReresolveMin) * time.Minute
+		ui.backend.SeqGaps = c.Ping.SeqGaps
+		ui.customOn = c.Ping.CustomProbes
+	}
+	ui.runCtx, ui.cancel = context.WithCancel(context.Background())
+	if ui.hostRuns == nil {
+		ui.hostRuns = map[*Host]*hostRun{}
+	}
+	for h, r := range ui.hostRuns {
+		if r.exited() {
+			delete(ui.hostRuns, h)
+		}
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	ui.cancel = cancel
 	ui.running = true
+	ui.runningSince = time.Now()
 	ui.updateButtons()
+	ui.refreshRunLabel()
 
+	skipped := 0 // custom probes while they are turned off
 	for _, h := range ui.model.Hosts() {
-		h.State = HostRunning
-		go func(h *Host) {
-			// We don’t use the callback any more – ping.go updates h.buf directly.
-			// But we still pass a dummy func to satisfy the signature.
-			_ = ui.backend.RunForHost(ctx, h)
-			h.State = HostStopped
-		}(h)
+		if h.Paused {
+			continue
+		}
+		if !ui.startHost(h) {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		log.Printf("Not running %d custom probe command(s): ping.custom_probes is off\n", skipped)
+		ui.main.StatusBar().ShowMessage2(fmt.Sprintf("%d custom probes not run: set ping.custom_probes: true in settings.yml to allow commands", skipped), 30000)
 	}
 }
 
@@ -463,14 +1801,134 @@ func (ui *UI) StopPinging() {
 		ui.cancel()
 		ui.cancel = nil
 	}
+	ui.runCtx = nil
 	ui.running = false
+	ui.runningSince = time.Time{}
 	ui.updateButtons()
+	ui.refreshRunLabel()
+}
+
+// refreshPublicIP looks the public address up in the background.
+func (ui *UI) refreshPublicIP() {
+	if ui.pubIPBusy {
+		return
+	}
+	ui.pubIPBusy = true
+	ui.pubIPLabel.SetText("Public IP: checking…")
+	url := ""
+	if c := ui.model.Config(); c != nil {
+		url = c.Net.PublicIPURL
+	}
+	go func() {
+		ip, err := pubip.Lookup(context.Background(), url)
+		mainthread.Wait(func() {
+			ui.pubIPBusy = false
+			if err != nil {
+				log.Printf("Public IP lookup failed: %s\n", err)
+				ui.pubIPLabel.SetText("Public IP: unknown")
+				ui.pubIPLabel.SetToolTip(err.Error())
+				return
+			}
+			ui.pubIPLabel.SetText("Public IP: " + ip)
+			ui.pubIPLabel.SetToolTip(url)
+		})
+	}()
+}
+
+// Close stops pinging and flushes background writers (called on exit).
+func (ui *UI) Close() {
+	ui.StopPinging()
+	if ui.detached {
+		ui.graphWin.Close() // or it would keep the app running
+	}
+	if !ui.runs.Shutdown(shutdownWait) {
+		log.Printf("Child processes still running after %s, exiting anyway\n", shutdownWait)
+	}
+	ui.stopSampleLog()
+	ui.metrics.Close()
+	ui.api.Close()
+}
+
+func (ui *UI) startSampleLog() {
+	if ui.sampleLog != nil {
+		return
+	}
+	sl := NewSampleLogger(logsDir())
+	ui.sampleLog = sl
+	ui.unsubSample = ui.model.Subscribe(func(h *Host, s Sample) {
+		sl.Log(ui.model.Ident(h), s)
+	})
+}
+
+func (ui *UI) stopSampleLog() {
+	if ui.sampleLog == nil {
+		return
+	}
+	ui.unsubSample()
+	ui.sampleLog.Close()
+	ui.sampleLog, ui.unsubSample = nil, nil
+}
+
+// armStop starts watching the "Stop after" conditions for the session the
+// Start button is about to begin. Restarts (host added, interval changed)
+// keep the same watch, so they don't reset the clock.
+func (ui *UI) armStop() {
+	ui.disarmStop()
+	cond := monitor.StopCondition{
+		Duration:   time.Duration(ui.stopMin.Value()) * time.Minute,
+		Samples:    ui.stopSamples.Value(),
+		LossStreak: ui.stopLoss.Value(),
+	}
+	if !cond.Enabled() {
+		return
+	}
+	sw := monitor.Arm(cond, time.Now())
+	ui.stopWatch = sw
+	ui.unsubStop = ui.model.Subscribe(func(h *Host, s Sample) {
+		sw.Observe(ui.model.Ident(h).Addr, s)
+	})
+}
+
+func (ui *UI) disarmStop() {
+	if ui.unsubStop != nil {
+		ui.unsubStop()
+		ui.unsubStop = nil
+	}
+	ui.stopWatch = nil
+}
+
+// checkStop ends the session once an armed stop condition is met.
+func (ui *UI) checkStop() {
+	if ui.stopWatch == nil || !ui.running {
+		return
+	}
+	reason, due := ui.stopWatch.Due(time.Now())
+	if !due {
+		return
+	}
+	ui.disarmStop()
+	ui.StopPinging()
+	ui.main.StatusBar().ShowMessage("Stopped: " + reason)
+	log.Printf("Pinging stopped automatically: %s\n", reason)
+	ui.showSessionSummary(reason)
 }
 
 func (ui *UI) restartPinging() {
 	// simple strategy: stop then start with new config
+	since := ui.runningSince
 	ui.StopPinging()
 	ui.StartPinging()
+	ui.keepRunningSince(since)
+}
+
+// keepRunningSince carries the run clock over an internal restart: when
+// pinging was running since since and runs again, the run label keeps
+// counting from then.
+func (ui *UI) keepRunningSince(since time.Time) {
+	if ui.running && !since.IsZero() {
+		ui.runningSince = since
+		ui.refreshRunLabel()
+	}
 }
 
 func (ui *UI) updateButtons() {
@@ -478,6 +1936,37 @@ func (ui *UI) updateButtons() {
 	ui.btnStop.SetEnabled(ui.running)
 	// while running, avoid structural changes:
 	ui.btnAdd.SetEnabled(!ui.running)
+	ui.updateRate()
+}
+
+// updateRate shows how many packets per second the current setup sends.
+func (ui *UI) updateRate() {
+	n := ui.model.Count()
+	ms := ui.intSlider.Value()
+	if ms <= 0 {
+		ms = 1000
+	}
+	pps, custom := 0.0, 0
+	for _, h := range ui.model.Hosts() {
+		hms := ms
+		if h.IntervalMs > 0 {
+			hms = h.IntervalMs
+			custom++
+		}
+		pps += 1000 / float64(hms)
+	}
+	text := fmt.Sprintf("%d hosts × %d ms = %s packets/sec", n, ms, formatFloat(pps, 1))
+	if custom > 0 {
+		text = fmt.Sprintf("%d hosts × %d ms (%d with own interval) = %s packets/sec", n, ms, custom, formatFloat(pps, 1))
+	}
+	ui.rateLabel.SetText(text)
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
 }
 
 // small helper
@@ -488,6 +1977,52 @@ func maxDur(a, b time.Duration) time.Duration {
 	return b
 }
 
+// parseHostList splits pasted input on commas, spaces and newlines,
+// dropping empty entries and duplicates while keeping the input order.
+//
+// A custom probe ("cmd:…") is a command line, so it is taken whole.
+func parseHostList(s string) []string {
+	if t := strings.TrimSpace(s); strings.HasPrefix(t, monitor.CommandPrefix) {
+		return []string{t}
+	}
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+	var out []string
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	return out
+}
+
+// tcpStatsText shows an interval's TCP RTT and congestion window, e.g.
+// "RTT 12.3 ms · cwnd 434 KB". The end-of-test row (final) holds the mean
+// RTT and the largest window.
+func tcpStatsText(iv iperf.Interval, final bool) string {
+	rtt, cwnd := "RTT ", "cwnd "
+	if final {
+		rtt, cwnd = "mean RTT ", "max cwnd "
+	}
+	var parts []string
+	if iv.RTTms > 0 {
+		parts = append(parts, rtt+formatFloat(iv.RTTms, 1)+" ms")
+	}
+	if iv.CWND > 0 {
+		kb := float64(iv.CWND) / 1024
+		if kb >= 1024 {
+			parts = append(parts, cwnd+formatFloat(kb/1024, 1)+" MB")
+		} else {
+			parts = append(parts, cwnd+formatFloat(kb, 0)+" KB")
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
 // iperf.Interval.Bitrate is "<num> <unit>bits/sec" where unit is K/M/G (already handled in our iperf regex).
 func parseMbps(bitrate string) float64 {
 	// examples: "937 Mbits/sec", "1.25 Gbits/sec", "880 Kbits/sec"
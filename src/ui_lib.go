@@ -21,7 +21,10 @@
 package main
 
 import (
-	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/mappu/miqt/qt"
@@ -35,22 +38,125 @@ func qcolor(r, g2, b, a int) *qt.QColor {
 	return c
 }
 
-var palette = [][]int{
-	{90, 180, 255},  // azure
-	{255, 120, 120}, // salmon
-	{255, 200, 80},  // amber
-	{120, 230, 140}, // mint
-	{200, 140, 255}, // purple
-	{80, 220, 200},  // teal
-	{255, 160, 220}, // pink
-	{160, 200, 255}, // light blue
+// dpiScale tells how much bigger than a 96 DPI layout pixel sizes should be.
+// When Qt scales by devicePixelRatio itself, coordinates are already logical
+// and logical DPI stays ~96; an enlarged logical DPI (e.g. Windows at 150%
+// without Qt HiDPI scaling) is what we have to compensate for by hand.
+func dpiScale(d *qt.QPaintDevice) float64 {
+	s := float64(d.LogicalDpiX()) / 96.0
+	if s < 1 {
+		return 1 // macOS reports 72
+	}
+	return s
+}
+
+// A graph whose plot area would be narrower or shorter than minPlotPx once
+// the axes and labels have their room drops them and draws only its data,
+// compactPad from the edges; below tinyPlotPx it is left blank.
+const (
+	minPlotPx  = 40
+	tinyPlotPx = 8
+	compactPad = 2
+)
+
+// noDataText is what an empty graph shows instead of axes scaled to nothing.
+const noDataText = "Waiting for data…"
+
+// paintNoData draws msg centered in a w×h widget, in a faded text color so
+// it reads on light and dark themes alike.
+func paintNoData(p *qt.QPainter, w, h float64, txt *qt.QColor, msg string) {
+	fm := qt.NewQFontMetricsF(p.Font())
+	p.SetPen(qcolor(txt.Red(), txt.Green(), txt.Blue(), 160))
+	p.DrawStaticText2(qt.NewQPoint2(int((w-fm.Width(msg))/2), int((h-fm.Height())/2)), qt.NewQStaticText2(msg))
+}
+
+// seriesPalette is a set of host series colors plus the colors used for
+// reply/warn/bad states (loss strip, RTT thresholds).
+type seriesPalette struct {
+	series        [][]int
+	ok, warn, bad []int
+}
+
+var palettes = map[string]seriesPalette{
+	"default": {
+		series: [][]int{
+			{90, 180, 255},  // azure
+			{255, 120, 120}, // salmon
+			{255, 200, 80},  // amber
+			{120, 230, 140}, // mint
+			{200, 140, 255}, // purple
+			{80, 220, 200},  // teal
+			{255, 160, 220}, // pink
+			{160, 200, 255}, // light blue
+		},
+		ok: []int{80, 190, 100}, warn: []int{255, 170, 0}, bad: []int{230, 40, 40},
+	},
+	// Okabe-Ito, distinguishable with red-green (and most other) color
+	// blindness; grey stands in for black, which vanishes on dark themes
+	"colorblind": {
+		series: [][]int{
+			{86, 180, 233},  // sky blue
+			{230, 159, 0},   // orange
+			{0, 158, 115},   // bluish green
+			{240, 228, 66},  // yellow
+			{204, 121, 167}, // reddish purple
+			{213, 94, 0},    // vermilion
+			{0, 114, 178},   // blue
+			{153, 153, 153}, // grey
+		},
+		ok: []int{86, 180, 233}, warn: []int{240, 228, 66}, bad: []int{213, 94, 0},
+	},
+}
+
+// paletteNames lists the palettes in combo order: config name, label.
+var paletteNames = [][2]string{
+	{"default", "Default colors"},
+	{"colorblind", "Colorblind-safe"},
+}
+
+// palette is the active one (display.palette); highContrast thickens lines
+// and strengthens grid/label backgrounds (display.high_contrast). Both are
+// only changed and read on the UI thread.
+var (
+	palette      = palettes["default"]
+	highContrast bool
+)
+
+// setPalette activates the named palette, falling back to the default.
+func setPalette(name string) {
+	p, ok := palettes[name]
+	if !ok {
+		p = palettes["default"]
+	}
+	palette = p
 }
 
 func seriesColor(i int) *qt.QColor {
-	c := palette[i%len(palette)]
+	c := palette.series[i%len(palette.series)]
 	return qcolor(c[0], c[1], c[2], 255)
 }
 
+// stateColor makes a QColor from one of palette's ok/warn/bad colors.
+func stateColor(c []int, a int) *qt.QColor {
+	return qcolor(c[0], c[1], c[2], a)
+}
+
+// lineWidth scales a pen width for high contrast mode.
+func lineWidth(w float64) float64 {
+	if highContrast {
+		return w * 1.75
+	}
+	return w
+}
+
+// contrastAlpha raises a grid/background alpha in high contrast mode.
+func contrastAlpha(a int) int {
+	if highContrast {
+		return min(a*2, 240)
+	}
+	return a
+}
+
 // 1–2–5 tick generator within [min, max]
 func niceTicks(min, max float64, target int) []float64 {
 	if target < 2 {
@@ -103,10 +209,80 @@ func roundTo(v, step float64) float64 {
 }
 
 func formatMS(ms float64) string {
+	if d := valueDecimals.Load(); d > 0 {
+		return formatFloat(ms, int(d))
+	}
 	if ms < 1 {
 		return "0"
 	}
-	return fmt.Sprintf("%.0f", ms)
+	return formatFloat(ms, 0)
+}
+
+// valueDecimals fixes the decimals of RTT and rate values in the graphs'
+// labels and tooltips (display.decimals), -1 leaves each its own default;
+// tipMillis adds milliseconds to tooltip times (display.tip_millis). Both
+// are set from the UI thread and read while painting.
+var (
+	valueDecimals atomic.Int32
+	tipMillis     atomic.Bool
+)
+
+func init() { valueDecimals.Store(-1) }
+
+// valuePrec is the number of decimals for a value normally shown with def.
+func valuePrec(def int) int {
+	if d := valueDecimals.Load(); d >= 0 {
+		return int(d)
+	}
+	return def
+}
+
+// tipTime formats t for a hover tooltip.
+func tipTime(t time.Time) string {
+	if tipMillis.Load() {
+		return t.Format("15:04:05.000")
+	}
+	return t.Format("15:04:05")
+}
+
+// decimalComma switches formatFloat to "," as the decimal separator
+// (display.decimal_comma). Set from the UI thread, read while painting.
+var decimalComma atomic.Bool
+
+// formatFloat formats v with prec decimals for display, honouring the
+// decimal separator setting. Not for files or exported data.
+func formatFloat(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if decimalComma.Load() {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
+
+// timeTicks returns the multiples of step within (start, end]. Mapped with
+// mapX they sit at fractional positions that glide with the clock each
+// frame, instead of one being pinned to the left edge until it jumps.
+func timeTicks(start, end time.Time, step time.Duration) []time.Time {
+	var out []time.Time
+	for t := start.Truncate(step).Add(step); !t.After(end); t = t.Add(step) {
+		out = append(out, t)
+	}
+	return out
+}
+
+// labelEvery returns n such that labelling every nth of ticks px apart keeps
+// labels w wide from overlapping.
+func labelEvery(px, w float64) int {
+	if px <= 0 {
+		return 1
+	}
+	return max(1, int(math.Ceil((w+6)/px)))
+}
+
+// labelled picks the ticks that get a label by their own time, so the
+// same ones stay labelled while the graph scrolls.
+func labelled(t time.Time, step time.Duration, every int) bool {
+	return (t.UnixNano()/int64(step))%int64(every) == 0
 }
 
 func mapX(t time.Time, start, end time.Time, left, right float64) float64 {
@@ -135,6 +311,90 @@ func unmapX(x float64, start, end time.Time, left, right float64) time.Time {
 	return start.Add(time.Duration(ratio * float64(span)))
 }
 
+// frameRateToMs turns a target frame rate into a timer interval (30 fps if unset).
+func frameRateToMs(fps int) int {
+	if fps <= 0 {
+		return 33
+	}
+	return max(1000/fps, 1)
+}
+
+// commandButtons creates the "Copy command" / "Open in terminal" pair shown
+// next to the speed test and traceroute Start buttons.
+func commandButtons() (copyBtn, termBtn *qt.QPushButton) {
+	copyBtn = qt.NewQPushButton(nil)
+	copyBtn.SetText("Copy command")
+	copyBtn.SetToolTip("Copy the exact command line a run would use")
+	termBtn = qt.NewQPushButton(nil)
+	termBtn.SetText("Open in terminal")
+	termBtn.SetToolTip("Run the same command in a new terminal window")
+	return copyBtn, termBtn
+}
+
+// wireCommandButtons connects a commandButtons pair; build returns the
+// program and arguments for the current settings, status shows the outcome.
+func wireCommandButtons(copyBtn, termBtn *qt.QPushButton, status *qt.QLabel, build func() (string, []string, error)) {
+	line := func() string {
+		bin, args, err := build()
+		if err != nil {
+			status.SetText("Error: " + err.Error())
+			return ""
+		}
+		return commandLine(bin, args)
+	}
+	copyBtn.OnClicked(func() {
+		if l := line(); l != "" {
+			copyToClipboard(l)
+			status.SetText("Command copied.")
+		}
+	})
+	termBtn.OnClicked(func() {
+		l := line()
+		if l == "" {
+			return
+		}
+		if err := openInTerminal(l); err != nil {
+			copyToClipboard(l)
+			status.SetText("Can't open a terminal (" + err.Error() + "); command copied instead.")
+		}
+	})
+}
+
+func copyToClipboard(text string) {
+	if text == "" {
+		return
+	}
+	qt.QGuiApplication_Clipboard().SetText2(text, qt.QClipboard__Clipboard)
+}
+
+// plotPt is a point in widget (pixel) coordinates.
+type plotPt struct{ X, Y float64 }
+
+// smoothPath builds a Catmull-Rom spline (as cubic Béziers) through pts.
+// Control points are clamped to floorY (the pixel row of value 0) so the
+// curve never dips below zero between samples. Needs at least 2 points.
+func smoothPath(pts []plotPt, floorY float64) *qt.QPainterPath {
+	path := qt.NewQPainterPath2(qt.NewQPointF3(pts[0].X, pts[0].Y))
+	clamp := func(y float64) float64 {
+		if y > floorY { // Y grows downwards
+			return floorY
+		}
+		return y
+	}
+	for i := 0; i+1 < len(pts); i++ {
+		p0 := pts[max(i-1, 0)]
+		p1 := pts[i]
+		p2 := pts[i+1]
+		p3 := pts[min(i+2, len(pts)-1)]
+		c1x := p1.X + (p2.X-p0.X)/6
+		c1y := clamp(p1.Y + (p2.Y-p0.Y)/6)
+		c2x := p2.X - (p3.X-p1.X)/6
+		c2y := clamp(p2.Y - (p3.Y-p1.Y)/6)
+		path.CubicTo2(c1x, c1y, c2x, c2y, p2.X, p2.Y)
+	}
+	return path
+}
+
 func mapY(v, minV, maxV, top, bottom float64) float64 {
 	if maxV <= minV {
 		return bottom
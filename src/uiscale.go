@@ -0,0 +1,113 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mappu/miqt/qt"
+)
+
+// UI scale (display.ui_scale): the application font is set to a percentage
+// of the platform default, for projectors and large screens. Layouts and
+// the graphs' margins follow font metrics by themselves; the few fonts
+// worked out from the default font, and the fixed pixel sizes (through
+// scaledPx), register with onUIScale so they are redone when the scale
+// changes.
+
+var uiScales = []int{100, 125, 150, 200}
+
+var (
+	basePt, basePx float64 // the platform's default font size, in points or pixels
+	onUIScale      []func()
+)
+
+// applyUIScale sets the application font to pct percent of the default.
+func applyUIScale(pct int) {
+	if pct <= 0 {
+		pct = 100
+	}
+	f := qt.QApplication_Font()
+	if basePt == 0 && basePx == 0 {
+		basePt, basePx = f.PointSizeF(), float64(f.PixelSize())
+	}
+	if basePt > 0 {
+		f.SetPointSizeF(basePt * float64(pct) / 100)
+	} else {
+		f.SetPixelSize(int(basePx * float64(pct) / 100))
+	}
+	qt.QApplication_SetFont(f)
+	for _, fn := range onUIScale {
+		fn()
+	}
+}
+
+// uiFactor is the current UI scale as a factor, 1 at 100%.
+func uiFactor() float64 {
+	f := qt.QApplication_Font()
+	switch {
+	case basePt > 0:
+		return f.PointSizeF() / basePt
+	case basePx > 0:
+		return float64(f.PixelSize()) / basePx
+	}
+	return 1 // never scaled
+}
+
+// scaledPx is a size of px pixels at 100% at the current UI scale.
+func scaledPx(px int) int { return int(float64(px)*uiFactor() + 0.5) }
+
+// whenScaled calls set now and after every scale change, for long-lived
+// widgets; dialogs can just use scaledPx when they open.
+func whenScaled(set func()) {
+	set()
+	onUIScale = append(onUIScale, set)
+}
+
+// scaledFont gives w the application font as changed by adjust (bold,
+// larger...), now and after every scale change.
+func scaledFont(w *qt.QWidget, adjust func(f *qt.QFont)) {
+	whenScaled(func() {
+		f := qt.QApplication_Font()
+		adjust(f)
+		w.SetFont(f)
+	})
+}
+
+// uiScaleCombo lists the scales with pct selected, pct included when it was
+// set by hand in settings.yml; scales[i] is the percentage of item i.
+func uiScaleCombo(pct int) (cb *qt.QComboBox, scales []int) {
+	scales = uiScales
+	if pct > 0 && !slices.Contains(scales, pct) {
+		scales = append(slices.Clone(scales), pct)
+		slices.Sort(scales)
+	}
+	cb = qt.NewQComboBox(nil)
+	for _, s := range scales {
+		cb.AddItem(fmt.Sprintf("%d%%", s))
+		if s == pct {
+			cb.SetCurrentIndex(cb.Count() - 1)
+		}
+	}
+	cb.SetToolTip("Text size of the whole window, e.g. 150% or 200% on a projector")
+	return cb, scales
+}
@@ -21,8 +21,11 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"math"
+	"os"
 	"os/exec"
 	"runtime"
 	"strconv"
@@ -41,8 +44,19 @@ func maxf(a, b float64) float64 {
 	return b
 }
 
-// open file (default association) or folder (supports windows, linux, mac)
-func openFileOrDir(file string) {
+// openDir creates dir when it doesn't exist yet (e.g. before the first
+// save) and opens it in the file manager.
+func openDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return openFileOrDir(dir)
+}
+
+// open file (default association) or folder (supports windows, linux, mac).
+// An error means the opener couldn't be started; if it fails later (e.g.
+// xdg-open without a handler) that is only logged.
+func openFileOrDir(file string) error {
 	log.Printf("Opening external: %s\n", file)
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
@@ -53,9 +67,85 @@ func openFileOrDir(file string) {
 	case "windows":
 		cmd = exec.Command("explorer", file)
 	default:
-		return
+		return fmt.Errorf("opening files is not supported on %s", runtime.GOOS)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("unable to run %s: %w", cmd.Path, err)
+	}
+	go func() {
+		err := cmd.Wait()
+		var exit *exec.ExitError
+		if err == nil || (runtime.GOOS == "windows" && errors.As(err, &exit) && exit.ExitCode() == 1) {
+			return // explorer exits with 1 even when it opened the folder
+		}
+		log.Printf("Opening %s with %s failed: %s\n", file, cmd.Path, err)
+	}()
+	return nil
+}
+
+// commandLine joins bin and args into one line that can be pasted into the
+// platform's shell, quoting only where needed.
+func commandLine(bin string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	for _, a := range append([]string{bin}, args...) {
+		parts = append(parts, shellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$&|;<>()*?![]{}`~#") {
+		return s
+	}
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// linuxTerminals are tried in order after $TERMINAL; the second field is the
+// flag after which the program and its arguments follow.
+var linuxTerminals = [][2]string{
+	{"x-terminal-emulator", "-e"},
+	{"gnome-terminal", "--"},
+	{"konsole", "-e"},
+	{"xfce4-terminal", "-x"},
+	{"kitty", "--"},
+	{"alacritty", "-e"},
+	{"xterm", "-e"},
+}
+
+// openInTerminal opens a new terminal window running line, left open
+// afterwards so the output can be read.
+func openInTerminal(line string) error {
+	log.Printf("Opening terminal: %s\n", line)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := `tell application "Terminal" to do script "` +
+			strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(line) + `"`
+		cmd = exec.Command("osascript", "-e", script, "-e", `tell application "Terminal" to activate`)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", "cmd", "/k", line)
+	case "linux":
+		sh := []string{"sh", "-c", line + `; exec "${SHELL:-sh}"`}
+		terms := linuxTerminals
+		if t := os.Getenv("TERMINAL"); t != "" {
+			terms = append([][2]string{{t, "-e"}}, terms...)
+		}
+		for _, t := range terms {
+			if p, err := exec.LookPath(t[0]); err == nil {
+				cmd = exec.Command(p, append([]string{t[1]}, sh...)...)
+				break
+			}
+		}
+		if cmd == nil {
+			return errors.New("no terminal emulator found")
+		}
+	default:
+		return fmt.Errorf("opening a terminal is not supported on %s", runtime.GOOS)
 	}
-	_ = cmd.Start()
+	return cmd.Start()
 }
 
 func atoiDefault(s string, def int) int {
@@ -0,0 +1,91 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"fmt"
+
+	"github.com/e1z0/speedping/internal/confcheck"
+)
+
+// Validation of a loaded settings.yml, with the range rules of
+// internal/confcheck.
+
+// Validate corrects out-of-range values in c and returns one line per
+// correction, e.g. "speed.port: 99999 is outside 1..65535, using 65535".
+// Settings left at zero to mean "off" or "default" are left alone.
+func (c *AppConfig) Validate() []string {
+	k := &confcheck.Check{}
+
+	p := &c.Ping
+	k.Int("ping.interval_ms", &p.IntervalMs, 100, 1000, 1000)
+	for i := range p.Hosts {
+		h := &p.Hosts[i]
+		key := fmt.Sprintf("ping.hosts[%d] (%s)", i, h.Addr)
+		if h.IntervalMs != 0 {
+			k.Int(key+".interval_ms", &h.IntervalMs, minHostIntervalMs, 24*3600*1000, 0)
+		}
+		k.Int(key+".packet_size", &h.PacketSize, 0, maxPacketSize, 0)
+		k.Float(key+".warn_ms", &h.WarnMs, 0, 1e6, 0)
+		k.Float(key+".bad_ms", &h.BadMs, 0, 1e6, 0)
+	}
+	k.Float("ping.warn_ms", &p.WarnMs, 0, 1e6, 0)
+	k.Float("ping.bad_ms", &p.BadMs, 0, 1e6, 0)
+	k.Int("ping.stop_minutes", &p.StopMinutes, 0, 24*60, 0)
+	k.Int("ping.stop_samples", &p.StopSamples, 0, 1000000, 0)
+	k.Int("ping.stop_loss_streak", &p.StopLossStreak, 0, 1000, 0)
+	k.Int("ping.reresolve_min", &p.ReresolveMin, 0, 24*60, 0)
+	if p.RingCapacity != 0 {
+		k.Int("ping.ring_capacity", &p.RingCapacity, minRingCap, maxRingCap, 0)
+	}
+
+	g := &c.Graph
+	k.Int("graph.flap_threshold", &g.FlapThreshold, 0, 100, 6)
+	k.Int("graph.loss_strip_px", &g.LossStripPx, 1, 40, 6)
+	k.Float("graph.loss_ms", &g.LossMs, 0, 1e6, 0)
+
+	s := &c.Speed
+	k.Int("speed.port", &s.Port, 1, 65535, 5201)
+	k.Int("speed.duration_sec", &s.DurationSec, 1, 24*3600, 10)
+	k.Int("speed.interval_sec", &s.IntervalSec, 1, 60, 1)
+	k.Int("speed.parallel", &s.Parallel, 1, 128, 1) // iperf3's own limit
+
+	t := &c.Trace
+	k.Int("traceroute.max_hops", &t.MaxHops, 1, 255, 30)
+	k.Float("traceroute.timeout_sec", &t.TimeoutSec, 0.1, 60, 1)
+	k.Int("traceroute.probes", &t.Probes, 1, 10, 1)
+	k.OneOf("traceroute.family", &t.Family, []int{0, 4, 6}, 0)
+	k.Float("traceroute.pulse_seconds", &t.PulseSeconds, 0.5, 120, 6)
+
+	v := &c.View
+	k.Int("display.frame_rate", &v.FrameRate, 1, 240, 30)
+	k.Int("display.decimals", &v.Decimals, -1, 2, -1)
+	k.OneOf("display.ui_scale", &v.UIScale, uiScales, 100)
+
+	a := &c.Alert
+	k.Float("alert.loss_pct", &a.LossPct, 0, 100, 0)
+	k.Float("alert.rtt_ms", &a.RTTms, 0, 1e6, 0)
+	k.Int("alert.window_sec", &a.WindowSec, 0, 24*3600, 0)
+	k.Int("alert.cooldown_sec", &a.CooldownSec, 0, 7*24*3600, 0)
+
+	k.Int("auto_export.interval_min", &c.Export.IntervalMin, 0, 7*24*60, 15)
+	return k.Issues
+}
@@ -0,0 +1,188 @@
+/* SPDX-License-Identifier: GPL-3.0-or-later
+ *
+ * SpeedPing
+ * Copyright (C) 2025 e1z0 <e1z0@icloud.com>
+ *
+ * This file is part of SpeedPing.
+ *
+ * SpeedPing is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * SpeedPing is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with SpeedPing.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/e1z0/speedping/internal/dns"
+	"github.com/e1z0/speedping/internal/hostlist"
+	"github.com/e1z0/speedping/internal/monitor"
+	"github.com/e1z0/speedping/internal/netinfo"
+	traceroute_wrapper "github.com/e1z0/speedping/internal/traceroute"
+)
+
+// "Check my connection": a guided run of the tools the app already has,
+// for people who don't want to read graphs. It pings the router, a public
+// DNS server and a well-known website for a short while, traces the route
+// to the website and sums it all up in one plain sentence. The hosts are
+// the check's own, so nothing is added to the list.
+
+const (
+	wizardSiteName = "Google"
+	wizardSite     = "www.google.com"
+	wizardPing     = 20 * time.Second // how long the three hosts are pinged
+	wizardTraceMax = 60 * time.Second // the trace is abandoned after this
+	wizardSlowMs   = 150.0            // average RTT to the website called slow
+)
+
+// wizardFacts is what the check found out; zero reports mean no replies.
+type wizardFacts struct {
+	GatewayErr   error // default gateway not found
+	GW, DNS, Web monitor.HostReport
+	ResolveErr   error // wizardSite could not be looked up
+
+	TraceErr     error
+	TraceHops    int    // highest hop that answered
+	TraceLast    string // its address
+	TraceReached bool
+}
+
+// wizardVerdict turns facts into one plain-language sentence, most basic
+// problem first.
+func wizardVerdict(f wizardFacts) (string, diagLevel) {
+	dead := func(r monitor.HostReport) bool { return r.Samples == 0 || r.LossPct >= 100 }
+	lossy := func(r monitor.HostReport) bool { return r.LossPct > diagLossPct }
+	switch {
+	case f.GatewayErr != nil:
+		return "Your computer doesn't seem to be connected to a network: no router was found. Check the cable or Wi-Fi connection.", diagBad
+	case dead(f.GW):
+		return "Your router isn't answering. Check the cable or Wi-Fi connection, or restart the router.", diagBad
+	case lossy(f.GW):
+		return fmt.Sprintf("Packets are being lost between this computer and your router (%s%%). Wi-Fi interference or a bad cable is the usual cause; try moving closer to the router or using a cable.", formatFloat(f.GW.LossPct, 0)), diagBad
+	case dead(f.DNS) && dead(f.Web):
+		return "Your home network works, but nothing on the internet answers. The problem is most likely with your internet provider or modem.", diagBad
+	case f.ResolveErr != nil && !dead(f.DNS):
+		return "The internet is reachable, but website names can't be looked up (a DNS problem). Try restarting the router or changing the DNS server.", diagWarn
+	case lossy(f.DNS) || lossy(f.Web):
+		return fmt.Sprintf("Packet loss detected beyond your router (%s%% to %s). Your home network is fine; the problem is with your internet provider or further out.",
+			formatFloat(max(f.DNS.LossPct, f.Web.LossPct), 0), worseHost(f.DNS, f.Web)), diagWarn
+	case !dead(f.Web) && f.Web.Avg > wizardSlowMs:
+		return fmt.Sprintf("Your connection works but is slow to respond (%s ms to %s). Video calls and games may lag.", formatFloat(f.Web.Avg, 0), f.Web.Name), diagWarn
+	}
+	return "Your connection to the internet looks healthy.", diagGood
+}
+
+func worseHost(a, b monitor.HostReport) string {
+	if b.LossPct > a.LossPct {
+		return b.Name
+	}
+	return a.Name
+}
+
+// wizardDetails lists the numbers behind the verdict.
+func wizardDetails(f wizardFacts) string {
+	var reports []monitor.HostReport
+	for _, r := range []monitor.HostReport{f.GW, f.DNS, f.Web} {
+		if r.Addr != "" {
+			reports = append(reports, r)
+		}
+	}
+	lines := reportLines(reports)
+	switch {
+	case f.ResolveErr != nil:
+		lines = append(lines, "Looking up "+wizardSite+" failed: "+f.ResolveErr.Error())
+	case f.TraceErr != nil:
+		lines = append(lines, "Route trace failed: "+f.TraceErr.Error())
+	case f.TraceReached:
+		lines = append(lines, fmt.Sprintf("Route to %s: %d hops", wizardSite, f.TraceHops))
+	case f.TraceHops > 0:
+		lines = append(lines, fmt.Sprintf("Route to %s: no answer past hop %d (%s)", wizardSite, f.TraceHops, f.TraceLast))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runWizard runs the check as a guided check (see checkrun.go).
+func (ui *UI) runWizard() {
+	var f wizardFacts
+	c := guidedCheck{
+		Title:   "Check my connection",
+		Running: fmt.Sprintf("Pinging your router, %s and %s, and tracing the route to %s…", diagPublicName, wizardSiteName, wizardSite),
+		Verdict: func(reports []monitor.HostReport) (string, diagLevel) {
+			if len(reports) == 3 {
+				f.GW, f.DNS, f.Web = reports[0], reports[1], reports[2]
+			}
+			return wizardVerdict(f)
+		},
+		Details: func([]monitor.HostReport) string { return wizardDetails(f) },
+	}
+	gwAddr, err := netinfo.DefaultGateway()
+	if err != nil {
+		f.GatewayErr = err // nothing to ping: straight to the verdict
+		c.Running = "Looking for your router…"
+		ui.runGuidedCheck(c)
+		return
+	}
+	c.Hosts = []hostlist.Entry{
+		{Name: "Gateway", Addr: gwAddr},
+		{Name: diagPublicName, Addr: diagPublicAddr},
+		{Name: wizardSiteName, Addr: wizardSite},
+	}
+	c.Duration = wizardPing
+	c.Side = func(ctx context.Context) func() {
+		ctx, cancel := context.WithTimeout(ctx, wizardTraceMax)
+		defer cancel()
+		var tf wizardFacts
+		wizardTrace(ctx, &tf)
+		return func() {
+			f.ResolveErr, f.TraceErr = tf.ResolveErr, tf.TraceErr
+			f.TraceHops, f.TraceLast, f.TraceReached = tf.TraceHops, tf.TraceLast, tf.TraceReached
+		}
+	}
+	ui.runGuidedCheck(c)
+}
+
+// wizardTrace looks wizardSite up and traces the route to it, filling in
+// the resolve and trace fields of f.
+func wizardTrace(ctx context.Context, f *wizardFacts) {
+	ips, err := dns.Lookup(ctx, wizardSite)
+	if err != nil {
+		f.ResolveErr = err
+		return
+	}
+	ev, err := traceroute_wrapper.Run(ctx, traceroute_wrapper.Options{Target: wizardSite, DontResolve: true})
+	if err != nil {
+		f.TraceErr = err
+		return
+	}
+	for e := range ev {
+		switch e.Kind {
+		case "hop":
+			h := e.Hop
+			if h.RTTms < 0 || h.Addr == "*" || h.Index < f.TraceHops {
+				continue
+			}
+			f.TraceHops, f.TraceLast = h.Index, h.Addr
+			if traceroute_wrapper.Reached(h.Addr, wizardSite, ips) {
+				f.TraceReached = true
+			}
+		case "error":
+			f.TraceErr = e.Err
+			if f.TraceErr == nil {
+				f.TraceErr = errors.New(e.Msg)
+			}
+		}
+	}
+}